@@ -0,0 +1,252 @@
+package http
+
+import (
+	defaultErr "errors"
+	"fmt"
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/api"
+	"github.com/YasiruR/connector/domain/core/dataplane"
+	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/pkg/middleware"
+	"github.com/gorilla/mux"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Handler struct {
+	provider dataplane.Provider
+	consumer dataplane.Consumer
+	roles    domain.Roles
+	log      pkg.Log
+}
+
+func NewHandler(roles domain.Roles, provider dataplane.Provider, consumer dataplane.Consumer,
+	log pkg.Log) *Handler {
+	return &Handler{provider: provider, consumer: consumer, roles: roles, log: log}
+}
+
+func (h *Handler) HandlePull(w http.ResponseWriter, r *http.Request) {
+	tpId, ok := mux.Vars(r)[api.ParamPid]
+	if !ok {
+		middleware.WriteError(w, errors.Transfer(``, ``,
+			errors.PathParamNotFound(api.ParamPid)), http.StatusBadRequest)
+		return
+	}
+
+	token, ok := bearerToken(r)
+	if !ok {
+		middleware.WriteError(w, errors.Transfer(tpId, ``, errors.Unauthorized(tokenNotFound())),
+			http.StatusUnauthorized)
+		return
+	}
+
+	// interrupted pulls can be resumed by requesting the data from an offset
+	offset := rangeOffset(r)
+	data, err := h.provider.Pull(tpId, token, offset)
+	if err != nil {
+		// access is denied with a protocol error (e.g. revoked token) whereas others are internal errors
+		var trErr errors.TransferError
+		if defaultErr.As(err, &trErr) {
+			middleware.WriteError(w, err, http.StatusForbidden)
+			return
+		}
+		middleware.WriteError(w, errors.Transfer(tpId, ``, errors.DataUnavailable(err)),
+			http.StatusInternalServerError)
+		return
+	}
+	defer data.Content.Close()
+
+	if data.ContentType != `` {
+		w.Header().Set(`Content-Type`, data.ContentType)
+	}
+
+	w.Header().Set(`Accept-Ranges`, `bytes`)
+	status := http.StatusOK
+	if offset > 0 {
+		status = http.StatusPartialContent
+		w.Header().Set(dataplane.HeaderOffset, strconv.FormatInt(offset, 10))
+		if data.Size >= 0 {
+			w.Header().Set(`Content-Range`, fmt.Sprintf("bytes %d-%d/%d", offset, data.Size-1, data.Size))
+		}
+	}
+
+	if data.Size >= 0 {
+		w.Header().Set(`Content-Length`, strconv.FormatInt(data.Size-offset, 10))
+	}
+
+	w.WriteHeader(status)
+	n, err := io.Copy(w, data.Content)
+	if err != nil {
+		// response can not be changed once the content is partially written
+		h.log.Error(fmt.Sprintf("streaming data of transfer process failed (id: %s, sent bytes: %d) - %s",
+			tpId, n, err))
+		return
+	}
+
+	h.log.Debug(fmt.Sprintf("data plane served data of transfer process (id: %s, bytes: %d)", tpId, n))
+}
+
+// HandleSink receives a chunk of the data pushed by the provider at the offset in the
+// request. Data pushed without an offset is considered complete and is committed
+// once received.
+func (h *Handler) HandleSink(w http.ResponseWriter, r *http.Request) {
+	tpId, token, ok := h.sinkParams(w, r)
+	if !ok {
+		return
+	}
+	defer r.Body.Close()
+
+	size, err := headerInt(r, dataplane.HeaderSize, -1)
+	if err != nil {
+		middleware.WriteError(w, errors.Transfer(``, tpId, errors.InvalidValue(dataplane.HeaderSize,
+			``, r.Header.Get(dataplane.HeaderSize))), http.StatusBadRequest)
+		return
+	}
+
+	offset, err := headerInt(r, dataplane.HeaderOffset, -1)
+	if err != nil {
+		middleware.WriteError(w, errors.Transfer(``, tpId, errors.InvalidValue(dataplane.HeaderOffset,
+			``, r.Header.Get(dataplane.HeaderOffset))), http.StatusBadRequest)
+		return
+	}
+
+	single := offset < 0
+	if single {
+		offset = 0
+		if size < 0 {
+			size = r.ContentLength
+		}
+	}
+
+	// digest is read once the body is consumed since it may be sent as a trailer
+	digest := func() string {
+		if val := r.Trailer.Get(dataplane.HeaderDigest); val != `` {
+			return val
+		}
+		return r.Header.Get(dataplane.HeaderDigest)
+	}
+
+	received, err := h.consumer.Receive(tpId, token, offset, dataplane.Data{Content: r.Body,
+		ContentType: r.Header.Get(`Content-Type`), Size: r.ContentLength}, digest)
+	if err != nil {
+		h.writeSinkError(w, tpId, err)
+		return
+	}
+
+	if single {
+		h.commit(w, tpId, token, size)
+		return
+	}
+
+	if err = middleware.WriteAck(w, dataplane.Ack{Offset: received}, http.StatusOK); err != nil {
+		h.log.Error(errors.Transfer(``, tpId, errors.WriteAckError(`chunk`, err)))
+	}
+}
+
+// HandleSinkCommit concludes a push once all chunks are sent and completes the
+// transfer process once the data is verified
+func (h *Handler) HandleSinkCommit(w http.ResponseWriter, r *http.Request) {
+	tpId, token, ok := h.sinkParams(w, r)
+	if !ok {
+		return
+	}
+
+	size, err := headerInt(r, dataplane.HeaderSize, -1)
+	if err != nil {
+		middleware.WriteError(w, errors.Transfer(``, tpId, errors.InvalidValue(dataplane.HeaderSize,
+			``, r.Header.Get(dataplane.HeaderSize))), http.StatusBadRequest)
+		return
+	}
+
+	h.commit(w, tpId, token, size)
+}
+
+func (h *Handler) commit(w http.ResponseWriter, tpId, token string, size int64) {
+	receipt, err := h.consumer.Commit(tpId, token, size)
+	if err != nil {
+		h.writeSinkError(w, tpId, err)
+		return
+	}
+
+	// provider concludes the transfer process by itself if the completion message fails
+	if err = h.roles.Consumer.CompleteTransfer(tpId); err != nil {
+		h.log.Error(errors.CustomFuncError(`CompleteTransfer`, err))
+	}
+
+	if err = middleware.WriteAck(w, receipt, http.StatusOK); err != nil {
+		h.log.Error(errors.Transfer(``, tpId, errors.WriteAckError(`receipt`, err)))
+	}
+}
+
+func (h *Handler) sinkParams(w http.ResponseWriter, r *http.Request) (tpId, token string, ok bool) {
+	tpId, ok = mux.Vars(r)[api.ParamPid]
+	if !ok {
+		middleware.WriteError(w, errors.Transfer(``, ``,
+			errors.PathParamNotFound(api.ParamPid)), http.StatusBadRequest)
+		return ``, ``, false
+	}
+
+	token, ok = bearerToken(r)
+	if !ok {
+		middleware.WriteError(w, errors.Transfer(``, tpId, errors.Unauthorized(tokenNotFound())),
+			http.StatusUnauthorized)
+		return ``, ``, false
+	}
+	return tpId, token, true
+}
+
+func (h *Handler) writeSinkError(w http.ResponseWriter, tpId string, err error) {
+	var trErr errors.TransferError
+	switch {
+	case defaultErr.Is(err, dataplane.TypeCorrupted):
+		middleware.WriteError(w, errors.Transfer(``, tpId, errors.IncorrectReqValues(err.Error())),
+			http.StatusBadRequest)
+	case defaultErr.As(err, &trErr):
+		middleware.WriteError(w, err, http.StatusForbidden)
+	default:
+		middleware.WriteError(w, errors.Transfer(``, tpId, errors.DataUnavailable(err)),
+			http.StatusInternalServerError)
+	}
+}
+
+// rangeOffset returns the start of a range request in the form of 'bytes=<offset>-'
+// and zero for any other range, in which case the complete data is served
+func rangeOffset(r *http.Request) int64 {
+	val, ok := strings.CutPrefix(r.Header.Get(`Range`), `bytes=`)
+	if !ok {
+		return 0
+	}
+
+	start, ok := strings.CutSuffix(val, `-`)
+	if !ok {
+		return 0
+	}
+
+	offset, err := strconv.ParseInt(start, 10, 64)
+	if err != nil || offset < 0 {
+		return 0
+	}
+	return offset
+}
+
+// headerInt returns the integer value of the header or the default value if the
+// header is not set
+func headerInt(r *http.Request, key string, def int64) (int64, error) {
+	val := r.Header.Get(key)
+	if val == `` {
+		return def, nil
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get(`Authorization`), `Bearer `)
+	return token, ok && token != ``
+}
+
+func tokenNotFound() error {
+	return fmt.Errorf("access token is not found in the Authorization header")
+}
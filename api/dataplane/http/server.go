@@ -0,0 +1,46 @@
+package http
+
+import (
+	"github.com/YasiruR/connector/domain"
+	dpHttp "github.com/YasiruR/connector/domain/api/dataplane/http"
+	"github.com/YasiruR/connector/domain/core/dataplane"
+	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+)
+
+// dataplane.http.Server contains the endpoints through which the data of transfer
+// processes is exchanged. Requests are authorized with the tokens issued for the
+// transfer processes instead of participant tokens.
+
+type Server struct {
+	port   int
+	h      dpHttp.Handler
+	router *mux.Router
+	log    pkg.Log
+}
+
+func NewServer(port int, roles domain.Roles, provider dataplane.Provider, consumer dataplane.Consumer,
+	log pkg.Log) *Server {
+	r := mux.NewRouter()
+	s := Server{
+		port:   port,
+		h:      NewHandler(roles, provider, consumer, log),
+		router: r,
+		log:    log,
+	}
+
+	r.HandleFunc(dpHttp.PullEndpoint, s.h.HandlePull).Methods(http.MethodGet)
+	r.HandleFunc(dpHttp.SinkEndpoint, s.h.HandleSink).Methods(http.MethodPut)
+	r.HandleFunc(dpHttp.SinkEndpoint, s.h.HandleSinkCommit).Methods(http.MethodPost)
+	return &s
+}
+
+func (s *Server) Start() {
+	s.log.Info("data plane HTTP server is listening on " + strconv.Itoa(s.port))
+	if err := http.ListenAndServe(":"+strconv.Itoa(s.port), s.router); err != nil {
+		s.log.Fatal(errors.ModuleInitFailed(`data plane API`, err))
+	}
+}
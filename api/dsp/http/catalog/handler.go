@@ -1,6 +1,8 @@
 package catalog
 
 import (
+	defaultErr "errors"
+	"fmt"
 	"github.com/YasiruR/connector/domain"
 	"github.com/YasiruR/connector/domain/api/dsp/http/catalog"
 	"github.com/YasiruR/connector/domain/core"
@@ -8,6 +10,8 @@ import (
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/pkg/middleware"
 	"net/http"
+	"net/url"
+	"strconv"
 )
 
 type Handler struct {
@@ -29,13 +33,34 @@ func (h *Handler) HandleCatalogRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cat, err := h.provider.HandleCatalogRequest(nil)
+	page, err := parsePage(r)
 	if err != nil {
-		middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleProvider, catalog.RequestEndpoint, err),
-			http.StatusInternalServerError)
+		middleware.WriteError(w, errors.Catalog(errors.IncorrectReqValues(err.Error())), http.StatusBadRequest)
+		return
+	}
+
+	cat, next, err := h.provider.HandleCatalogRequest(req.DspaceFilter, page)
+	if err != nil {
+		// an invalid filter is rejected with a protocol error whereas others are internal errors
+		status := http.StatusInternalServerError
+		var catErr errors.CatalogError
+		if defaultErr.As(err, &catErr) {
+			status = http.StatusBadRequest
+		}
+
+		middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleProvider, catalog.RequestEndpoint, err), status)
 		return
 	}
 
+	// remaining datasets are linked as the next page which is requested with the same body
+	if next.Cursor != `` {
+		query := url.Values{}
+		query.Set(catalog.ParamLimit, strconv.Itoa(next.Limit))
+		query.Set(catalog.ParamCursor, next.Cursor)
+		w.Header().Set(`Link`, fmt.Sprintf(`<%s?%s>; rel="%s"`, catalog.RequestEndpoint, query.Encode(),
+			catalog.LinkRelNext))
+	}
+
 	if err = middleware.WriteAck(w, cat, http.StatusOK); err != nil {
 		middleware.WriteError(w, errors.Catalog(errors.WriteAckError(`catalog request`, err)),
 			http.StatusInternalServerError)
@@ -61,3 +86,18 @@ func (h *Handler) HandleDatasetRequest(w http.ResponseWriter, r *http.Request) {
 			http.StatusInternalServerError)
 	}
 }
+
+// parsePage reads the page of a catalog request from the query parameters, where the
+// page size of the provider applies if the limit is not provided
+func parsePage(r *http.Request) (catalog.Page, error) {
+	query := r.URL.Query()
+	page := catalog.Page{Cursor: query.Get(catalog.ParamCursor)}
+	if val := query.Get(catalog.ParamLimit); val != `` {
+		limit, err := strconv.Atoi(val)
+		if err != nil || limit <= 0 {
+			return catalog.Page{}, fmt.Errorf("limit should be a positive integer (limit: %s)", val)
+		}
+		page.Limit = limit
+	}
+	return page, nil
+}
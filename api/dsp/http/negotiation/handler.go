@@ -64,7 +64,7 @@ func (h *Handler) HandleContractRequest(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	ack, err := h.provider.HandleContractRequest(req)
+	ack, err := h.provider.HandleContractRequest(req, middleware.Participant(r))
 	if err != nil {
 		middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleProvider, endpoint, err), http.StatusBadRequest)
 		return
@@ -92,7 +92,7 @@ func (h *Handler) HandleContractOffer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ack, err := h.consumer.HandleContractOffer(req)
+	ack, err := h.consumer.HandleContractOffer(req, middleware.Participant(r))
 	if err != nil {
 		middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleConsumer, endpoint, err), http.StatusBadRequest)
 		return
@@ -112,7 +112,7 @@ func (h *Handler) HandleContractAgreement(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	ack, err := h.consumer.HandleContractAgreement(req)
+	ack, err := h.consumer.HandleContractAgreement(req, middleware.Participant(r))
 	if err != nil {
 		middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleConsumer, negotiation.ContractAgreementEndpoint,
 			err), http.StatusBadRequest)
@@ -133,7 +133,7 @@ func (h *Handler) HandleAgreementVerification(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	ack, err := h.provider.HandleAgreementVerification(req)
+	ack, err := h.provider.HandleAgreementVerification(req, middleware.Participant(r))
 	if err != nil {
 		middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleProvider,
 			negotiation.AgreementVerificationEndpoint, err), http.StatusBadRequest)
@@ -159,14 +159,14 @@ func (h *Handler) HandleNegotiationEvent(w http.ResponseWriter, r *http.Request)
 
 	switch req.EventType {
 	case negotiation.EventAccepted:
-		ack, err = h.provider.HandleAcceptOffer(req)
+		ack, err = h.provider.HandleAcceptOffer(req, middleware.Participant(r))
 		if err != nil {
 			middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleProvider, negotiation.EventsEndpoint,
 				err), http.StatusBadRequest)
 			return
 		}
 	case negotiation.EventFinalized:
-		ack, err = h.consumer.HandleFinalizedEvent(req)
+		ack, err = h.consumer.HandleFinalizedEvent(req, middleware.Participant(r))
 		if err != nil {
 			middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleConsumer, negotiation.EventsEndpoint,
 				err), http.StatusBadRequest)
@@ -192,7 +192,7 @@ func (h *Handler) HandleTermination(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ack, err := h.provider.HandleContractTermination(req)
+	ack, err := h.provider.HandleContractTermination(req, middleware.Participant(r))
 	if err != nil {
 		middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleProvider, negotiation.TerminateEndpoint,
 			err), http.StatusBadRequest)
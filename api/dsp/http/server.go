@@ -10,6 +10,7 @@ import (
 	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
 	"github.com/YasiruR/connector/domain/errors"
 	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/pkg/middleware"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 	"net/http"
@@ -28,7 +29,7 @@ type Server struct {
 	log    pkg.Log
 }
 
-func NewServer(port int, roles domain.Roles, log pkg.Log) *Server {
+func NewServer(port int, roles domain.Roles, iam pkg.IAM, log pkg.Log) *Server {
 	r := mux.NewRouter()
 	s := Server{
 		port:   port,
@@ -39,6 +40,9 @@ func NewServer(port int, roles domain.Roles, log pkg.Log) *Server {
 		log:    log,
 	}
 
+	// all protocol messages must be sent by an authenticated participant
+	r.Use(middleware.Authenticate(iam))
+
 	// catalog protocol related endpoints
 	r.HandleFunc(catalog.RequestEndpoint, s.ch.HandleCatalogRequest).Methods(http.MethodPost)
 	r.HandleFunc(catalog.RequestDatasetEndpoint, s.ch.HandleDatasetRequest).Methods(http.MethodPost)
@@ -56,7 +56,7 @@ func (h *Handler) HandleTransferRequest(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	ack, err := h.provider.HandleTransferRequest(req)
+	ack, err := h.provider.HandleTransferRequest(req, middleware.Participant(r))
 	if err != nil {
 		middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleProvider,
 			transfer.RequestEndpoint, err), http.StatusBadRequest)
@@ -89,14 +89,14 @@ func (h *Handler) HandleTransferStart(w http.ResponseWriter, r *http.Request) {
 	var err error
 	switch pid {
 	case req.ProvPId:
-		ack, err = h.provider.HandleTransferStart(req)
+		ack, err = h.provider.HandleTransferStart(req, middleware.Participant(r))
 		if err != nil {
 			middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleProvider,
 				transfer.StartEndpoint, err), http.StatusBadRequest)
 			return
 		}
 	case req.ConsPId:
-		ack, err = h.consumer.HandleTransferStart(req)
+		ack, err = h.consumer.HandleTransferStart(req, middleware.Participant(r))
 		if err != nil {
 			middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleConsumer,
 				transfer.StartEndpoint, err), http.StatusBadRequest)
@@ -134,14 +134,14 @@ func (h *Handler) HandleTransferSuspension(w http.ResponseWriter, r *http.Reques
 	var err error
 	switch pid {
 	case req.ProvPId:
-		ack, err = h.provider.HandleTransferSuspension(req)
+		ack, err = h.provider.HandleTransferSuspension(req, middleware.Participant(r))
 		if err != nil {
 			middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleProvider,
 				transfer.SuspendEndpoint, err), http.StatusBadRequest)
 			return
 		}
 	case req.ConsPId:
-		ack, err = h.consumer.HandleTransferSuspension(req)
+		ack, err = h.consumer.HandleTransferSuspension(req, middleware.Participant(r))
 		if err != nil {
 			middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleConsumer,
 				transfer.SuspendEndpoint, err), http.StatusBadRequest)
@@ -179,14 +179,14 @@ func (h *Handler) HandleTransferCompletion(w http.ResponseWriter, r *http.Reques
 	var err error
 	switch pid {
 	case req.ProvPId:
-		ack, err = h.provider.HandleTransferCompletion(req)
+		ack, err = h.provider.HandleTransferCompletion(req, middleware.Participant(r))
 		if err != nil {
 			middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleProvider,
 				transfer.CompleteEndpoint, err), http.StatusBadRequest)
 			return
 		}
 	case req.ConsPId:
-		ack, err = h.consumer.HandleTransferCompletion(req)
+		ack, err = h.consumer.HandleTransferCompletion(req, middleware.Participant(r))
 		if err != nil {
 			middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleConsumer,
 				transfer.CompleteEndpoint, err), http.StatusBadRequest)
@@ -224,14 +224,14 @@ func (h *Handler) HandleTransferTermination(w http.ResponseWriter, r *http.Reque
 	var err error
 	switch pid {
 	case req.ProvPId:
-		ack, err = h.provider.HandleTransferTermination(req)
+		ack, err = h.provider.HandleTransferTermination(req, middleware.Participant(r))
 		if err != nil {
 			middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleProvider,
 				transfer.TerminateEndpoint, err), http.StatusBadRequest)
 			return
 		}
 	case req.ConsPId:
-		ack, err = h.consumer.HandleTransferTermination(req)
+		ack, err = h.consumer.HandleTransferTermination(req, middleware.Participant(r))
 		if err != nil {
 			middleware.WriteError(w, errors.DSPHandlerFailed(core.RoleConsumer,
 				transfer.TerminateEndpoint, err), http.StatusBadRequest)
@@ -4,11 +4,13 @@ import (
 	"github.com/YasiruR/connector/domain"
 	"github.com/YasiruR/connector/domain/api/gateway/http/catalog"
 	"github.com/YasiruR/connector/domain/core"
+	"github.com/YasiruR/connector/domain/core/dataplane"
 	"github.com/YasiruR/connector/domain/errors"
 	"github.com/YasiruR/connector/domain/models/odrl"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
 	"github.com/YasiruR/connector/pkg/middleware"
+	"github.com/gorilla/mux"
 	"net/http"
 )
 
@@ -28,6 +30,74 @@ func NewHandler(roles domain.Roles, stores domain.Stores, log pkg.Log) *Handler
 	}
 }
 
+func (h *Handler) CreateCatalog(w http.ResponseWriter, r *http.Request) {
+	var req catalog.CreateCatalogRequest
+	if err := middleware.ParseRequest(r, &req); err != nil {
+		middleware.WriteError(w, errors.Client(errors.InvalidReqBody(`create catalog`,
+			err)), http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.owner.CreateCatalog(req.ParentId, req.Title, req.Descriptions, req.Keywords, req.Endpoints)
+	if err != nil {
+		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleOwner, `CreateCatalog`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err = middleware.WriteAck(w, catalog.CatalogResponse{Id: id}, http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`create catalog`,
+			err)), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) UpdateCatalog(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)[catalog.ParamCatalogId]
+	if !ok {
+		middleware.WriteError(w, errors.Client(errors.PathParamNotFound(
+			catalog.ParamCatalogId)), http.StatusBadRequest)
+		return
+	}
+
+	var req catalog.UpdateCatalogRequest
+	if err := middleware.ParseRequest(r, &req); err != nil {
+		middleware.WriteError(w, errors.Client(errors.InvalidReqBody(`update catalog`,
+			err)), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.owner.UpdateCatalog(id, req.Title, req.Descriptions, req.Keywords, req.Endpoints); err != nil {
+		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleOwner, `UpdateCatalog`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err := middleware.WriteAck(w, catalog.CatalogResponse{Id: id}, http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`update catalog`,
+			err)), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) DeleteCatalog(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)[catalog.ParamCatalogId]
+	if !ok {
+		middleware.WriteError(w, errors.Client(errors.PathParamNotFound(
+			catalog.ParamCatalogId)), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.owner.DeleteCatalog(id); err != nil {
+		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleOwner, `DeleteCatalog`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err := middleware.WriteAck(w, nil, http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`delete catalog`,
+			err)), http.StatusInternalServerError)
+	}
+}
+
 func (h *Handler) CreatePolicy(w http.ResponseWriter, r *http.Request) {
 	var req catalog.CreatePolicyRequest
 	if err := middleware.ParseRequest(r, &req); err != nil {
@@ -36,20 +106,8 @@ func (h *Handler) CreatePolicy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var perms []odrl.Rule // handle other policy types
-	for _, p := range req.Permissions {
-		var cons []odrl.Constraint
-		for _, c := range p.Constraints {
-			cons = append(cons, odrl.Constraint{
-				LeftOperand:  c.LeftOperand,
-				Operator:     c.Operator,
-				RightOperand: c.RightOperand,
-			})
-		}
-		perms = append(perms, odrl.Rule{Action: odrl.Action(p.Action), Constraints: cons})
-	}
-
-	id, err := h.owner.CreatePolicy(req.Target, perms, []odrl.Rule{})
+	id, err := h.owner.CreatePolicy(req.Target, h.rules(req.Permissions), h.rules(req.Prohibitions),
+		h.duties(req.Obligations))
 	if err != nil {
 		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleOwner, `CreatePolicy`, err),
 			http.StatusInternalServerError)
@@ -62,6 +120,89 @@ func (h *Handler) CreatePolicy(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *Handler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)[catalog.ParamPolicyId]
+	if !ok {
+		middleware.WriteError(w, errors.Client(errors.PathParamNotFound(
+			catalog.ParamPolicyId)), http.StatusBadRequest)
+		return
+	}
+
+	var req catalog.UpdatePolicyRequest
+	if err := middleware.ParseRequest(r, &req); err != nil {
+		middleware.WriteError(w, errors.Client(errors.InvalidReqBody(`update policy`,
+			err)), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.owner.UpdatePolicy(id, req.Target, h.rules(req.Permissions), h.rules(req.Prohibitions),
+		h.duties(req.Obligations)); err != nil {
+		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleOwner, `UpdatePolicy`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err := middleware.WriteAck(w, catalog.PolicyResponse{Id: id}, http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`update policy`,
+			err)), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)[catalog.ParamPolicyId]
+	if !ok {
+		middleware.WriteError(w, errors.Client(errors.PathParamNotFound(
+			catalog.ParamPolicyId)), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.owner.DeletePolicy(id); err != nil {
+		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleOwner, `DeletePolicy`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err := middleware.WriteAck(w, nil, http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`delete policy`,
+			err)), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)[catalog.ParamPolicyId]
+	if !ok {
+		middleware.WriteError(w, errors.Client(errors.PathParamNotFound(
+			catalog.ParamPolicyId)), http.StatusBadRequest)
+		return
+	}
+
+	ofr, err := h.owner.Policy(id)
+	if err != nil {
+		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleOwner, `Policy`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err = middleware.WriteAck(w, ofr, http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`get policy`,
+			err)), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) GetPolicies(w http.ResponseWriter, _ *http.Request) {
+	ofrs, err := h.owner.Policies()
+	if err != nil {
+		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleOwner, `Policies`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err = middleware.WriteAck(w, ofrs, http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`get policies`,
+			err)), http.StatusInternalServerError)
+	}
+}
+
 func (h *Handler) CreateDataset(w http.ResponseWriter, r *http.Request) {
 	var req catalog.CreateDatasetRequest
 	if err := middleware.ParseRequest(r, &req); err != nil {
@@ -70,7 +211,17 @@ func (h *Handler) CreateDataset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := h.owner.CreateDataset(req.Title, req.Format, req.Descriptions, req.Keywords, req.Endpoints, req.OfferIds)
+	var dists []core.Distribution
+	for _, d := range req.Distributions {
+		dists = append(dists, core.Distribution{
+			Format:    d.Format,
+			MediaType: d.MediaType,
+			Endpoints: d.Endpoints,
+			Source:    dataplane.Source{ID: d.DataSource.ID, Path: d.DataSource.Path},
+		})
+	}
+
+	id, err := h.owner.CreateDataset(req.CatalogId, req.Title, req.Descriptions, req.Keywords, req.OfferIds, dists)
 	if err != nil {
 		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleOwner, `CreateDataset`, err),
 			http.StatusInternalServerError)
@@ -83,6 +234,88 @@ func (h *Handler) CreateDataset(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *Handler) UpdateDataset(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)[catalog.ParamDatasetId]
+	if !ok {
+		middleware.WriteError(w, errors.Client(errors.PathParamNotFound(
+			catalog.ParamDatasetId)), http.StatusBadRequest)
+		return
+	}
+
+	var req catalog.UpdateDatasetRequest
+	if err := middleware.ParseRequest(r, &req); err != nil {
+		middleware.WriteError(w, errors.Client(errors.InvalidReqBody(`update dataset`,
+			err)), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.owner.UpdateDataset(id, req.Title, req.Descriptions, req.Keywords, req.OfferIds); err != nil {
+		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleOwner, `UpdateDataset`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err := middleware.WriteAck(w, catalog.DatasetResponse{Id: id}, http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`update dataset`,
+			err)), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) DeleteDataset(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)[catalog.ParamDatasetId]
+	if !ok {
+		middleware.WriteError(w, errors.Client(errors.PathParamNotFound(
+			catalog.ParamDatasetId)), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.owner.DeleteDataset(id); err != nil {
+		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleOwner, `DeleteDataset`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err := middleware.WriteAck(w, nil, http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`delete dataset`,
+			err)), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) GetDataset(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)[catalog.ParamDatasetId]
+	if !ok {
+		middleware.WriteError(w, errors.Client(errors.PathParamNotFound(
+			catalog.ParamDatasetId)), http.StatusBadRequest)
+		return
+	}
+
+	ds, err := h.owner.Dataset(id)
+	if err != nil {
+		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleOwner, `Dataset`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err = middleware.WriteAck(w, ds, http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`get dataset`,
+			err)), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) GetDatasets(w http.ResponseWriter, _ *http.Request) {
+	datasets, err := h.owner.Datasets()
+	if err != nil {
+		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleOwner, `Datasets`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err = middleware.WriteAck(w, datasets, http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`get datasets`,
+			err)), http.StatusInternalServerError)
+	}
+}
+
 func (h *Handler) RequestCatalog(w http.ResponseWriter, r *http.Request) {
 	var req catalog.Request
 	if err := middleware.ParseRequest(r, &req); err != nil {
@@ -91,7 +324,7 @@ func (h *Handler) RequestCatalog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cat, err := h.consumer.RequestCatalog(req.ProviderEndpoint)
+	cat, err := h.consumer.RequestCatalog(req.ProviderEndpoint, req.Filter)
 	if err != nil {
 		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleConsumer, `RequestCatalog`, err),
 			http.StatusInternalServerError)
@@ -138,3 +371,50 @@ func (h *Handler) GetStoredCatalogs(w http.ResponseWriter, _ *http.Request) {
 			err)), http.StatusInternalServerError)
 	}
 }
+
+func (h *Handler) rules(reqRules []catalog.Rule) []odrl.Rule {
+	var rules []odrl.Rule
+	for _, r := range reqRules {
+		rules = append(rules, odrl.Rule{
+			Action:      odrl.Action(r.Action),
+			Constraints: h.constraints(r.Constraints),
+			Duties:      h.duties(r.Duties),
+			Remedies:    h.duties(r.Remedies),
+		})
+	}
+	return rules
+}
+
+func (h *Handler) duties(reqDuties []catalog.Duty) []odrl.Duty {
+	var duties []odrl.Duty
+	for _, d := range reqDuties {
+		duties = append(duties, odrl.Duty{
+			Action:       odrl.Action(d.Action),
+			Constraints:  h.constraints(d.Constraints),
+			Consequences: h.duties(d.Consequences),
+		})
+	}
+	return duties
+}
+
+func (h *Handler) constraints(reqConstraints []catalog.Constraint) []odrl.Constraint {
+	var cons []odrl.Constraint
+	for _, c := range reqConstraints {
+		right := odrl.TypedLiteral(c.RightOperand, c.DataType)
+		if c.DataType == `@id` {
+			right = odrl.IRI(c.RightOperand)
+		}
+
+		cons = append(cons, odrl.Constraint{
+			LeftOperand:           c.LeftOperand,
+			Operator:              c.Operator,
+			RightOperand:          right,
+			RightOperandReference: c.RightOperandReference,
+			Unit:                  c.Unit,
+			And:                   h.constraints(c.And),
+			Or:                    h.constraints(c.Or),
+			Xone:                  h.constraints(c.Xone),
+		})
+	}
+	return cons
+}
@@ -0,0 +1,80 @@
+package fetch
+
+import (
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/api/gateway/http/fetch"
+	"github.com/YasiruR/connector/domain/core"
+	"github.com/YasiruR/connector/domain/core/consumer"
+	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/pkg/middleware"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+type Handler struct {
+	consumer core.Consumer
+	log      pkg.Log
+}
+
+func NewHandler(roles domain.Roles, log pkg.Log) *Handler {
+	return &Handler{
+		consumer: roles.Consumer,
+		log:      log,
+	}
+}
+
+// NegotiateAndFetch returns the ID of the fetch once the offer is selected, after which
+// the negotiation and transfer are followed by the fetch
+func (h *Handler) NegotiateAndFetch(w http.ResponseWriter, r *http.Request) {
+	var req fetch.Request
+	if err := middleware.ParseRequest(r, &req); err != nil {
+		middleware.WriteError(w, errors.Client(errors.InvalidReqBody(`negotiate and fetch`,
+			err)), http.StatusBadRequest)
+		return
+	}
+
+	fetchId, err := h.consumer.NegotiateAndFetch(consumer.FetchRequest{
+		ProviderEndpoint: req.ProviderEndpoint,
+		DatasetId:        req.DatasetId,
+		OfferId:          req.OfferId,
+		Constraints:      req.Constraints,
+		Format:           req.TransferFormat,
+		MediaType:        req.MediaType,
+		SinkEndpoint:     req.SinkEndpoint,
+	})
+	if err != nil {
+		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleConsumer, `NegotiateAndFetch`, err),
+			http.StatusBadRequest)
+		return
+	}
+
+	if err = middleware.WriteAck(w, fetch.Response{FetchId: fetchId}, http.StatusAccepted); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`negotiate and fetch`,
+			err)), http.StatusInternalServerError)
+	}
+}
+
+// GetFetch returns the progress of a fetch along with the contract negotiation and
+// transfer process IDs once they are created
+func (h *Handler) GetFetch(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	fetchId, ok := params[fetch.ParamFetchId]
+	if !ok {
+		middleware.WriteError(w, errors.Client(errors.PathParamNotFound(fetch.ParamFetchId)),
+			http.StatusBadRequest)
+		return
+	}
+
+	ft, err := h.consumer.Fetch(fetchId)
+	if err != nil {
+		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleConsumer, `Fetch`, err),
+			http.StatusBadRequest)
+		return
+	}
+
+	if err = middleware.WriteAck(w, ft, http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`get fetch`,
+			err)), http.StatusInternalServerError)
+	}
+}
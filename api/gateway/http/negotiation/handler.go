@@ -1,6 +1,7 @@
 package negotiation
 
 import (
+	defaultErr "errors"
 	"github.com/YasiruR/connector/domain"
 	"github.com/YasiruR/connector/domain/api/gateway/http/negotiation"
 	"github.com/YasiruR/connector/domain/core"
@@ -15,6 +16,7 @@ import (
 type Handler struct {
 	provider core.Provider
 	consumer core.Consumer
+	cnStore  stores.ContractNegotiationStore
 	agrStore stores.AgreementStore
 	log      pkg.Log
 }
@@ -23,6 +25,7 @@ func NewHandler(roles domain.Roles, stores domain.Stores, log pkg.Log) *Handler
 	return &Handler{
 		provider: roles.Provider,
 		consumer: roles.Consumer,
+		cnStore:  stores.ContractNegotiationStore,
 		agrStore: stores.AgreementStore,
 		log:      log,
 	}
@@ -57,7 +60,8 @@ func (h *Handler) OfferContract(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cnId, err := h.provider.OfferContract(req.OfferId, req.ProviderPid, req.ConsumerAddr)
+	cnId, err := h.provider.OfferContract(req.OfferId, req.ProviderPid, req.ConsumerAddr, req.ConsumerId,
+		req.Constraints)
 	if err != nil {
 		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleProvider, `OfferContract`, err),
 			http.StatusInternalServerError)
@@ -205,3 +209,33 @@ func (h *Handler) TerminateContract(w http.ResponseWriter, r *http.Request) {
 	middleware.WriteError(w, errors.Client(errors.IncorrectReqValues(
 		`only one of consumer and provider process IDs should be provided`)), http.StatusBadRequest)
 }
+
+// GetRounds returns the offers proposed in each round of a negotiation along with the
+// terms that differ from the offer published by the provider
+func (h *Handler) GetRounds(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	providerPid, ok := params[negotiation.ParamProviderPid]
+	if !ok {
+		middleware.WriteError(w, errors.Client(errors.PathParamNotFound(
+			negotiation.ParamProviderPid)), http.StatusBadRequest)
+		return
+	}
+
+	rounds, err := h.cnStore.Rounds(providerPid)
+	if err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			middleware.WriteError(w, errors.Client(errors.InvalidKey(stores.TypeContractNegotiation,
+				`provider pid`, err)), http.StatusBadRequest)
+			return
+		}
+		middleware.WriteError(w, errors.StoreFailed(stores.TypeContractNegotiation, `Rounds`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err = middleware.WriteAck(w, negotiation.RoundsResponse{ProviderPid: providerPid, Rounds: rounds},
+		http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`get negotiation rounds`,
+			err)), http.StatusInternalServerError)
+	}
+}
@@ -2,10 +2,12 @@ package http
 
 import (
 	httpCatalog "github.com/YasiruR/connector/api/gateway/http/catalog"
+	httpFetch "github.com/YasiruR/connector/api/gateway/http/fetch"
 	httpNegotiation "github.com/YasiruR/connector/api/gateway/http/negotiation"
 	httpTransfer "github.com/YasiruR/connector/api/gateway/http/transfer"
 	"github.com/YasiruR/connector/domain"
 	"github.com/YasiruR/connector/domain/api/gateway/http/catalog"
+	"github.com/YasiruR/connector/domain/api/gateway/http/fetch"
 	"github.com/YasiruR/connector/domain/api/gateway/http/negotiation"
 	"github.com/YasiruR/connector/domain/api/gateway/http/transfer"
 	"github.com/YasiruR/connector/domain/errors"
@@ -25,6 +27,7 @@ type Server struct {
 	ch     catalog.Handler
 	nh     negotiation.Handler
 	th     transfer.Handler
+	fh     fetch.Handler
 	log    pkg.Log
 }
 
@@ -35,13 +38,25 @@ func NewServer(port int, roles domain.Roles, stores domain.Stores, log pkg.Log)
 		router: r,
 		ch:     httpCatalog.NewHandler(roles, stores, log),
 		nh:     httpNegotiation.NewHandler(roles, stores, log),
-		th:     httpTransfer.NewHandler(roles, log),
+		th:     httpTransfer.NewHandler(roles, stores, log),
+		fh:     httpFetch.NewHandler(roles, log),
 		log:    log,
 	}
 
 	// endpoints related to catalog
+	r.HandleFunc(catalog.CreateCatalogEndpoint, s.ch.CreateCatalog).Methods(http.MethodPost)
+	r.HandleFunc(catalog.UpdateCatalogEndpoint, s.ch.UpdateCatalog).Methods(http.MethodPost)
+	r.HandleFunc(catalog.DeleteCatalogEndpoint, s.ch.DeleteCatalog).Methods(http.MethodPost)
 	r.HandleFunc(catalog.CreatePolicyEndpoint, s.ch.CreatePolicy).Methods(http.MethodPost)
+	r.HandleFunc(catalog.UpdatePolicyEndpoint, s.ch.UpdatePolicy).Methods(http.MethodPost)
+	r.HandleFunc(catalog.DeletePolicyEndpoint, s.ch.DeletePolicy).Methods(http.MethodPost)
+	r.HandleFunc(catalog.GetPolicyEndpoint, s.ch.GetPolicy).Methods(http.MethodGet)
+	r.HandleFunc(catalog.GetPoliciesEndpoint, s.ch.GetPolicies).Methods(http.MethodGet)
 	r.HandleFunc(catalog.CreateDatasetEndpoint, s.ch.CreateDataset).Methods(http.MethodPost)
+	r.HandleFunc(catalog.UpdateDatasetEndpoint, s.ch.UpdateDataset).Methods(http.MethodPost)
+	r.HandleFunc(catalog.DeleteDatasetEndpoint, s.ch.DeleteDataset).Methods(http.MethodPost)
+	r.HandleFunc(catalog.GetDatasetEndpoint, s.ch.GetDataset).Methods(http.MethodGet)
+	r.HandleFunc(catalog.GetDatasetsEndpoint, s.ch.GetDatasets).Methods(http.MethodGet)
 	r.HandleFunc(catalog.RequestCatalogEndpoint, s.ch.RequestCatalog).Methods(http.MethodPost)
 	r.HandleFunc(catalog.RequestDatasetEndpoint, s.ch.RequestDataset).Methods(http.MethodPost)
 	r.HandleFunc(catalog.GetStoredCatalogsEndpoint, s.ch.GetStoredCatalogs).Methods(http.MethodGet)
@@ -55,6 +70,7 @@ func NewServer(port int, roles domain.Roles, stores domain.Stores, log pkg.Log)
 	r.HandleFunc(negotiation.VerifyAgreementEndpoint, s.nh.VerifyAgreement).Methods(http.MethodPost)
 	r.HandleFunc(negotiation.FinalizeContractEndpoint, s.nh.FinalizeContract).Methods(http.MethodPost)
 	r.HandleFunc(negotiation.TerminateContractEndpoint, s.nh.TerminateContract).Methods(http.MethodPost)
+	r.HandleFunc(negotiation.RoundsEndpoint, s.nh.GetRounds).Methods(http.MethodGet)
 
 	// endpoints related to transfer process
 	r.HandleFunc(transfer.GetProcessEndpoint, s.th.GetProviderProcess).Methods(http.MethodGet)
@@ -63,6 +79,12 @@ func NewServer(port int, roles domain.Roles, stores domain.Stores, log pkg.Log)
 	r.HandleFunc(transfer.SuspendEndpoint, s.th.SuspendTransfer).Methods(http.MethodPost)
 	r.HandleFunc(transfer.CompleteEndpoint, s.th.CompleteTransfer).Methods(http.MethodPost)
 	r.HandleFunc(transfer.TerminateEndpoint, s.th.TerminateTransfer).Methods(http.MethodPost)
+	r.HandleFunc(transfer.HistoryEndpoint, s.th.GetHistory).Methods(http.MethodGet)
+	r.HandleFunc(transfer.ProgressEndpoint, s.th.GetProgress).Methods(http.MethodGet)
+
+	// endpoints related to fetching datasets by the consumer
+	r.HandleFunc(fetch.NegotiateAndFetchEndpoint, s.fh.NegotiateAndFetch).Methods(http.MethodPost)
+	r.HandleFunc(fetch.GetFetchEndpoint, s.fh.GetFetch).Methods(http.MethodGet)
 
 	return &s
 }
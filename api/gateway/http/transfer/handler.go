@@ -1,12 +1,14 @@
 package transfer
 
 import (
+	defaultErr "errors"
 	"github.com/YasiruR/connector/domain"
 	"github.com/YasiruR/connector/domain/api"
 	"github.com/YasiruR/connector/domain/api/gateway/http/transfer"
 	"github.com/YasiruR/connector/domain/core"
 	"github.com/YasiruR/connector/domain/errors"
 	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/domain/stores"
 	"github.com/YasiruR/connector/pkg/middleware"
 	"github.com/gorilla/mux"
 	"net/http"
@@ -15,13 +17,17 @@ import (
 type Handler struct {
 	provider core.Provider
 	consumer core.Consumer
+	tpStore  stores.TransferStore
+	dpStore  stores.DataPlaneStore
 	log      pkg.Log
 }
 
-func NewHandler(roles domain.Roles, log pkg.Log) *Handler {
+func NewHandler(roles domain.Roles, stores domain.Stores, log pkg.Log) *Handler {
 	return &Handler{
 		provider: roles.Provider,
 		consumer: roles.Consumer,
+		tpStore:  stores.TransferStore,
+		dpStore:  stores.DataPlaneStore,
 		log:      log,
 	}
 }
@@ -55,7 +61,8 @@ func (h *Handler) RequestTransfer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	trId, err := h.consumer.RequestTransfer(req.TransferFormat, req.AgreementId, req.SinkEndpoint, req.ProviderEndpoint)
+	trId, err := h.consumer.RequestTransfer(req.TransferFormat, req.MediaType, req.AgreementId, req.SinkEndpoint,
+		req.ProviderEndpoint)
 	if err != nil {
 		middleware.WriteError(w, errors.DSPControllerFailed(core.RoleConsumer,
 			`RequestTransfer`, err), http.StatusInternalServerError)
@@ -179,3 +186,60 @@ func (h *Handler) TerminateTransfer(w http.ResponseWriter, r *http.Request) {
 			err)), http.StatusInternalServerError)
 	}
 }
+
+// GetHistory returns the state transitions of a transfer process stored by either
+// the provider or the consumer, along with the initiator and reasons of each
+func (h *Handler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tpId, ok := vars[api.ParamPid]
+	if !ok {
+		middleware.WriteError(w, errors.Client(errors.PathParamNotFound(api.ParamPid)), http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.tpStore.History(tpId)
+	if err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			middleware.WriteError(w, errors.Client(errors.InvalidKey(stores.TypeTransfer,
+				`transfer process id`, err)), http.StatusBadRequest)
+			return
+		}
+		middleware.WriteError(w, errors.StoreFailed(stores.TypeTransfer, `History`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err = middleware.WriteAck(w, transfer.HistoryResponse{TransferID: tpId, Transitions: history},
+		http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`get transfer history`,
+			err)), http.StatusInternalServerError)
+	}
+}
+
+// GetProgress returns the number of bytes exchanged by the data plane for a transfer process
+func (h *Handler) GetProgress(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tpId, ok := vars[api.ParamPid]
+	if !ok {
+		middleware.WriteError(w, errors.Client(errors.PathParamNotFound(api.ParamPid)), http.StatusBadRequest)
+		return
+	}
+
+	progress, err := h.dpStore.Progress(tpId)
+	if err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			middleware.WriteError(w, errors.Client(errors.InvalidKey(stores.TypeDataPlane,
+				`transfer process id`, err)), http.StatusBadRequest)
+			return
+		}
+		middleware.WriteError(w, errors.StoreFailed(stores.TypeDataPlane, `Progress`, err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	if err = middleware.WriteAck(w, transfer.ProgressResponse{TransferID: tpId, Progress: progress},
+		http.StatusOK); err != nil {
+		middleware.WriteError(w, errors.Client(errors.WriteAckError(`get transfer progress`,
+			err)), http.StatusInternalServerError)
+	}
+}
@@ -22,6 +22,16 @@ func loadConfig(log pkg.Log) boot.Config {
 		log.Fatal(fmt.Sprintf("unmarshalling %s failed - %s", configFile, err))
 	}
 
+	// participant ID is the identity authenticated by other participants and
+	// therefore, is used in policies unless specified otherwise
+	if c.DataSpace.AssignerId == `` {
+		c.DataSpace.AssignerId = c.DataSpace.ParticipantId
+	}
+
+	if c.DataSpace.AssigneeId == `` {
+		c.DataSpace.AssigneeId = c.DataSpace.ParticipantId
+	}
+
 	pwd, err := os.Getwd()
 	if err != nil {
 		log.Error(fmt.Sprintf("could not fetch current working directory - %s", err))
@@ -1,30 +1,54 @@
 package boot
 
 import (
+	"fmt"
+	dataplaneHttp "github.com/YasiruR/connector/api/dataplane/http"
 	dspSHttp "github.com/YasiruR/connector/api/dsp/http"
 	gatewayHttp "github.com/YasiruR/connector/api/gateway/http"
 	"github.com/YasiruR/connector/core/consumer"
+	"github.com/YasiruR/connector/core/dataplane"
 	"github.com/YasiruR/connector/core/owner"
 	"github.com/YasiruR/connector/core/provider"
 	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/boot"
+	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/pkg/client/http"
 	"github.com/YasiruR/connector/pkg/database/memory"
+	"github.com/YasiruR/connector/pkg/database/sqlite"
+	"github.com/YasiruR/connector/pkg/datasource"
+	"github.com/YasiruR/connector/pkg/datasource/directory"
+	"github.com/YasiruR/connector/pkg/datasource/file"
+	dsHttp "github.com/YasiruR/connector/pkg/datasource/http"
+	"github.com/YasiruR/connector/pkg/datasource/s3"
+	"github.com/YasiruR/connector/pkg/iam/jwt"
 	pkgLog "github.com/YasiruR/connector/pkg/log"
+	pkgPolicy "github.com/YasiruR/connector/pkg/policy"
+	"github.com/YasiruR/connector/pkg/storage/local"
 	"github.com/YasiruR/connector/pkg/urn"
 	"github.com/YasiruR/connector/stores/catalog"
+	storesDataplane "github.com/YasiruR/connector/stores/dataplane"
 	"github.com/YasiruR/connector/stores/policy"
 	"github.com/YasiruR/connector/stores/protocol"
+	"strconv"
+	"time"
 )
 
 var log = pkgLog.NewLogger()
 
 var config = loadConfig(log)
 
+var iam = newIAM(config)
+
 var plugins = domain.Plugins{
-	Client:     http.NewClient(log),
-	Database:   memory.NewStore(log),
-	URNService: urn.NewGenerator(),
-	Log:        log,
+	Client:       http.NewClient(iam, log),
+	Database:     newDatabase(config),
+	URNService:   urn.NewGenerator(),
+	PolicyEngine: pkgPolicy.NewEngine(log),
+	IAM:          iam,
+	Storage:      newStorage(config),
+	DataSources:  newDataSources(config),
+	Log:          log,
 }
 
 var stores = domain.Stores{
@@ -34,15 +58,94 @@ var stores = domain.Stores{
 	ContractNegotiationStore: protocol.NewContractNegotiationStore(plugins),
 	AgreementStore:           policy.NewAgreementStore(plugins),
 	TransferStore:            protocol.NewTransferStore(plugins),
+	DataPlaneStore:           storesDataplane.NewStore(plugins),
+	FetchStore:               protocol.NewFetchStore(plugins),
 }
 
+var dataPlane = dataplane.NewProvider(config, stores, plugins)
+
+var sink = dataplane.NewConsumer(config, stores, plugins)
+
 var roles = domain.Roles{
-	Provider: provider.New(config, stores, plugins),
-	Consumer: consumer.New(config, stores, plugins),
+	Provider: provider.New(config, stores, plugins, dataPlane),
+	Consumer: consumer.New(config, stores, plugins, sink),
 	Owner:    owner.New(config, stores, plugins),
 }
 
 var servers = domain.Servers{
-	DSP:     dspSHttp.NewServer(config.Servers.DSP.HTTP.Port, roles, plugins.Log),
+	DSP:     dspSHttp.NewServer(config.Servers.DSP.HTTP.Port, roles, plugins.IAM, plugins.Log),
 	Gateway: gatewayHttp.NewServer(config.Servers.Gateway.HTTP.Port, roles, stores, plugins.Log),
+	DataPlane: dataplaneHttp.NewServer(config.Servers.DataPlane.HTTP.Port, roles, dataPlane, sink,
+		plugins.Log),
+}
+
+// newDatabase initializes the database configured for the connector
+func newDatabase(cfg boot.Config) pkg.Database {
+	switch cfg.Database.Type {
+	case sqlite.Type:
+		return sqlite.NewStore(cfg.Database.Path, log)
+	default:
+		return memory.NewStore(log)
+	}
+}
+
+// newStorage initializes the storage in which the consumer stores the received data
+func newStorage(cfg boot.Config) pkg.Storage {
+	switch cfg.Storage.Type {
+	case ``, local.Type:
+		return local.NewStorage(cfg.Storage.Path, log)
+	default:
+		log.Fatal(fmt.Sprintf("storage type is not supported (type: %s)", cfg.Storage.Type))
+		return nil
+	}
+}
+
+// newDataSources initializes the data sources configured for datasets
+func newDataSources(cfg boot.Config) pkg.DataSources {
+	reg := datasource.NewRegistry()
+	for _, ds := range cfg.DataSources {
+		var src pkg.DataSource
+		switch ds.Type {
+		case file.Type:
+			src = file.NewSource(ds.Path, log)
+		case directory.Type:
+			src = directory.NewSource(ds.Path, log)
+		case dsHttp.Type:
+			src = dsHttp.NewSource(ds.URL, log)
+		case s3.Type:
+			src = s3.NewSource(ds.URL, ds.Bucket, ds.Region, ds.AccessKey, ds.SecretKey, log)
+		default:
+			log.Fatal(fmt.Sprintf("data source type is not supported (id: %s, type: %s)", ds.ID, ds.Type))
+		}
+
+		if err := reg.Register(ds.ID, src); err != nil {
+			log.Fatal(errors.ModuleInitFailed(`data source`, err))
+		}
+	}
+	return reg
+}
+
+// newIAM initializes the identity provider configured for the connector
+func newIAM(cfg boot.Config) pkg.IAM {
+	switch cfg.IAM.Type {
+	case ``, jwt.Type:
+		trusted := make(map[string]string)
+		for _, p := range cfg.IAM.Trusted {
+			trusted[p.ParticipantId] = p.PublicKey
+		}
+
+		// tokens are addressed to the access services of the catalog or the callback
+		// address of the connector unless the addresses are configured explicitly
+		audiences := cfg.IAM.Audiences
+		if len(audiences) == 0 {
+			audiences = append([]string{cfg.Servers.IP + `:` + strconv.Itoa(cfg.Servers.DSP.HTTP.Port)},
+				cfg.Catalog.AccessServices...)
+		}
+
+		return jwt.NewIssuer(cfg.DataSpace.ParticipantId, cfg.IAM.PrivateKey, trusted, audiences,
+			time.Duration(cfg.IAM.TokenTTL)*time.Second, log)
+	default:
+		log.Fatal(fmt.Sprintf("IAM type is not supported (type: %s)", cfg.IAM.Type))
+		return nil
+	}
 }
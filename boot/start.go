@@ -2,5 +2,6 @@ package boot
 
 func Start() {
 	go servers.DSP.Start()
+	go servers.DataPlane.Start()
 	servers.Gateway.Start()
 }
@@ -21,11 +21,19 @@ func NewController(s domain.Stores, client pkg.Client, log pkg.Log) *Controller
 	return &Controller{client: client, catalog: s.ConsumerCatalog, log: log}
 }
 
-func (c *Controller) RequestCatalog(endpoint string) (catalog.Response, error) {
+// RequestCatalog requests the datasets of the provider which match the filter, following
+// the pages of a paginated response until the last page. Datasets of a filtered catalog
+// are merged with the stored catalog of the provider so that previously received
+// datasets (and their offers) are retained.
+func (c *Controller) RequestCatalog(endpoint string, filter []string) (catalog.Response, error) {
+	if _, err := catalog.ParseFilter(filter); err != nil {
+		return catalog.Response{}, errors.Client(errors.IncorrectReqValues(err.Error()))
+	}
+
 	req := catalog.Request{
 		Context:      core.Context,
 		Type:         catalog.MsgTypRequest,
-		DspaceFilter: nil,
+		DspaceFilter: filter,
 	}
 
 	data, err := json.Marshal(req)
@@ -33,22 +41,51 @@ func (c *Controller) RequestCatalog(endpoint string) (catalog.Response, error) {
 		return catalog.Response{}, errors.Client(errors.MarshalError(`catalog request`, err))
 	}
 
-	res, err := c.client.Send(data, endpoint+catalog.RequestEndpoint)
-	if err != nil {
-		var catErr catalog.Error
-		if unmarshalErr := json.Unmarshal(res, &catErr); unmarshalErr != nil {
-			return catalog.Response{}, errors.Client(errors.SendFailed(unmarshalErr))
+	var cat catalog.Response
+	var dest any = endpoint + catalog.RequestEndpoint
+	for pages := 0; dest != nil; pages++ {
+		res, next, err := c.client.SendPage(data, dest)
+		if err != nil {
+			var catErr catalog.Error
+			if unmarshalErr := json.Unmarshal(res, &catErr); unmarshalErr != nil {
+				return catalog.Response{}, errors.Client(errors.SendFailed(unmarshalErr))
+			}
+
+			return catalog.Response{}, errors.Client(errors.ProtocolFailed(core.CatalogProtocol, catErr, err))
+		}
+
+		var page catalog.Response
+		if err = json.Unmarshal(res, &page); err != nil {
+			return catalog.Response{}, errors.Client(errors.UnmarshalError(`catalog response`, err))
+		}
+
+		// datasets of nested catalogs are merged into the corresponding catalogs
+		if pages == 0 {
+			cat = page
+		} else {
+			cat.Catalog = cat.Merge(page.Catalog)
 		}
 
-		return catalog.Response{}, errors.Client(errors.ProtocolFailed(core.CatalogProtocol, catErr, err))
+		// an empty page terminates the loop even if a provider keeps linking further pages
+		if len(page.Datasets()) == 0 {
+			break
+		}
+
+		if next != nil {
+			c.log.Trace(fmt.Sprintf("requesting the next page of the catalog (received: %d, next: %v)",
+				len(cat.Datasets()), next))
+		}
+		dest = next
 	}
 
-	var cat catalog.Response
-	if err = json.Unmarshal(res, &cat); err != nil {
-		return catalog.Response{}, errors.Client(errors.UnmarshalError(`catalog response`, err))
+	stored := cat
+	if len(filter) > 0 {
+		stored = c.merge(cat)
 	}
 
-	c.catalog.AddCatalog(cat)
+	if err := c.catalog.AddCatalog(endpoint, stored); err != nil {
+		return catalog.Response{}, errors.StoreFailed(stores.TypeConsumerCatalog, `AddCatalog`, err)
+	}
 	c.log.Trace(fmt.Sprintf("stored the requested catalog (id: %s)", cat.ID))
 	return cat, nil
 }
@@ -82,3 +119,16 @@ func (c *Controller) RequestDataset(id, endpoint string) (catalog.DatasetRespons
 
 	return dataset, nil
 }
+
+// merge appends the stored datasets of the provider which are not included in the
+// filtered catalog
+func (c *Controller) merge(filtered catalog.Response) catalog.Response {
+	stored, err := c.catalog.Catalog(filtered.DspaceParticipantID)
+	if err != nil {
+		return filtered
+	}
+
+	merged := filtered
+	merged.Catalog = filtered.Merge(stored.Catalog)
+	return merged
+}
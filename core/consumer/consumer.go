@@ -2,11 +2,13 @@ package consumer
 
 import (
 	"github.com/YasiruR/connector/core/consumer/catalog"
+	"github.com/YasiruR/connector/core/consumer/fetch"
 	"github.com/YasiruR/connector/core/consumer/negotiation"
 	"github.com/YasiruR/connector/core/consumer/transfer"
 	"github.com/YasiruR/connector/domain"
 	"github.com/YasiruR/connector/domain/boot"
 	"github.com/YasiruR/connector/domain/core/consumer"
+	"github.com/YasiruR/connector/domain/core/dataplane"
 )
 
 // validator should verify states before transitioning into next, signatures, authorization
@@ -17,14 +19,19 @@ type Consumer struct {
 	consumer.NegotiationHandler
 	consumer.TransferController
 	consumer.TransferHandler
+	consumer.Fetcher
 }
 
-func New(cfg boot.Config, stores domain.Stores, plugins domain.Plugins) *Consumer {
+func New(cfg boot.Config, stores domain.Stores, plugins domain.Plugins, dp dataplane.Consumer) *Consumer {
+	cc := catalog.NewController(stores, plugins.Client, plugins)
+	nc := negotiation.NewController(cfg, stores, plugins)
+	tc := transfer.NewController(cfg, stores, plugins, dp)
 	return &Consumer{
-		CatalogController:     catalog.NewController(stores, plugins.Client, plugins),
-		NegotiationController: negotiation.NewController(cfg, stores, plugins),
-		NegotiationHandler:    negotiation.NewHandler(stores, plugins),
-		TransferController:    transfer.NewController(cfg, stores, plugins),
+		CatalogController:     cc,
+		NegotiationController: nc,
+		NegotiationHandler:    negotiation.NewHandler(stores, plugins, nc),
+		TransferController:    tc,
 		TransferHandler:       transfer.NewHandler(stores.TransferStore, plugins.Log),
+		Fetcher:               fetch.NewFetcher(stores, plugins, cc, nc, tc),
 	}
 }
@@ -0,0 +1,15 @@
+package fetch
+
+import "fmt"
+
+func negotiationTimedOut(consumerPid string) error {
+	return fmt.Errorf("provider did not finalize the contract negotiation in time (id: %s)", consumerPid)
+}
+
+func negotiationTerminated(consumerPid string) error {
+	return fmt.Errorf("contract negotiation was terminated (id: %s)", consumerPid)
+}
+
+func transferTerminated(tpId string) error {
+	return fmt.Errorf("transfer process was terminated (id: %s)", tpId)
+}
@@ -0,0 +1,298 @@
+package fetch
+
+import (
+	defaultErr "errors"
+	"fmt"
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"github.com/YasiruR/connector/domain/core/consumer"
+	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/models/dcat"
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/domain/stores"
+	"time"
+)
+
+const (
+	// pollInterval is the interval at which the state of the negotiation is checked
+	// for the steps of the provider
+	pollInterval = 500 * time.Millisecond
+	// negotiationTimeout is the duration after which a fetch fails if the provider has
+	// not finalized the negotiation, which is then left for manual steps
+	negotiationTimeout = 10 * time.Minute
+)
+
+// Fetcher chains the catalog, negotiation and transfer controllers of the consumer to
+// fetch a dataset, while waiting for the steps of the provider in between
+type Fetcher struct {
+	catalog     consumer.CatalogController
+	negotiation consumer.NegotiationController
+	transfer    consumer.TransferController
+	catStore    stores.ConsumerCatalog
+	cnStore     stores.ContractNegotiationStore
+	agrStore    stores.AgreementStore
+	tpStore     stores.TransferStore
+	fetchStore  stores.FetchStore
+	urn         pkg.URNService
+	log         pkg.Log
+}
+
+func NewFetcher(stores domain.Stores, plugins domain.Plugins, cc consumer.CatalogController,
+	nc consumer.NegotiationController, tc consumer.TransferController) *Fetcher {
+	return &Fetcher{
+		catalog:     cc,
+		negotiation: nc,
+		transfer:    tc,
+		catStore:    stores.ConsumerCatalog,
+		cnStore:     stores.ContractNegotiationStore,
+		agrStore:    stores.AgreementStore,
+		tpStore:     stores.TransferStore,
+		fetchStore:  stores.FetchStore,
+		urn:         plugins.URNService,
+		log:         plugins.Log,
+	}
+}
+
+// NegotiateAndFetch selects the offer and validates the format synchronously so that
+// invalid requests are rejected before the remaining steps are run in the background
+func (f *Fetcher) NegotiateAndFetch(req consumer.FetchRequest) (fetchId string, err error) {
+	switch {
+	case req.ProviderEndpoint == ``:
+		return ``, errors.Client(errors.MissingAttrError(`providerEndpoint`, `required to fetch a dataset`))
+	case req.DatasetId == ``:
+		return ``, errors.Client(errors.MissingAttrError(`datasetId`, `required to fetch a dataset`))
+	case !transfer.DataTransferType(req.Format).Supported():
+		return ``, errors.Client(errors.UnsupportedFormat(req.Format, req.MediaType))
+	}
+
+	ds, err := f.catalog.RequestDataset(req.DatasetId, req.ProviderEndpoint)
+	if err != nil {
+		return ``, errors.CustomFuncError(`RequestDataset`, err)
+	}
+
+	if _, ok := ds.Distribution(req.Format, req.MediaType); !ok {
+		return ``, errors.Client(errors.UnsupportedFormat(req.Format, req.MediaType))
+	}
+
+	ofr, err := f.offer(ds.Dataset, req.OfferId, req.Constraints)
+	if err != nil {
+		return ``, errors.CustomFuncError(`offer`, err)
+	}
+
+	fetchId, err = f.urn.NewURN()
+	if err != nil {
+		return ``, errors.PkgError(pkg.TypeURN, `NewURN`, err, `fetch id`)
+	}
+
+	req.OfferId = ofr.Id
+	ft := consumer.Fetch{
+		ID:               fetchId,
+		State:            consumer.FetchNegotiating,
+		ProviderEndpoint: req.ProviderEndpoint,
+		DatasetId:        req.DatasetId,
+		OfferId:          ofr.Id,
+	}
+
+	if err = f.save(&ft); err != nil {
+		return ``, err
+	}
+
+	go f.run(ft, req)
+	return fetchId, nil
+}
+
+// Fetch returns the stored fetch along with the current states of its contract
+// negotiation and transfer process
+func (f *Fetcher) Fetch(id string) (consumer.Fetch, error) {
+	ft, err := f.fetchStore.Fetch(id)
+	if err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return consumer.Fetch{}, errors.Client(errors.InvalidKey(stores.TypeFetch, `fetch id`, err))
+		}
+		return consumer.Fetch{}, errors.StoreFailed(stores.TypeFetch, `Fetch`, err)
+	}
+
+	if ft.ConsumerPid != `` {
+		cn, err := f.cnStore.Negotiation(ft.ConsumerPid)
+		if err != nil {
+			return consumer.Fetch{}, errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
+		}
+		ft.NegotiationState = cn.State
+	}
+
+	if ft.TransferProcessId != `` {
+		tp, err := f.tpStore.Process(ft.TransferProcessId)
+		if err != nil {
+			return consumer.Fetch{}, errors.StoreFailed(stores.TypeTransfer, `Process`, err)
+		}
+
+		ft.TransferState = tp.State
+		switch tp.State {
+		case transfer.StateCompleted:
+			ft.State = consumer.FetchCompleted
+		case transfer.StateTerminated:
+			ft.State = consumer.FetchFailed
+			ft.Error = transferTerminated(ft.TransferProcessId).Error()
+		}
+	}
+
+	return ft, nil
+}
+
+// indexed checks if the offer is contained in the stored catalog of the provider
+// at the endpoint
+func (f *Fetcher) indexed(endpoint, offerId string) bool {
+	provider, err := f.catStore.Provider(endpoint)
+	if err != nil {
+		return false
+	}
+
+	_, err = f.catStore.Offer(provider, offerId)
+	return err == nil
+}
+
+// run blocks until the negotiation is finalized and the transfer is requested, or
+// any of the steps fails, and therefore, should be run in a separate goroutine
+func (f *Fetcher) run(ft consumer.Fetch, req consumer.FetchRequest) {
+	// offers are indexed by the stored catalogs, from which the offer of a contract
+	// request is retrieved and the format of a transfer request is validated
+	if !f.indexed(req.ProviderEndpoint, req.OfferId) {
+		if _, err := f.catalog.RequestCatalog(req.ProviderEndpoint, nil); err != nil {
+			f.fail(ft, errors.CustomFuncError(`RequestCatalog`, err))
+			return
+		}
+	}
+
+	consumerPid, err := f.negotiation.RequestContract(``, req.ProviderEndpoint, req.OfferId, req.Constraints)
+	if err != nil {
+		f.fail(ft, errors.CustomFuncError(`RequestContract`, err))
+		return
+	}
+
+	ft.ConsumerPid = consumerPid
+	if err = f.save(&ft); err != nil {
+		f.log.Error(err)
+	}
+
+	if err = f.negotiate(consumerPid); err != nil {
+		f.fail(ft, errors.CustomFuncError(`negotiate`, err))
+		return
+	}
+
+	agr, err := f.agrStore.AgreementByNegotiationID(consumerPid)
+	if err != nil {
+		f.fail(ft, errors.StoreFailed(stores.TypeAgreement, `AgreementByNegotiationID`, err))
+		return
+	}
+
+	ft.AgreementId = agr.Id
+	tpId, err := f.transfer.RequestTransfer(req.Format, req.MediaType, agr.Id, req.SinkEndpoint,
+		req.ProviderEndpoint)
+	if err != nil {
+		f.fail(ft, errors.CustomFuncError(`RequestTransfer`, err))
+		return
+	}
+
+	ft.State = consumer.FetchTransferring
+	ft.TransferProcessId = tpId
+	if err = f.save(&ft); err != nil {
+		f.log.Error(err)
+	}
+
+	f.log.Info(fmt.Sprintf("consumer requested the transfer of the fetched dataset (fetch: %s, agreement: %s, "+
+		"transfer process: %s)", ft.ID, agr.Id, tpId))
+}
+
+// negotiate verifies the agreement once the provider agrees and returns when the
+// provider finalizes the negotiation. Offers of the provider are left to be accepted
+// manually since the terms are changed by the provider.
+func (f *Fetcher) negotiate(consumerPid string) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	timeout := time.After(negotiationTimeout)
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			return negotiationTimedOut(consumerPid)
+		}
+
+		cn, err := f.cnStore.Negotiation(consumerPid)
+		if err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
+		}
+
+		switch cn.State {
+		case negotiation.StateAgreed:
+			if err = f.negotiation.VerifyAgreement(consumerPid); err != nil {
+				return errors.CustomFuncError(`VerifyAgreement`, err)
+			}
+		case negotiation.StateFinalized:
+			return nil
+		case negotiation.StateTerminated:
+			return negotiationTerminated(consumerPid)
+		}
+	}
+}
+
+// offer returns the offer of the dataset with the given ID, or the first offer of which
+// the constraints can be proposed with the given values if the ID is not provided
+func (f *Fetcher) offer(ds dcat.Dataset, offerId string, vals map[string]string) (odrl.Offer, error) {
+	for _, ofr := range ds.OdrlHasPolicy {
+		if offerId != `` {
+			if ofr.Id == offerId {
+				return ofr, nil
+			}
+			continue
+		}
+
+		if covered(ofr, vals) {
+			return ofr, nil
+		}
+	}
+
+	if offerId != `` {
+		return odrl.Offer{}, errors.Client(errors.IncorrectReqValues(fmt.Sprintf(
+			"dataset does not contain the offer (dataset: %s, offer: %s)", ds.ID, offerId)))
+	}
+	return odrl.Offer{}, errors.Client(errors.IncorrectReqValues(fmt.Sprintf(
+		"dataset does not contain an offer of which all constraints are provided (dataset: %s)", ds.ID)))
+}
+
+func (f *Fetcher) fail(ft consumer.Fetch, err error) {
+	f.log.Error(err)
+	ft.State = consumer.FetchFailed
+	ft.Error = err.Error()
+	if err = f.save(&ft); err != nil {
+		f.log.Error(err)
+	}
+}
+
+func (f *Fetcher) save(ft *consumer.Fetch) error {
+	ft.UpdatedAt = time.Now().UTC()
+	if err := f.fetchStore.SetFetch(ft.ID, *ft); err != nil {
+		return errors.StoreFailed(stores.TypeFetch, `SetFetch`, err)
+	}
+	return nil
+}
+
+// covered returns true if a value is provided for each constraint of the permissions
+// which is proposed by the consumer in a contract request
+func covered(ofr odrl.Offer, vals map[string]string) bool {
+	for _, perm := range ofr.Permissions {
+		for _, cons := range perm.Constraints {
+			// logical constraints and referred right operands are retained as published
+			if cons.LeftOperand == `` || cons.RightOperandReference != `` {
+				continue
+			}
+
+			if _, ok := vals[cons.LeftOperand]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
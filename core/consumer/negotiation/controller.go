@@ -19,10 +19,12 @@ import (
 type Controller struct {
 	callbackAddr string
 	assigneeId   string
+	db           pkg.Database
 	catalog      stores.ConsumerCatalog
 	cnStore      stores.ContractNegotiationStore
 	urn          pkg.URNService
 	client       pkg.Client
+	locks        *locks
 	log          pkg.Log
 }
 
@@ -30,10 +32,12 @@ func NewController(cfg boot.Config, stores domain.Stores, plugins domain.Plugins
 	return &Controller{
 		callbackAddr: cfg.Servers.IP + `:` + strconv.Itoa(cfg.Servers.DSP.HTTP.Port),
 		assigneeId:   cfg.DataSpace.AssigneeId,
+		db:           plugins.Database,
 		catalog:      stores.ConsumerCatalog,
 		cnStore:      stores.ContractNegotiationStore,
 		urn:          plugins.URNService,
 		client:       plugins.Client,
+		locks:        newLocks(),
 		log:          plugins.Log,
 	}
 }
@@ -43,6 +47,7 @@ func (c *Controller) RequestContract(consumerPid, providerAddr, offerId string,
 
 	var providerPid, endpoint string
 	if consumerPid != `` {
+		defer c.locks.acquire(consumerPid)()
 		cn, err := c.cnStore.Negotiation(consumerPid)
 		if err != nil {
 			if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -52,7 +57,7 @@ func (c *Controller) RequestContract(consumerPid, providerAddr, offerId string,
 			return ``, errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
 		}
 
-		if cn.State != negotiation.StateOffered {
+		if !negotiation.ValidTransition(cn.State, negotiation.StateRequested) {
 			return ``, errors.Client(errors.StateError(`request contract`, string(cn.State)))
 		}
 
@@ -74,16 +79,39 @@ func (c *Controller) RequestContract(consumerPid, providerAddr, offerId string,
 		if err != nil {
 			return ``, errors.PkgError(pkg.TypeURN, `NewURN`, err, `contract negotiation id`)
 		}
+		defer c.locks.acquire(consumerPid)()
 		endpoint = negotiation.ContractRequestEndpoint
 	}
 
-	// fetch offer from store
-	ofr, err := c.setConstraints(offerId, constraints)
+	// a new negotiation is bound to the provider of the catalog received from the address,
+	// whereas an existing negotiation is already bound to its provider
+	var provider string
+	if providerPid == `` {
+		provider, err = c.catalog.Provider(providerAddr)
+		if err != nil {
+			if defaultErr.Is(err, stores.TypeInvalidKey) {
+				return ``, errors.Client(errors.InvalidKey(stores.TypeConsumerCatalog, `provider address`, err))
+			}
+			return ``, errors.StoreFailed(stores.TypeConsumerCatalog, `Provider`, err)
+		}
+	} else {
+		provider, err = c.cnStore.Counterparty(consumerPid)
+		if err != nil {
+			return ``, errors.StoreFailed(stores.TypeContractNegotiation, `Counterparty`, err)
+		}
+	}
+
+	// fetch offer from the catalog of the provider
+	published, err := c.catalog.Offer(provider, offerId)
+	if err != nil {
+		return ``, errors.Client(errors.InvalidKey(stores.TypeConsumerCatalog, `offer id`, err))
+	}
+
+	ofr, err := c.setConstraints(published, constraints)
 	if err != nil {
 		return ``, errors.CustomFuncError(`setConstraints`, err)
 	}
 
-	c.cnStore.SetParticipants(consumerPid, providerAddr, ofr.Assigner, ofr.Assignee)
 	req := negotiation.ContractRequest{
 		Ctx:          core.Context,
 		Type:         negotiation.MsgTypeContractRequest,
@@ -93,17 +121,45 @@ func (c *Controller) RequestContract(consumerPid, providerAddr, offerId string,
 		CallbackAddr: c.callbackAddr,
 	}
 
-	ack, err := c.send(consumerPid, endpoint, req)
+	ack, err := c.sendTo(providerAddr, endpoint, req)
 	if err != nil {
-		return ``, errors.CustomFuncError(`send`, err)
+		return ``, errors.CustomFuncError(`sendTo`, err)
 	}
 
 	if errMsg := c.validAck(consumerPid, ack, negotiation.StateRequested); errMsg != `` {
 		return ``, errors.Client(errors.InvalidAckError(`ContractRequest`, errMsg, ack))
 	}
 
+	// participants, counterparty and the requested offer are stored along with the negotiation
+	// so that they do not exist without the negotiation if the request fails. Requested offer
+	// is recorded to validate the agreement sent by the provider.
 	ack.Type = negotiation.MsgTypeNegotiation
-	c.cnStore.AddNegotiation(consumerPid, negotiation.Negotiation(ack))
+	if err = c.db.Transaction(func(tx pkg.Transaction) error {
+		cnStore := c.cnStore.WithTx(tx)
+		if err := cnStore.SetNegotiation(consumerPid, negotiation.Negotiation(ack), providerAddr,
+			ofr.Assigner, ofr.Assignee); err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `SetNegotiation`, err)
+		}
+
+		if err := cnStore.AddRound(consumerPid, negotiation.Round{
+			Proposer: negotiation.ProposerConsumer,
+			Offer:    ofr,
+			Changes:  negotiation.Changes(ofr, published),
+		}); err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `AddRound`, err)
+		}
+
+		if providerPid != `` {
+			return nil
+		}
+
+		if err := cnStore.SetCounterparty(consumerPid, provider); err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `SetCounterparty`, err)
+		}
+		return nil
+	}); err != nil {
+		return ``, err
+	}
 
 	c.log.Trace(fmt.Sprintf("consumer stored contract negotiation (id: %s, assigner: %s, "+
 		"assignee: %s, address: %s)", consumerPid, ofr.Assigner, ofr.Assignee, providerAddr))
@@ -113,6 +169,7 @@ func (c *Controller) RequestContract(consumerPid, providerAddr, offerId string,
 }
 
 func (c *Controller) AcceptOffer(consumerPid string) error {
+	defer c.locks.acquire(consumerPid)()
 	cn, err := c.cnStore.Negotiation(consumerPid)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -122,7 +179,7 @@ func (c *Controller) AcceptOffer(consumerPid string) error {
 		return errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
 	}
 
-	if cn.State != negotiation.StateOffered {
+	if !negotiation.ValidTransition(cn.State, negotiation.StateAccepted) {
 		return errors.Client(errors.StateError(`accept offer`, string(cn.State)))
 	}
 
@@ -153,6 +210,7 @@ func (c *Controller) AcceptOffer(consumerPid string) error {
 }
 
 func (c *Controller) VerifyAgreement(consumerPid string) error {
+	defer c.locks.acquire(consumerPid)()
 	cn, err := c.cnStore.Negotiation(consumerPid)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -162,7 +220,7 @@ func (c *Controller) VerifyAgreement(consumerPid string) error {
 		return errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
 	}
 
-	if cn.State != negotiation.StateAgreed {
+	if !negotiation.ValidTransition(cn.State, negotiation.StateVerified) {
 		return errors.Client(errors.StateError(`verify agreement`, string(cn.State)))
 	}
 
@@ -192,6 +250,7 @@ func (c *Controller) VerifyAgreement(consumerPid string) error {
 }
 
 func (c *Controller) TerminateContract(consumerPid, code string, reasons []string) error {
+	defer c.locks.acquire(consumerPid)()
 	cn, err := c.cnStore.Negotiation(consumerPid)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -201,6 +260,10 @@ func (c *Controller) TerminateContract(consumerPid, code string, reasons []strin
 		return errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
 	}
 
+	if !negotiation.ValidTransition(cn.State, negotiation.StateTerminated) {
+		return errors.Client(errors.StateError(`terminate contract`, string(cn.State)))
+	}
+
 	var rsnList []negotiation.Reason
 	for _, r := range reasons {
 		rsnList = append(rsnList, negotiation.Reason{
@@ -242,6 +305,12 @@ func (c *Controller) send(consumerPid, endpoint string, req any) (negotiation.Ac
 		return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `CallBackAddr`, err)
 	}
 
+	return c.sendTo(providerAddr, endpoint, req)
+}
+
+// sendTo sends the request to the given address of the provider, which is used when the
+// address is not stored yet
+func (c *Controller) sendTo(providerAddr, endpoint string, req any) (negotiation.Ack, error) {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return negotiation.Ack{}, errors.Client(errors.MarshalError(``, err))
@@ -260,29 +329,28 @@ func (c *Controller) send(consumerPid, endpoint string, req any) (negotiation.Ac
 	return ack, nil
 }
 
-func (c *Controller) setConstraints(offerId string, vals map[string]string) (odrl.Offer, error) {
+func (c *Controller) setConstraints(ofr odrl.Offer, vals map[string]string) (odrl.Offer, error) {
 	var permList []odrl.Rule
-	ofr, err := c.catalog.Offer(offerId)
-	if err != nil {
-		return odrl.Offer{}, errors.Client(errors.InvalidKey(stores.TypeTransfer, `offer id`, err))
-	}
-
 	for _, perm := range ofr.Permissions {
 		var consList []odrl.Constraint
 		for _, cons := range perm.Constraints {
+			// logical constraints and referred right operands are retained as published
+			if cons.LeftOperand == `` || cons.RightOperandReference != `` {
+				consList = append(consList, cons)
+				continue
+			}
+
 			val, ok := vals[cons.LeftOperand]
 			if !ok {
 				return odrl.Offer{}, errors.Client(errors.MissingAttrError(cons.LeftOperand,
 					`mandatory constraint`))
 			}
-			cons.RightOperand = val
+			cons.RightOperand = odrl.TypedLiteral(val, cons.RightOperand.Type)
 			consList = append(consList, cons)
 		}
 
-		permList = append(permList, odrl.Rule{
-			Action:      perm.Action,
-			Constraints: consList,
-		})
+		perm.Constraints = consList // duties of the permission are retained
+		permList = append(permList, perm)
 	}
 
 	ofr.Assignee = odrl.Assignee(c.assigneeId)
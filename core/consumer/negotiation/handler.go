@@ -7,29 +7,38 @@ import (
 	"github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
 	"github.com/YasiruR/connector/domain/core"
 	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/models/odrl"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
 )
 
 type Handler struct {
-	cnStore  stores.ContractNegotiationStore
-	agrStore stores.AgreementStore
-	urn      pkg.URNService
-	log      pkg.Log
+	assigneeId string
+	db         pkg.Database
+	cnStore    stores.ContractNegotiationStore
+	agrStore   stores.AgreementStore
+	urn        pkg.URNService
+	locks      *locks
+	log        pkg.Log
 }
 
-func NewHandler(stores domain.Stores, plugins domain.Plugins) *Handler {
+func NewHandler(stores domain.Stores, plugins domain.Plugins, c *Controller) *Handler {
 	return &Handler{
-		cnStore:  stores.ContractNegotiationStore,
-		agrStore: stores.AgreementStore,
-		urn:      plugins.URNService,
-		log:      plugins.Log,
+		assigneeId: c.assigneeId,
+		db:         plugins.Database,
+		cnStore:    stores.ContractNegotiationStore,
+		agrStore:   stores.AgreementStore,
+		urn:        plugins.URNService,
+		locks:      c.locks,
+		log:        plugins.Log,
 	}
 }
 
-func (h *Handler) HandleContractOffer(co negotiation.ContractOffer) (ack negotiation.Ack, err error) {
+func (h *Handler) HandleContractOffer(co negotiation.ContractOffer,
+	participantId string) (ack negotiation.Ack, err error) {
 	var cn negotiation.Negotiation
 	if co.ConsPId != `` {
+		defer h.locks.acquire(co.ConsPId)()
 		// validate the given consumerPid
 		cn, err = h.cnStore.Negotiation(co.ConsPId)
 		if err != nil {
@@ -45,9 +54,8 @@ func (h *Handler) HandleContractOffer(co negotiation.ContractOffer) (ack negotia
 				errors.InvalidValue(`providerPid`, cn.ProvPId, co.ProvPId))
 		}
 
-		if cn.State != negotiation.StateRequested {
-			return negotiation.Ack{}, errors.Negotiation(co.ProvPId, co.ConsPId,
-				errors.StateError(`offer contract`, string(cn.State)))
+		if err = h.validateCounterparty(cn, participantId); err != nil {
+			return negotiation.Ack{}, err
 		}
 
 		h.log.Trace("a contract negotiation already exists for the contract offer", "id: "+co.ConsPId)
@@ -62,10 +70,39 @@ func (h *Handler) HandleContractOffer(co negotiation.ContractOffer) (ack negotia
 		cn.Type = negotiation.MsgTypeNegotiation
 	}
 
+	curState := cn.State
 	cn.ProvPId = co.ProvPId
 	cn.State = negotiation.StateOffered
-	h.cnStore.AddNegotiation(cn.ConsPId, cn)
-	h.cnStore.SetParticipants(cn.ConsPId, co.CallbackAddr, co.Offer.Assigner, co.Offer.Assignee)
+
+	// received offer is recorded along with the negotiation so that the agreement can be
+	// validated against it if the consumer does not request another offer
+	if err = h.db.Transaction(func(tx pkg.Transaction) error {
+		cnStore := h.cnStore.WithTx(tx)
+		if err := cnStore.SetNegotiation(cn.ConsPId, cn, co.CallbackAddr, co.Offer.Assigner,
+			co.Offer.Assignee); err != nil {
+			switch {
+			case defaultErr.Is(err, stores.TypeInvalidTransition):
+				return errors.Negotiation(co.ProvPId, co.ConsPId, errors.StateError(`offer contract`,
+					string(curState)))
+			case defaultErr.Is(err, stores.TypeConflict):
+				return errors.Negotiation(co.ProvPId, co.ConsPId, errors.Conflict(`offer contract`, err))
+			default:
+				return errors.StoreFailed(stores.TypeContractNegotiation, `SetNegotiation`, err)
+			}
+		}
+
+		if err := cnStore.SetCounterparty(cn.ConsPId, participantId); err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `SetCounterparty`, err)
+		}
+
+		if err := cnStore.AddRound(cn.ConsPId, negotiation.Round{Proposer: negotiation.ProposerProvider,
+			Offer: co.Offer}); err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `AddRound`, err)
+		}
+		return nil
+	}); err != nil {
+		return negotiation.Ack{}, err
+	}
 
 	h.log.Trace(fmt.Sprintf("consumer updated callback address for contract negotiation (id: %s, address: %s)",
 		cn.ConsPId, co.CallbackAddr))
@@ -75,9 +112,9 @@ func (h *Handler) HandleContractOffer(co negotiation.ContractOffer) (ack negotia
 	return negotiation.Ack(cn), nil
 }
 
-func (h *Handler) HandleContractAgreement(ca negotiation.ContractAgreement) (negotiation.Ack, error) {
-	// validate agreement (e.g. consumerPid, providerPid, target)
-
+func (h *Handler) HandleContractAgreement(ca negotiation.ContractAgreement,
+	participantId string) (negotiation.Ack, error) {
+	defer h.locks.acquire(ca.ConsPId)()
 	cn, err := h.cnStore.Negotiation(ca.ConsPId)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -87,30 +124,50 @@ func (h *Handler) HandleContractAgreement(ca negotiation.ContractAgreement) (neg
 		return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
 	}
 
-	if cn.State != negotiation.StateRequested && cn.State != negotiation.StateAccepted {
-		return negotiation.Ack{}, errors.Negotiation(ca.ProvPId, ca.ConsPId,
-			errors.StateError(`agree contract`, string(cn.State)))
+	if err = h.validateCounterparty(cn, participantId); err != nil {
+		return negotiation.Ack{}, err
 	}
 
-	h.agrStore.AddAgreement(ca.Agreement.Id, ca.Agreement)
-	h.log.Trace(fmt.Sprintf("consumer stored contract agreement (id: %s) for negotation (id: %s)",
-		ca.Agreement.Id, ca.ConsPId))
+	if err = h.validateAgreement(cn, ca); err != nil {
+		return negotiation.Ack{}, err
+	}
 
-	if err := h.cnStore.UpdateState(ca.ConsPId, negotiation.StateAgreed); err != nil {
-		return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `UpdateState`, err)
+	// agreement is stored along with the state so that agreements are not stored for
+	// negotiations in an incompatible state
+	if err = h.db.Transaction(func(tx pkg.Transaction) error {
+		if err := h.cnStore.WithTx(tx).UpdateState(ca.ConsPId, negotiation.StateAgreed); err != nil {
+			switch {
+			case defaultErr.Is(err, stores.TypeInvalidTransition):
+				return errors.Negotiation(ca.ProvPId, ca.ConsPId, errors.StateError(`agree contract`,
+					string(cn.State)))
+			case defaultErr.Is(err, stores.TypeConflict):
+				return errors.Negotiation(ca.ProvPId, ca.ConsPId, errors.Conflict(`agree contract`, err))
+			default:
+				return errors.StoreFailed(stores.TypeContractNegotiation, `UpdateState`, err)
+			}
+		}
+
+		if err := h.agrStore.WithTx(tx).AddAgreement(ca.ConsPId, ca.Agreement); err != nil {
+			return errors.StoreFailed(stores.TypeAgreement, `AddAgreement`, err)
+		}
+		return nil
+	}); err != nil {
+		return negotiation.Ack{}, err
 	}
 
+	h.log.Trace(fmt.Sprintf("consumer stored contract agreement (id: %s) for negotation (id: %s)",
+		ca.Agreement.Id, ca.ConsPId))
+
+	cn.State = negotiation.StateAgreed
 	cn.Type = negotiation.MsgTypeNegotiationAck
 	h.log.Debug(fmt.Sprintf("consumer handler updated negotiation state (id: %s, state: %s)",
 		ca.ConsPId, negotiation.StateAgreed))
 	return negotiation.Ack(cn), nil
 }
 
-func (h *Handler) HandleFinalizedEvent(e negotiation.ContractNegotiationEvent) (negotiation.Ack, error) {
-	if err := h.cnStore.UpdateState(e.ConsPId, negotiation.StateFinalized); err != nil {
-		return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `UpdateState`, err)
-	}
-
+func (h *Handler) HandleFinalizedEvent(e negotiation.ContractNegotiationEvent,
+	participantId string) (negotiation.Ack, error) {
+	defer h.locks.acquire(e.ConsPId)()
 	cn, err := h.cnStore.Negotiation(e.ConsPId)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -120,23 +177,82 @@ func (h *Handler) HandleFinalizedEvent(e negotiation.ContractNegotiationEvent) (
 		return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
 	}
 
+	if err = h.validateCounterparty(cn, participantId); err != nil {
+		return negotiation.Ack{}, err
+	}
+
+	if err = h.cnStore.UpdateState(e.ConsPId, negotiation.StateFinalized); err != nil {
+		switch {
+		case defaultErr.Is(err, stores.TypeInvalidTransition):
+			return negotiation.Ack{}, errors.Negotiation(e.ProvPId, e.ConsPId,
+				errors.StateError(`finalize contract`, string(cn.State)))
+		case defaultErr.Is(err, stores.TypeConflict):
+			return negotiation.Ack{}, errors.Negotiation(e.ProvPId, e.ConsPId,
+				errors.Conflict(`finalize contract`, err))
+		default:
+			return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `UpdateState`, err)
+		}
+	}
+
+	cn.State = negotiation.StateFinalized
+
 	h.log.Info(fmt.Sprintf("consumer handler updated negotiation state (id: %s, state: %s)",
 		e.ConsPId, negotiation.StateFinalized))
 	return negotiation.Ack(cn), nil
 }
 
-func (h *Handler) validAgreement(agr negotiation.ContractAgreement) bool {
-	cn, err := h.cnStore.Negotiation(agr.ConsPId)
+// validateCounterparty rejects a message of the negotiation unless it is sent by the
+// provider of the offer which the consumer requested, or which offered the contract
+func (h *Handler) validateCounterparty(cn negotiation.Negotiation, participantId string) error {
+	counterparty, err := h.cnStore.Counterparty(cn.ConsPId)
+	if err != nil && !defaultErr.Is(err, stores.TypeInvalidKey) {
+		return errors.StoreFailed(stores.TypeContractNegotiation, `Counterparty`, err)
+	}
+
+	if counterparty == `` || counterparty != participantId {
+		return errors.Negotiation(cn.ProvPId, cn.ConsPId, errors.InvalidValue(`participant`, counterparty,
+			participantId))
+	}
+	return nil
+}
+
+// validateAgreement rejects an agreement which does not belong to the negotiation, is not
+// assigned by the assigner of the negotiated offer to the consumer or does not refer to the
+// target of the latest offer of the negotiation which has a target
+func (h *Handler) validateAgreement(cn negotiation.Negotiation, ca negotiation.ContractAgreement) error {
+	if ca.ProvPId != cn.ProvPId {
+		return errors.Negotiation(cn.ProvPId, cn.ConsPId, errors.InvalidValue(`providerPid`, cn.ProvPId,
+			ca.ProvPId))
+	}
+
+	assigner, err := h.cnStore.Assigner(cn.ConsPId)
 	if err != nil {
-		// todo handle invalid key error
-		return false
+		return errors.StoreFailed(stores.TypeContractNegotiation, `Assigner`, err)
+	}
+
+	if ca.Agreement.Assigner != assigner {
+		return errors.Negotiation(cn.ProvPId, cn.ConsPId, errors.InvalidValue(`assigner`, string(assigner),
+			string(ca.Agreement.Assigner)))
 	}
 
-	if agr.ProvPId != cn.ProvPId {
-		return false
+	if string(ca.Agreement.Assignee) != h.assigneeId {
+		return errors.Negotiation(cn.ProvPId, cn.ConsPId, errors.InvalidValue(`assignee`, h.assigneeId,
+			string(ca.Agreement.Assignee)))
 	}
 
-	// todo save catalog first, then set assigner, and this
-	//if agr.Agreement.Assigner !=
-	return true
+	rounds, err := h.cnStore.Rounds(cn.ConsPId)
+	if err != nil && !defaultErr.Is(err, stores.TypeInvalidKey) {
+		return errors.StoreFailed(stores.TypeContractNegotiation, `Rounds`, err)
+	}
+
+	var target odrl.Target
+	for i := len(rounds) - 1; i >= 0 && target == ``; i-- {
+		target = rounds[i].Offer.Target
+	}
+
+	if target == `` || ca.Agreement.Target != target {
+		return errors.Negotiation(cn.ProvPId, cn.ConsPId, errors.InvalidValue(`target`, string(target),
+			string(ca.Agreement.Target)))
+	}
+	return nil
 }
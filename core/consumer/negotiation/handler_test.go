@@ -0,0 +1,74 @@
+package negotiation
+
+import (
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"github.com/YasiruR/connector/pkg/database/memory"
+	"github.com/YasiruR/connector/pkg/log"
+	"github.com/YasiruR/connector/stores/policy"
+	"github.com/YasiruR/connector/stores/protocol"
+	"testing"
+)
+
+func TestHandler_HandleContractAgreement(t *testing.T) {
+	l := log.NewLogger()
+	plugins := domain.Plugins{Database: memory.NewStore(l), Log: l}
+	s := domain.Stores{
+		ContractNegotiationStore: protocol.NewContractNegotiationStore(plugins),
+		AgreementStore:           policy.NewAgreementStore(plugins),
+	}
+	h := NewHandler(s, plugins, &Controller{assigneeId: `consumer`, locks: newLocks()})
+
+	// negotiation of the offer requested by the consumer from the provider
+	requested := odrl.Offer{Id: `offer`, Target: `dataset`, Assigner: `provider`, Assignee: `consumer`,
+		Permissions: []odrl.Rule{{Action: `odrl:use`}}}
+	cn := negotiation.Negotiation{ProvPId: `provider-pid`, ConsPId: `consumer-pid`, State: negotiation.StateRequested}
+	if err := s.ContractNegotiationStore.SetNegotiation(cn.ConsPId, cn, `http://localhost:9080`,
+		requested.Assigner, requested.Assignee); err != nil {
+		t.Fatalf("SetNegotiation failed - %s", err)
+	}
+
+	if err := s.ContractNegotiationStore.SetCounterparty(cn.ConsPId, `provider`); err != nil {
+		t.Fatalf("SetCounterparty failed - %s", err)
+	}
+
+	if err := s.ContractNegotiationStore.AddRound(cn.ConsPId, negotiation.Round{
+		Proposer: negotiation.ProposerConsumer, Offer: requested}); err != nil {
+		t.Fatalf("AddRound failed - %s", err)
+	}
+
+	agreement := func(update func(ca *negotiation.ContractAgreement)) negotiation.ContractAgreement {
+		ca := negotiation.ContractAgreement{ProvPId: cn.ProvPId, ConsPId: cn.ConsPId, Agreement: odrl.Agreement{
+			Id: `agreement`, Target: requested.Target, Assigner: requested.Assigner, Assignee: requested.Assignee,
+			Permissions: requested.Permissions}}
+		update(&ca)
+		return ca
+	}
+
+	invalid := map[string]negotiation.ContractAgreement{
+		`providerPid`: agreement(func(ca *negotiation.ContractAgreement) { ca.ProvPId = `other-pid` }),
+		`target`:      agreement(func(ca *negotiation.ContractAgreement) { ca.Agreement.Target = `other` }),
+		`assigner`:    agreement(func(ca *negotiation.ContractAgreement) { ca.Agreement.Assigner = `other` }),
+		`assignee`:    agreement(func(ca *negotiation.ContractAgreement) { ca.Agreement.Assignee = `other` }),
+	}
+
+	for name, ca := range invalid {
+		if _, err := h.HandleContractAgreement(ca, `provider`); err == nil {
+			t.Errorf("HandleContractAgreement accepted an agreement with another %s", name)
+		}
+	}
+
+	if _, err := s.AgreementStore.AgreementByNegotiationID(cn.ConsPId); err == nil {
+		t.Errorf("a rejected agreement was stored")
+	}
+
+	if state, err := s.ContractNegotiationStore.State(cn.ConsPId); err != nil || state != negotiation.StateRequested {
+		t.Errorf("state of the negotiation was updated by rejected agreements (state: %s, error: %v)", state, err)
+	}
+
+	if _, err := h.HandleContractAgreement(agreement(func(*negotiation.ContractAgreement) {}),
+		`provider`); err != nil {
+		t.Errorf("HandleContractAgreement rejected the agreement of the requested offer - %s", err)
+	}
+}
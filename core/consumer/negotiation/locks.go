@@ -0,0 +1,43 @@
+package negotiation
+
+import "sync"
+
+// locks serializes the messages sent by the consumer for a contract negotiation with the
+// messages received for it. Since the consumer stores the state of a negotiation only once
+// the provider acknowledges a message, a response which the provider sends before the
+// acknowledgement (e.g. an automated agreement) is handled after the state is stored.
+type locks struct {
+	mu          sync.Mutex
+	negotiation map[string]*lock
+}
+
+type lock struct {
+	sync.Mutex
+	waiting int // number of goroutines holding or waiting for the lock
+}
+
+func newLocks() *locks {
+	return &locks{negotiation: make(map[string]*lock)}
+}
+
+// acquire blocks until the negotiation is locked and returns the function which releases it
+func (l *locks) acquire(consumerPid string) (release func()) {
+	l.mu.Lock()
+	nl, ok := l.negotiation[consumerPid]
+	if !ok {
+		nl = &lock{}
+		l.negotiation[consumerPid] = nl
+	}
+	nl.waiting++
+	l.mu.Unlock()
+
+	nl.Lock()
+	return func() {
+		nl.Unlock()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if nl.waiting--; nl.waiting == 0 {
+			delete(l.negotiation, consumerPid)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
 	"github.com/YasiruR/connector/domain/boot"
 	"github.com/YasiruR/connector/domain/core"
+	"github.com/YasiruR/connector/domain/core/dataplane"
 	"github.com/YasiruR/connector/domain/errors"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
@@ -19,13 +20,22 @@ type Controller struct {
 	callbackAddr string
 	urn          pkg.URNService
 	client       pkg.Client
+	dataPlane    dataplane.Consumer
+	catalog      stores.ConsumerCatalog
+	agrStore     stores.AgreementStore
+	cnStore      stores.ContractNegotiationStore
 	tpStore      stores.TransferStore
 	log          pkg.Log
 }
 
-func NewController(cfg boot.Config, stores domain.Stores, plugins domain.Plugins) *Controller {
+func NewController(cfg boot.Config, stores domain.Stores, plugins domain.Plugins,
+	dp dataplane.Consumer) *Controller {
 	return &Controller{
 		callbackAddr: cfg.Servers.IP + `:` + strconv.Itoa(cfg.Servers.DSP.HTTP.Port),
+		dataPlane:    dp,
+		catalog:      stores.ConsumerCatalog,
+		agrStore:     stores.AgreementStore,
+		cnStore:      stores.ContractNegotiationStore,
 		tpStore:      stores.TransferStore,
 		client:       plugins.Client,
 		urn:          plugins.URNService,
@@ -50,10 +60,14 @@ func (c *Controller) GetProviderProcess(tpId string) (transfer.Process, error) {
 	return transfer.Process(ack), nil
 }
 
-func (c *Controller) RequestTransfer(dataFormat, agreementId, sinkEndpoint,
+// RequestTransfer requests the data of the agreement in the given format, where the media
+// type optionally selects among the distributions of the same format
+func (c *Controller) RequestTransfer(dataFormat, mediaType, agreementId, sinkEndpoint,
 	providerEndpoint string) (tpId string, err error) {
-	// include validations for format
 	typ := transfer.DataTransferType(dataFormat)
+	if err = c.validateFormat(typ, mediaType, agreementId); err != nil {
+		return ``, err
+	}
 
 	tpId, err = c.urn.NewURN()
 	if err != nil {
@@ -66,30 +80,40 @@ func (c *Controller) RequestTransfer(dataFormat, agreementId, sinkEndpoint,
 		ConsPId:      tpId,
 		AgreementId:  agreementId,
 		Format:       typ,
+		MediaType:    mediaType,
 		CallbackAddr: c.callbackAddr,
 	}
 
 	if typ == transfer.HTTPPush {
+		// data is pushed to the sink of the data plane unless an external sink is provided
 		if sinkEndpoint == `` {
-			return ``, errors.Client(errors.MissingAttrError(`sink endpoint`,
-				`mandatory for push transfers`))
-		}
-
-		req.Address = transfer.Address{
-			Type:               transfer.MsgTypeDataAddress,
-			EndpointType:       transfer.EndpointTypeHTTP,
-			Endpoint:           sinkEndpoint,
-			EndpointProperties: nil, // e.g. auth tokens
+			if req.Address, err = c.dataPlane.SinkAddress(tpId); err != nil {
+				return ``, errors.CustomFuncError(`SinkAddress`, err)
+			}
+		} else {
+			req.Address = transfer.Address{
+				Type:               transfer.MsgTypeDataAddress,
+				EndpointType:       transfer.EndpointTypeHTTP,
+				Endpoint:           sinkEndpoint,
+				EndpointProperties: nil, // e.g. auth tokens
+			}
 		}
 	}
 
 	c.tpStore.SetCallbackAddr(tpId, providerEndpoint)
+	if err = c.setCounterparty(tpId, agreementId); err != nil {
+		return ``, err
+	}
+
 	ack, err := c.send(tpId, transfer.RequestEndpoint, req)
 	if err != nil {
 		return ``, errors.CustomFuncError(`send`, err)
 	}
 
-	c.tpStore.AddProcess(tpId, transfer.Process(ack))
+	if err = c.tpStore.AddProcess(tpId, transfer.Process(ack),
+		transfer.Cause{Initiator: core.RoleConsumer}); err != nil {
+		return ``, errors.StoreFailed(stores.TypeTransfer, `AddProcess`, err)
+	}
 	c.log.Trace("stored transfer process", ack)
 	c.log.Debug(fmt.Sprintf("consumer controller updated transfer process state (id: %s, state: %s)",
 		tpId, transfer.StateRequested))
@@ -107,7 +131,7 @@ func (c *Controller) SuspendTransfer(tpId, code string, reasons []interface{}) e
 
 	// validate tp
 
-	if tp.State != transfer.StateStarted {
+	if !transfer.ValidTransition(tp.State, transfer.StateSuspended) {
 		return errors.Client(errors.StateError(`suspend transfer`, string(tp.State)))
 	}
 
@@ -124,7 +148,8 @@ func (c *Controller) SuspendTransfer(tpId, code string, reasons []interface{}) e
 		return errors.CustomFuncError(`send`, err)
 	}
 
-	if err = c.tpStore.UpdateState(tpId, transfer.StateSuspended); err != nil {
+	if err = c.tpStore.UpdateState(tpId, transfer.StateSuspended,
+		transfer.Cause{Initiator: core.RoleConsumer, Code: code, Reasons: reasons}); err != nil {
 		return errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
 	}
 
@@ -144,6 +169,8 @@ func (c *Controller) StartTransfer(tpId string) error {
 
 	// validate tp
 
+	// consumer can only restart a suspended transfer process while the initial start
+	// is always sent by the provider
 	if tp.State != transfer.StateSuspended {
 		return errors.Client(errors.StateError(`start transfer`, string(tp.State)))
 	}
@@ -160,7 +187,8 @@ func (c *Controller) StartTransfer(tpId string) error {
 		return errors.CustomFuncError(`send`, err)
 	}
 
-	if err = c.tpStore.UpdateState(tpId, transfer.StateStarted); err != nil {
+	if err = c.tpStore.UpdateState(tpId, transfer.StateStarted,
+		transfer.Cause{Initiator: core.RoleConsumer}); err != nil {
 		return errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
 	}
 
@@ -180,7 +208,7 @@ func (c *Controller) CompleteTransfer(tpId string) error {
 
 	// validate tp
 
-	if tp.State != transfer.StateStarted {
+	if !transfer.ValidTransition(tp.State, transfer.StateCompleted) {
 		return errors.Client(errors.StateError(`complete transfer`, string(tp.State)))
 	}
 
@@ -195,7 +223,8 @@ func (c *Controller) CompleteTransfer(tpId string) error {
 		return errors.CustomFuncError(`send`, err)
 	}
 
-	if err = c.tpStore.UpdateState(tpId, transfer.StateCompleted); err != nil {
+	if err = c.tpStore.UpdateState(tpId, transfer.StateCompleted,
+		transfer.Cause{Initiator: core.RoleConsumer}); err != nil {
 		return errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
 	}
 
@@ -212,7 +241,7 @@ func (c *Controller) TerminateTransfer(tpId, code string, reasons []interface{})
 		return errors.StoreFailed(stores.TypeTransfer, `Process`, err)
 	}
 
-	if tp.State != transfer.StateRequested && tp.State != transfer.StateStarted && tp.State != transfer.StateSuspended {
+	if !transfer.ValidTransition(tp.State, transfer.StateTerminated) {
 		return errors.Client(errors.StateError(`terminate transfer`, string(tp.State)))
 	}
 
@@ -229,7 +258,8 @@ func (c *Controller) TerminateTransfer(tpId, code string, reasons []interface{})
 		return errors.CustomFuncError(`send`, err)
 	}
 
-	if err = c.tpStore.UpdateState(tpId, transfer.StateTerminated); err != nil {
+	if err = c.tpStore.UpdateState(tpId, transfer.StateTerminated,
+		transfer.Cause{Initiator: core.RoleConsumer, Code: code, Reasons: reasons}); err != nil {
 		return errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
 	}
 
@@ -265,3 +295,63 @@ func (c *Controller) send(tpId, endpoint string, req any) (transfer.Ack, error)
 
 	return ack, nil
 }
+
+// setCounterparty binds the transfer process to the provider of the negotiation which
+// concluded the agreement, so that only its messages are accepted for the process
+func (c *Controller) setCounterparty(tpId, agreementId string) error {
+	cnId, err := c.agrStore.NegotiationID(agreementId)
+	if err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return errors.Client(errors.InvalidKey(stores.TypeAgreement, `agreement id`, err))
+		}
+		return errors.StoreFailed(stores.TypeAgreement, `NegotiationID`, err)
+	}
+
+	provider, err := c.cnStore.Counterparty(cnId)
+	if err != nil {
+		return errors.StoreFailed(stores.TypeContractNegotiation, `Counterparty`, err)
+	}
+
+	if err = c.tpStore.SetCounterparty(tpId, provider); err != nil {
+		return errors.StoreFailed(stores.TypeTransfer, `SetCounterparty`, err)
+	}
+	return nil
+}
+
+// validateFormat checks if the format is supported and, if the catalog of the provider
+// has already been received, if the dataset of the agreement has a matching distribution
+func (c *Controller) validateFormat(typ transfer.DataTransferType, mediaType, agreementId string) error {
+	if !typ.Supported() {
+		return errors.Client(errors.UnsupportedFormat(string(typ), mediaType))
+	}
+
+	agr, err := c.agrStore.Agreement(agreementId)
+	if err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return errors.Client(errors.InvalidKey(stores.TypeAgreement, `agreement id`, err))
+		}
+		return errors.StoreFailed(stores.TypeAgreement, `Agreement`, err)
+	}
+
+	cats, err := c.catalog.AllCatalogs()
+	if err != nil {
+		return errors.StoreFailed(stores.TypeConsumerCatalog, `AllCatalogs`, err)
+	}
+
+	for _, cat := range cats {
+		for _, ds := range cat.Datasets() {
+			if ds.ID != string(agr.Target) {
+				continue
+			}
+
+			if _, ok := ds.Distribution(string(typ), mediaType); !ok {
+				return errors.Client(errors.UnsupportedFormat(string(typ), mediaType))
+			}
+			return nil
+		}
+	}
+
+	c.log.Debug(fmt.Sprintf("dataset of the agreement is not found in stored catalogs and hence format "+
+		"is validated only by the provider (agreement: %s, dataset: %s)", agreementId, agr.Target))
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	defaultErr "errors"
 	"fmt"
 	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"github.com/YasiruR/connector/domain/core"
 	"github.com/YasiruR/connector/domain/errors"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
@@ -18,7 +19,7 @@ func NewHandler(tpStore stores.TransferStore, log pkg.Log) *Handler {
 	return &Handler{tpStore: tpStore, log: log}
 }
 
-func (h *Handler) HandleTransferStart(sr transfer.StartRequest) (transfer.Ack, error) {
+func (h *Handler) HandleTransferStart(sr transfer.StartRequest, participantId string) (transfer.Ack, error) {
 	tp, err := h.tpStore.Process(sr.ConsPId)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -28,15 +29,22 @@ func (h *Handler) HandleTransferStart(sr transfer.StartRequest) (transfer.Ack, e
 		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `Process`, err)
 	}
 
-	// validate if received details are compatible with existing TP
-
-	if tp.State != transfer.StateRequested && tp.State != transfer.StateSuspended {
-		return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
-			errors.StateError(`start transfer`, string(tp.State)))
+	if err = h.validateCounterparty(tp, participantId); err != nil {
+		return transfer.Ack{}, err
 	}
 
-	if err = h.tpStore.UpdateState(sr.ConsPId, transfer.StateStarted); err != nil {
-		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+	if err = h.tpStore.UpdateState(sr.ConsPId, transfer.StateStarted,
+		transfer.Cause{Initiator: core.RoleProvider}); err != nil {
+		switch {
+		case defaultErr.Is(err, stores.TypeInvalidTransition):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.StateError(`start transfer`, string(tp.State)))
+		case defaultErr.Is(err, stores.TypeConflict):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.Conflict(`start transfer`, err))
+		default:
+			return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+		}
 	}
 
 	tp.State = transfer.StateStarted
@@ -45,7 +53,7 @@ func (h *Handler) HandleTransferStart(sr transfer.StartRequest) (transfer.Ack, e
 	return transfer.Ack(tp), nil
 }
 
-func (h *Handler) HandleTransferSuspension(sr transfer.SuspendRequest) (transfer.Ack, error) {
+func (h *Handler) HandleTransferSuspension(sr transfer.SuspendRequest, participantId string) (transfer.Ack, error) {
 	tp, err := h.tpStore.Process(sr.ConsPId)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -55,13 +63,22 @@ func (h *Handler) HandleTransferSuspension(sr transfer.SuspendRequest) (transfer
 		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `Process`, err)
 	}
 
-	if tp.State != transfer.StateStarted {
-		return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
-			errors.StateError(`suspend transfer`, string(tp.State)))
+	if err = h.validateCounterparty(tp, participantId); err != nil {
+		return transfer.Ack{}, err
 	}
 
-	if err = h.tpStore.UpdateState(sr.ConsPId, transfer.StateSuspended); err != nil {
-		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+	if err = h.tpStore.UpdateState(sr.ConsPId, transfer.StateSuspended,
+		transfer.Cause{Initiator: core.RoleProvider, Code: sr.Code, Reasons: sr.Reason}); err != nil {
+		switch {
+		case defaultErr.Is(err, stores.TypeInvalidTransition):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.StateError(`suspend transfer`, string(tp.State)))
+		case defaultErr.Is(err, stores.TypeConflict):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.Conflict(`suspend transfer`, err))
+		default:
+			return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+		}
 	}
 
 	tp.State = transfer.StateSuspended
@@ -70,7 +87,7 @@ func (h *Handler) HandleTransferSuspension(sr transfer.SuspendRequest) (transfer
 	return transfer.Ack(tp), nil
 }
 
-func (h *Handler) HandleTransferCompletion(cr transfer.CompleteRequest) (transfer.Ack, error) {
+func (h *Handler) HandleTransferCompletion(cr transfer.CompleteRequest, participantId string) (transfer.Ack, error) {
 	tp, err := h.tpStore.Process(cr.ConsPId)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -80,8 +97,22 @@ func (h *Handler) HandleTransferCompletion(cr transfer.CompleteRequest) (transfe
 		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `Process`, err)
 	}
 
-	if err = h.tpStore.UpdateState(cr.ConsPId, transfer.StateCompleted); err != nil {
-		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+	if err = h.validateCounterparty(tp, participantId); err != nil {
+		return transfer.Ack{}, err
+	}
+
+	if err = h.tpStore.UpdateState(cr.ConsPId, transfer.StateCompleted,
+		transfer.Cause{Initiator: core.RoleProvider}); err != nil {
+		switch {
+		case defaultErr.Is(err, stores.TypeInvalidTransition):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.StateError(`complete transfer`, string(tp.State)))
+		case defaultErr.Is(err, stores.TypeConflict):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.Conflict(`complete transfer`, err))
+		default:
+			return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+		}
 	}
 
 	tp.State = transfer.StateCompleted
@@ -89,8 +120,8 @@ func (h *Handler) HandleTransferCompletion(cr transfer.CompleteRequest) (transfe
 	return transfer.Ack(tp), nil
 }
 
-func (h *Handler) HandleTransferTermination(tr transfer.TerminateRequest) (transfer.Ack, error) {
-	tp, err := h.tpStore.Process(tr.ProvPId)
+func (h *Handler) HandleTransferTermination(tr transfer.TerminateRequest, participantId string) (transfer.Ack, error) {
+	tp, err := h.tpStore.Process(tr.ConsPId)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
 			return transfer.Ack{}, errors.Transfer(tr.ProvPId, tr.ConsPId,
@@ -99,13 +130,22 @@ func (h *Handler) HandleTransferTermination(tr transfer.TerminateRequest) (trans
 		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `Process`, err)
 	}
 
-	if tp.State != transfer.StateRequested && tp.State != transfer.StateStarted && tp.State != transfer.StateSuspended {
-		return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
-			errors.StateError(`terminate transfer`, string(tp.State)))
+	if err = h.validateCounterparty(tp, participantId); err != nil {
+		return transfer.Ack{}, err
 	}
 
-	if err = h.tpStore.UpdateState(tr.ProvPId, transfer.StateTerminated); err != nil {
-		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+	if err = h.tpStore.UpdateState(tr.ConsPId, transfer.StateTerminated,
+		transfer.Cause{Initiator: core.RoleProvider, Code: tr.Code, Reasons: tr.Reason}); err != nil {
+		switch {
+		case defaultErr.Is(err, stores.TypeInvalidTransition):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.StateError(`terminate transfer`, string(tp.State)))
+		case defaultErr.Is(err, stores.TypeConflict):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.Conflict(`terminate transfer`, err))
+		default:
+			return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+		}
 	}
 
 	tp.State = transfer.StateTerminated
@@ -113,3 +153,18 @@ func (h *Handler) HandleTransferTermination(tr transfer.TerminateRequest) (trans
 		tr.ProvPId, tr.Reason))
 	return transfer.Ack(tp), nil
 }
+
+// validateCounterparty rejects a message of the transfer process unless it is sent by the
+// provider of the negotiation which concluded the agreement
+func (h *Handler) validateCounterparty(tp transfer.Process, participantId string) error {
+	counterparty, err := h.tpStore.Counterparty(tp.ConsPId)
+	if err != nil && !defaultErr.Is(err, stores.TypeInvalidKey) {
+		return errors.StoreFailed(stores.TypeTransfer, `Counterparty`, err)
+	}
+
+	if counterparty == `` || counterparty != participantId {
+		return errors.Transfer(tp.ProvPId, tp.ConsPId, errors.InvalidValue(`participant`, counterparty,
+			participantId))
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package dataplane
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	defaultErr "errors"
+	"fmt"
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/api"
+	dpHttp "github.com/YasiruR/connector/domain/api/dataplane/http"
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"github.com/YasiruR/connector/domain/boot"
+	"github.com/YasiruR/connector/domain/core/dataplane"
+	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/domain/stores"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Consumer is the data plane of a consumer which receives the data pushed by
+// providers and writes it to the storage under the ID of the transfer process
+type Consumer struct {
+	endpoint string
+	dpStore  stores.DataPlaneStore
+	tpStore  stores.TransferStore
+	storage  pkg.Storage
+	log      pkg.Log
+}
+
+func NewConsumer(cfg boot.Config, stores domain.Stores, plugins domain.Plugins) *Consumer {
+	plugins.Log.Info("initialized consumer data plane")
+	return &Consumer{
+		endpoint: cfg.Servers.IP + `:` + strconv.Itoa(cfg.Servers.DataPlane.HTTP.Port),
+		dpStore:  stores.DataPlaneStore,
+		tpStore:  stores.TransferStore,
+		storage:  plugins.Storage,
+		log:      plugins.Log,
+	}
+}
+
+// SinkAddress issues a token without an expiry since the provider may start the
+// transfer at any time after it is requested
+func (c *Consumer) SinkAddress(tpId string) (transfer.Address, error) {
+	val, err := newToken()
+	if err != nil {
+		return transfer.Address{}, errors.CustomFuncError(`newToken`, err)
+	}
+
+	if err = c.dpStore.SetToken(tpId, dataplane.Token{Value: val}); err != nil {
+		return transfer.Address{}, errors.StoreFailed(stores.TypeDataPlane, `SetToken`, err)
+	}
+
+	return transfer.Address{
+		Type:         transfer.MsgTypeDataAddress,
+		EndpointType: transfer.EndpointTypeHTTP,
+		Endpoint:     c.endpoint + api.SetParamPid(dpHttp.SinkEndpoint, tpId),
+		EndpointProperties: []transfer.EndpointProperty{
+			{Type: transfer.MsgTypeEndpointProperty, Name: dataplane.PropertyAuthorization, Value: val},
+			{Type: transfer.MsgTypeEndpointProperty, Name: dataplane.PropertyAuthType, Value: dataplane.AuthTypeBearer},
+		},
+	}, nil
+}
+
+func (c *Consumer) Receive(tpId, token string, offset int64, data dataplane.Data,
+	digest func() string) (int64, error) {
+	if _, err := c.authorize(tpId, token); err != nil {
+		return 0, err
+	}
+
+	// chunks may be resent from an earlier offset if their acknowledgement was lost
+	// but a gap in the received data is not allowed
+	cur, err := c.tpStore.Offset(tpId)
+	if err != nil {
+		return 0, errors.StoreFailed(stores.TypeTransfer, `Offset`, err)
+	}
+
+	if offset > cur {
+		return 0, dataCorrupted(`offset`, strconv.FormatInt(offset, 10), strconv.FormatInt(cur, 10))
+	}
+
+	h := sha256.New()
+	n, err := c.storage.WriteAt(tpId, offset, io.TeeReader(data.Content, h))
+	if err != nil {
+		return 0, errors.PkgError(pkg.TypeStorage, `WriteAt`, err, tpId)
+	}
+
+	if err = verify(data.Size, digest(), n, Digest(h.Sum(nil))); err != nil {
+		// content is truncated to the offset so that the chunk can be resent
+		if _, wErr := c.storage.WriteAt(tpId, offset, strings.NewReader(``)); wErr != nil {
+			c.log.Error(errors.PkgError(pkg.TypeStorage, `WriteAt`, wErr, tpId))
+		}
+		return 0, err
+	}
+
+	if err = c.tpStore.SetOffset(tpId, offset+n); err != nil {
+		return 0, errors.StoreFailed(stores.TypeTransfer, `SetOffset`, err)
+	}
+
+	c.setProgress(tpId, offset+n, -1)
+	c.log.Trace(fmt.Sprintf("data plane received a chunk of transfer process (id: %s, offset: %d, bytes: %d)",
+		tpId, offset, n))
+	return offset + n, nil
+}
+
+func (c *Consumer) Commit(tpId, token string, size int64) (dataplane.Receipt, error) {
+	tkn, err := c.authorize(tpId, token)
+	if err != nil {
+		return dataplane.Receipt{}, err
+	}
+
+	received, err := c.tpStore.Offset(tpId)
+	if err != nil {
+		return dataplane.Receipt{}, errors.StoreFailed(stores.TypeTransfer, `Offset`, err)
+	}
+
+	if size >= 0 && size != received {
+		return dataplane.Receipt{}, dataCorrupted(`size`, strconv.FormatInt(size, 10),
+			strconv.FormatInt(received, 10))
+	}
+
+	// partial content does not exist if the data is empty
+	if received == 0 {
+		if _, err = c.storage.WriteAt(tpId, 0, strings.NewReader(``)); err != nil {
+			return dataplane.Receipt{}, errors.PkgError(pkg.TypeStorage, `WriteAt`, err, tpId)
+		}
+	}
+
+	if err = c.storage.Commit(tpId); err != nil {
+		return dataplane.Receipt{}, errors.PkgError(pkg.TypeStorage, `Commit`, err, tpId)
+	}
+
+	sum, err := c.digest(tpId)
+	if err != nil {
+		return dataplane.Receipt{}, errors.CustomFuncError(`digest`, err)
+	}
+
+	// token is valid only until the data is committed
+	tkn.Revoked = true
+	if err = c.dpStore.SetToken(tpId, tkn); err != nil {
+		return dataplane.Receipt{}, errors.StoreFailed(stores.TypeDataPlane, `SetToken`, err)
+	}
+
+	c.setProgress(tpId, received, received)
+	c.log.Debug(fmt.Sprintf("data plane received data of transfer process (id: %s, bytes: %d)", tpId, received))
+	return dataplane.Receipt{Size: received, Digest: sum}, nil
+}
+
+// authorize validates the token of the sink and the state of the transfer process
+func (c *Consumer) authorize(tpId, token string) (dataplane.Token, error) {
+	tkn, err := c.dpStore.Token(tpId)
+	if err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return dataplane.Token{}, errors.Transfer(``, tpId, errors.Unauthorized(accessNotGranted()))
+		}
+		return dataplane.Token{}, errors.StoreFailed(stores.TypeDataPlane, `Token`, err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(tkn.Value), []byte(token)) != 1 {
+		return dataplane.Token{}, errors.Transfer(``, tpId, errors.Unauthorized(invalidToken()))
+	}
+
+	if !tkn.Valid(time.Now()) {
+		return dataplane.Token{}, errors.Transfer(``, tpId, errors.Unauthorized(tokenRevoked(tkn)))
+	}
+
+	tp, err := c.tpStore.Process(tpId)
+	if err != nil {
+		return dataplane.Token{}, errors.StoreFailed(stores.TypeTransfer, `Process`, err)
+	}
+
+	// process resumed by the consumer is updated only once the provider acknowledges the
+	// start message and therefore, data may be received while it is still suspended
+	if tp.State != transfer.StateStarted && tp.State != transfer.StateSuspended {
+		return dataplane.Token{}, errors.Transfer(tp.ProvPId, tpId,
+			errors.StateError(`receive data`, string(tp.State)))
+	}
+
+	return tkn, nil
+}
+
+// digest computes the digest of the committed content
+func (c *Consumer) digest(tpId string) (string, error) {
+	content, err := c.storage.Open(tpId)
+	if err != nil {
+		return ``, errors.PkgError(pkg.TypeStorage, `Open`, err, tpId)
+	}
+	defer content.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, content); err != nil {
+		return ``, errors.PkgError(pkg.TypeStorage, `Open`, err, tpId)
+	}
+	return Digest(h.Sum(nil)), nil
+}
+
+func (c *Consumer) setProgress(tpId string, received, total int64) {
+	if err := c.dpStore.SetProgress(tpId, dataplane.Progress{Transferred: received, Total: total,
+		UpdatedAt: time.Now().UTC()}); err != nil {
+		c.log.Warn(errors.StoreFailed(stores.TypeDataPlane, `SetProgress`, err))
+	}
+}
+
+// verify compares the received chunk with the size and digest declared by the sender,
+// where either of them can be omitted
+func verify(size int64, digest string, received int64, sum string) error {
+	if size >= 0 && size != received {
+		return dataCorrupted(`size`, strconv.FormatInt(size, 10), strconv.FormatInt(received, 10))
+	}
+
+	if digest != `` && digest != sum {
+		return dataCorrupted(`digest`, digest, sum)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package dataplane
+
+import (
+	"fmt"
+	"github.com/YasiruR/connector/domain/core/dataplane"
+	"time"
+)
+
+func accessNotGranted() error {
+	return fmt.Errorf("access to the transfer process has not been granted")
+}
+
+func invalidToken() error {
+	return fmt.Errorf("access token does not match with the transfer process")
+}
+
+func tokenRevoked(t dataplane.Token) error {
+	return fmt.Errorf("access token is revoked or expired (revoked: %t, expires at: %s)", t.Revoked,
+		t.ExpiresAt.Format(time.RFC3339))
+}
+
+func distributionNotFound(datasetId, format, mediaType string) error {
+	return fmt.Errorf("dataset does not have a distribution matching the transfer request (dataset: %s, "+
+		"format: %s, media type: %s)", datasetId, format, mediaType)
+}
+
+func contentNotFound(distributionId string) error {
+	return fmt.Errorf("distribution does not have a data source or an access service (distribution: %s)",
+		distributionId)
+}
+
+func fetchFailed(url string, err error) error {
+	return fmt.Errorf("fetching data from the distribution failed (endpoint: %s) - %s", url, err)
+}
+
+func sinkNotFound(tpId string) error {
+	return fmt.Errorf("sink address is not provided for the push transfer (id: %s)", tpId)
+}
+
+func pushFailed(sink string, err error) error {
+	return fmt.Errorf("pushing data to the sink failed (endpoint: %s) - %s", sink, err)
+}
+
+func dataCorrupted(attr, declared, received string) error {
+	return fmt.Errorf("%w (attribute: %s, declared: %s, received: %s)", dataplane.TypeCorrupted, attr,
+		declared, received)
+}
+
+func incompleteSource(read, size int64) error {
+	return fmt.Errorf("data source ended before the declared size (read: %d, size: %d)", read, size)
+}
@@ -0,0 +1,257 @@
+package dataplane
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	defaultErr "errors"
+	"fmt"
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/api"
+	dpHttp "github.com/YasiruR/connector/domain/api/dataplane/http"
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"github.com/YasiruR/connector/domain/boot"
+	"github.com/YasiruR/connector/domain/core/dataplane"
+	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/models/dcat"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/domain/stores"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTokenTTL  = 5 * time.Minute
+	defaultChunkSize = 8 << 20
+	tokenLength      = 32
+)
+
+// Provider is the data plane of a provider which serves or pushes the data of a
+// transfer from the data source of the dataset targeted by its agreement
+type Provider struct {
+	endpoint  string
+	ttl       time.Duration
+	chunkSize int64
+	dpStore   stores.DataPlaneStore
+	tpStore   stores.TransferStore
+	agrStore  stores.AgreementStore
+	catalog   stores.ProviderCatalog
+	sources   pkg.DataSources
+	pushes    *sync.Map // transfer process ID -> context.CancelFunc of the ongoing push
+	hc        *http.Client
+	log       pkg.Log
+}
+
+func NewProvider(cfg boot.Config, stores domain.Stores, plugins domain.Plugins) *Provider {
+	ttl := time.Duration(cfg.DataPlane.TokenTTL) * time.Second
+	if ttl <= 0 {
+		ttl = defaultTokenTTL
+	}
+
+	chunkSize := cfg.DataPlane.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	plugins.Log.Info("initialized provider data plane", "token ttl: "+ttl.String(),
+		"chunk size: "+strconv.FormatInt(chunkSize, 10))
+	return &Provider{
+		endpoint:  cfg.Servers.IP + `:` + strconv.Itoa(cfg.Servers.DataPlane.HTTP.Port),
+		ttl:       ttl,
+		chunkSize: chunkSize,
+		dpStore:   stores.DataPlaneStore,
+		tpStore:   stores.TransferStore,
+		agrStore:  stores.AgreementStore,
+		catalog:   stores.ProviderCatalog,
+		sources:   plugins.DataSources,
+		pushes:    new(sync.Map),
+		hc:        http.DefaultClient,
+		log:       plugins.Log,
+	}
+}
+
+func (p *Provider) Activate(tpId string) (transfer.Address, error) {
+	tkn, err := p.dpStore.Token(tpId)
+	if err != nil && !defaultErr.Is(err, stores.TypeInvalidKey) {
+		return transfer.Address{}, errors.StoreFailed(stores.TypeDataPlane, `Token`, err)
+	}
+
+	if tkn.Value == `` {
+		if tkn.Value, err = newToken(); err != nil {
+			return transfer.Address{}, errors.CustomFuncError(`newToken`, err)
+		}
+	}
+
+	tkn.ExpiresAt = time.Now().Add(p.ttl)
+	tkn.Revoked = false
+	if err = p.dpStore.SetToken(tpId, tkn); err != nil {
+		return transfer.Address{}, errors.StoreFailed(stores.TypeDataPlane, `SetToken`, err)
+	}
+
+	p.log.Debug(fmt.Sprintf("data plane granted access to transfer process (id: %s, expires at: %s)",
+		tpId, tkn.ExpiresAt.Format(time.RFC3339)))
+	return transfer.Address{
+		Type:         transfer.MsgTypeDataAddress,
+		EndpointType: transfer.EndpointTypeHTTP,
+		Endpoint:     p.endpoint + api.SetParamPid(dpHttp.PullEndpoint, tpId),
+		EndpointProperties: []transfer.EndpointProperty{
+			{Type: transfer.MsgTypeEndpointProperty, Name: dataplane.PropertyAuthorization, Value: tkn.Value},
+			{Type: transfer.MsgTypeEndpointProperty, Name: dataplane.PropertyAuthType, Value: dataplane.AuthTypeBearer},
+		},
+	}, nil
+}
+
+func (p *Provider) Revoke(tpId string) error {
+	if cancel, ok := p.pushes.LoadAndDelete(tpId); ok {
+		cancel.(context.CancelFunc)()
+		p.log.Debug(fmt.Sprintf("data plane cancelled the push of transfer process (id: %s)", tpId))
+	}
+
+	tkn, err := p.dpStore.Token(tpId)
+	if err != nil {
+		// push transfers and pull transfers which were never started do not have a token
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return nil
+		}
+		return errors.StoreFailed(stores.TypeDataPlane, `Token`, err)
+	}
+
+	tkn.Revoked = true
+	if err = p.dpStore.SetToken(tpId, tkn); err != nil {
+		return errors.StoreFailed(stores.TypeDataPlane, `SetToken`, err)
+	}
+
+	p.log.Debug(fmt.Sprintf("data plane revoked access to transfer process (id: %s)", tpId))
+	return nil
+}
+
+func (p *Provider) Pull(tpId, token string, offset int64) (dataplane.Data, error) {
+	tkn, err := p.dpStore.Token(tpId)
+	if err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return dataplane.Data{}, errors.Transfer(tpId, ``, errors.Unauthorized(accessNotGranted()))
+		}
+		return dataplane.Data{}, errors.StoreFailed(stores.TypeDataPlane, `Token`, err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(tkn.Value), []byte(token)) != 1 {
+		return dataplane.Data{}, errors.Transfer(tpId, ``, errors.Unauthorized(invalidToken()))
+	}
+
+	if !tkn.Valid(time.Now()) {
+		return dataplane.Data{}, errors.Transfer(tpId, ``, errors.Unauthorized(tokenRevoked(tkn)))
+	}
+
+	tp, err := p.tpStore.Process(tpId)
+	if err != nil {
+		return dataplane.Data{}, errors.StoreFailed(stores.TypeTransfer, `Process`, err)
+	}
+
+	if tp.State != transfer.StateStarted {
+		return dataplane.Data{}, errors.Transfer(tpId, tp.ConsPId,
+			errors.StateError(`pull data`, string(tp.State)))
+	}
+
+	return p.open(context.Background(), tpId, offset)
+}
+
+// open streams the content of the dataset targeted by the agreement of the transfer
+// process from its data source starting from the offset. Datasets created without a
+// data source are fetched from the access service of their distribution instead.
+func (p *Provider) open(ctx context.Context, tpId string, offset int64) (dataplane.Data, error) {
+	req, err := p.tpStore.Request(tpId)
+	if err != nil {
+		return dataplane.Data{}, errors.StoreFailed(stores.TypeTransfer, `Request`, err)
+	}
+
+	agr, err := p.agrStore.Agreement(req.AgreementId)
+	if err != nil {
+		return dataplane.Data{}, errors.StoreFailed(stores.TypeAgreement, `Agreement`, err)
+	}
+
+	ds, err := p.catalog.Dataset(string(agr.Target))
+	if err != nil {
+		return dataplane.Data{}, errors.StoreFailed(stores.TypeProviderCatalog, `Dataset`, err)
+	}
+
+	dist, ok := ds.Distribution(string(req.Format), req.MediaType)
+	if !ok {
+		return dataplane.Data{}, distributionNotFound(ds.ID, string(req.Format), req.MediaType)
+	}
+
+	ref, err := p.catalog.Source(dist.ID)
+	if err != nil {
+		if !defaultErr.Is(err, stores.TypeInvalidKey) {
+			return dataplane.Data{}, errors.StoreFailed(stores.TypeProviderCatalog, `Source`, err)
+		}
+
+		if len(dist.DcatAccessService) == 0 {
+			return dataplane.Data{}, contentNotFound(dist.ID)
+		}
+		return p.fetch(ctx, dist, offset)
+	}
+
+	src, err := p.sources.DataSource(ref.ID)
+	if err != nil {
+		return dataplane.Data{}, errors.PkgError(pkg.TypeDataSource, `DataSource`, err, ref.ID)
+	}
+
+	content, info, err := src.Open(ctx, ref.Path, offset)
+	if err != nil {
+		return dataplane.Data{}, errors.PkgError(pkg.TypeDataSource, `Open`, err, ref.ID, ref.Path)
+	}
+
+	contentType := info.ContentType
+	if contentType == `` {
+		contentType = dist.DcatMediaType
+	}
+
+	return dataplane.Data{Content: content, ContentType: contentType, Size: info.Size}, nil
+}
+
+// fetch streams the content of the distribution from its first access service, where
+// the bytes before the offset are skipped since access services are not required to
+// support ranges
+func (p *Provider) fetch(ctx context.Context, dist dcat.Distribution, offset int64) (dataplane.Data, error) {
+	url := dist.DcatAccessService[0].EndpointURL
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return dataplane.Data{}, fetchFailed(url, err)
+	}
+
+	res, err := p.hc.Do(req)
+	if err != nil {
+		return dataplane.Data{}, fetchFailed(url, err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return dataplane.Data{}, fetchFailed(url, fmt.Errorf("received status code %d", res.StatusCode))
+	}
+
+	if offset > 0 {
+		if _, err = io.CopyN(io.Discard, res.Body, offset); err != nil {
+			res.Body.Close()
+			return dataplane.Data{}, fetchFailed(url, err)
+		}
+	}
+
+	contentType := res.Header.Get(`Content-Type`)
+	if contentType == `` {
+		contentType = dist.DcatMediaType
+	}
+
+	return dataplane.Data{Content: res.Body, ContentType: contentType, Size: res.ContentLength}, nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return ``, fmt.Errorf("generating random token failed - %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,262 @@
+package dataplane
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	defaultErr "errors"
+	"fmt"
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"github.com/YasiruR/connector/domain/core/dataplane"
+	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/stores"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// progressInterval is the minimum duration between two progress updates of a push
+const progressInterval = time.Second
+
+func (p *Provider) Push(tpId string) error {
+	req, err := p.tpStore.Request(tpId)
+	if err != nil {
+		return errors.StoreFailed(stores.TypeTransfer, `Request`, err)
+	}
+
+	if req.Address.Endpoint == `` {
+		return sinkNotFound(tpId)
+	}
+
+	// push is cancelled by revoking the access to the transfer process
+	ctx, cancel := context.WithCancel(context.Background())
+	p.pushes.Store(tpId, cancel)
+	defer func() {
+		p.pushes.Delete(tpId)
+		cancel()
+	}()
+
+	// a resumed transfer continues from the offset acknowledged by the sink
+	offset, err := p.tpStore.Offset(tpId)
+	if err != nil {
+		return errors.StoreFailed(stores.TypeTransfer, `Offset`, err)
+	}
+
+	data, err := p.open(ctx, tpId, offset)
+	if err != nil {
+		return p.pushError(ctx, err)
+	}
+	defer data.Content.Close()
+
+	pr := &progressReader{r: data.Content, total: data.Size}
+	pr.read.Store(offset)
+	stop := p.trackProgress(tpId, pr)
+	defer stop()
+
+	s := newSink(req.Address, data)
+	p.log.Info(fmt.Sprintf("data plane started pushing data of transfer process (id: %s, sink: %s, offset: %d)",
+		tpId, s.endpoint, offset))
+
+	br := bufio.NewReader(pr)
+	for {
+		// source is exhausted if no more data can be read
+		if _, err = br.Peek(1); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return p.pushError(ctx, pushFailed(s.endpoint, err))
+		}
+
+		n, err := p.pushChunk(ctx, s, io.LimitReader(br, p.chunkSize), offset)
+		if err != nil {
+			return p.pushError(ctx, err)
+		}
+
+		offset += n
+		if err = p.tpStore.SetOffset(tpId, offset); err != nil {
+			return errors.StoreFailed(stores.TypeTransfer, `SetOffset`, err)
+		}
+	}
+
+	if data.Size >= 0 && offset != data.Size {
+		return pushFailed(s.endpoint, incompleteSource(offset, data.Size))
+	}
+
+	if err = p.commit(ctx, s, offset); err != nil {
+		return p.pushError(ctx, err)
+	}
+
+	p.log.Info(fmt.Sprintf("data plane pushed data of transfer process (id: %s, bytes: %d)", tpId, offset))
+	return nil
+}
+
+// sink is the destination of a push along with the headers included in each request
+type sink struct {
+	endpoint string
+	header   http.Header
+}
+
+func newSink(addr transfer.Address, data dataplane.Data) sink {
+	header := make(http.Header)
+	if data.Size >= 0 {
+		header.Set(dataplane.HeaderSize, strconv.FormatInt(data.Size, 10))
+	}
+
+	if data.ContentType != `` {
+		header.Set(`Content-Type`, data.ContentType)
+	}
+
+	// endpoint properties provided by the consumer are used to authorize the push
+	var auth, authType string
+	for _, prop := range addr.EndpointProperties {
+		switch prop.Name {
+		case dataplane.PropertyAuthorization:
+			auth = prop.Value
+		case dataplane.PropertyAuthType:
+			authType = prop.Value
+		}
+	}
+
+	if auth != `` {
+		if strings.EqualFold(authType, dataplane.AuthTypeBearer) {
+			auth = `Bearer ` + auth
+		}
+		header.Set(`Authorization`, auth)
+	}
+
+	return sink{endpoint: addr.Endpoint, header: header}
+}
+
+// pushChunk sends the chunk to the sink as a single request and returns the number
+// of bytes sent once the sink acknowledges the chunk
+func (p *Provider) pushChunk(ctx context.Context, s sink, chunk io.Reader, offset int64) (int64, error) {
+	// chunk is streamed with chunked encoding so that its digest can be sent as a trailer
+	trailer := http.Header{dataplane.HeaderDigest: nil}
+	dr := &digestReader{r: chunk, h: sha256.New(), trailer: trailer}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.endpoint, dr)
+	if err != nil {
+		return 0, pushFailed(s.endpoint, err)
+	}
+
+	req.Header = s.header.Clone()
+	req.Header.Set(dataplane.HeaderOffset, strconv.FormatInt(offset, 10))
+	req.ContentLength = -1
+	req.Trailer = trailer
+
+	if err = p.send(req); err != nil {
+		return 0, err
+	}
+	return dr.n, nil
+}
+
+// commit requests the sink to conclude the push once all chunks are acknowledged
+func (p *Provider) commit(ctx context.Context, s sink, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, nil)
+	if err != nil {
+		return pushFailed(s.endpoint, err)
+	}
+
+	req.Header = s.header.Clone()
+	req.Header.Set(dataplane.HeaderSize, strconv.FormatInt(size, 10))
+	return p.send(req)
+}
+
+func (p *Provider) send(req *http.Request) error {
+	res, err := p.hc.Do(req)
+	if err != nil {
+		return pushFailed(req.URL.String(), err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return pushFailed(req.URL.String(), fmt.Errorf("sink responded with status code %d (%s)",
+			res.StatusCode, strings.TrimSpace(string(body))))
+	}
+	return nil
+}
+
+// pushError returns TypeCancelled if the push was cancelled since the error is a
+// consequence of the cancellation
+func (p *Provider) pushError(ctx context.Context, err error) error {
+	if defaultErr.Is(ctx.Err(), context.Canceled) {
+		return fmt.Errorf("%w - %s", dataplane.TypeCancelled, err)
+	}
+	return err
+}
+
+// trackProgress periodically stores the progress of the reader until the returned
+// function is called, which stores the final progress
+func (p *Provider) trackProgress(tpId string, pr *progressReader) (stop func()) {
+	done := make(chan struct{})
+	update := func() {
+		if err := p.dpStore.SetProgress(tpId, pr.progress()); err != nil {
+			p.log.Error(errors.StoreFailed(stores.TypeDataPlane, `SetProgress`, err))
+		}
+	}
+
+	update()
+	go func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				update()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		update()
+	}
+}
+
+// progressReader counts the bytes read from the underlying reader
+type progressReader struct {
+	r     io.Reader
+	read  atomic.Int64
+	total int64
+}
+
+func (pr *progressReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	pr.read.Add(int64(n))
+	return n, err
+}
+
+func (pr *progressReader) progress() dataplane.Progress {
+	return dataplane.Progress{Transferred: pr.read.Load(), Total: pr.total, UpdatedAt: time.Now().UTC()}
+}
+
+// digestReader computes the digest of the data read from the underlying reader and
+// sets it in the trailer once the data is completely read
+type digestReader struct {
+	r       io.Reader
+	h       hash.Hash
+	n       int64
+	trailer http.Header
+}
+
+func (dr *digestReader) Read(b []byte) (int, error) {
+	n, err := dr.r.Read(b)
+	dr.h.Write(b[:n])
+	dr.n += int64(n)
+	if err == io.EOF {
+		dr.trailer.Set(dataplane.HeaderDigest, Digest(dr.h.Sum(nil)))
+	}
+	return n, err
+}
+
+// Digest formats the hash as the value of the digest header
+func Digest(sum []byte) string {
+	return dataplane.DigestAlgorithm + `=:` + base64.StdEncoding.EncodeToString(sum) + `:`
+}
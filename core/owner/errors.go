@@ -0,0 +1,32 @@
+package owner
+
+import (
+	"fmt"
+	"github.com/YasiruR/connector/domain/stores"
+)
+
+func noDistributions() error {
+	return fmt.Errorf("dataset should have at least one distribution")
+}
+
+func unsupportedFormat(format string) error {
+	return fmt.Errorf("format of the distribution is not a supported transfer type (format: %s)", format)
+}
+
+func duplicateDistribution(format, mediaType string) error {
+	return fmt.Errorf("distributions should differ either by format or media type (format: %s, "+
+		"media type: %s)", format, mediaType)
+}
+
+func rootCatalog(id string) error {
+	return fmt.Errorf("root catalog is defined by the configuration and cannot be modified (id: %s)", id)
+}
+
+func offerInNegotiation(offerId, cnId, state string) error {
+	return stores.InUse(offerId, fmt.Sprintf("offer of a contract negotiation in progress (negotiation: %s, "+
+		"state: %s)", cnId, state))
+}
+
+func offerAgreed(offerId, cnId string) error {
+	return stores.InUse(offerId, fmt.Sprintf("offer of a concluded contract agreement (negotiation: %s)", cnId))
+}
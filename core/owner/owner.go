@@ -1,22 +1,31 @@
 package owner
 
 import (
+	"context"
 	defaultErr "errors"
 	"fmt"
 	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
 	"github.com/YasiruR/connector/domain/boot"
+	"github.com/YasiruR/connector/domain/core"
+	"github.com/YasiruR/connector/domain/core/dataplane"
 	"github.com/YasiruR/connector/domain/errors"
 	"github.com/YasiruR/connector/domain/models/dcat"
 	"github.com/YasiruR/connector/domain/models/odrl"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
+	"sort"
+	"strings"
 )
 
 type Service struct {
 	assignerId string
 	catalog    stores.ProviderCatalog
 	ofrStore   stores.OfferStore
+	cnStore    stores.ContractNegotiationStore
 	urn        pkg.URNService
+	sources    pkg.DataSources
 	log        pkg.Log
 }
 
@@ -24,13 +33,16 @@ func New(cfg boot.Config, stores domain.Stores, plugins domain.Plugins) *Service
 	return &Service{
 		assignerId: cfg.DataSpace.AssignerId, // can we assign participant ID from config to assigner?
 		ofrStore:   stores.OfferStore,
+		cnStore:    stores.ContractNegotiationStore,
 		catalog:    stores.ProviderCatalog,
 		urn:        plugins.URNService,
+		sources:    plugins.DataSources,
 		log:        plugins.Log,
 	}
 }
 
-func (s *Service) CreatePolicy(target string, permissions, prohibitions []odrl.Rule) (ofrId string, err error) {
+func (s *Service) CreatePolicy(target string, permissions, prohibitions []odrl.Rule,
+	obligations []odrl.Duty) (ofrId string, err error) {
 	ofrId, err = s.urn.NewURN()
 	if err != nil {
 		return ``, errors.PkgError(pkg.TypeURN, `NewURN`, err, `offer id`)
@@ -44,6 +56,7 @@ func (s *Service) CreatePolicy(target string, permissions, prohibitions []odrl.R
 		Assigner:     odrl.Assigner(s.assignerId),
 		Permissions:  permissions,
 		Prohibitions: prohibitions,
+		Obligations:  obligations,
 	}
 
 	s.ofrStore.AddOffer(ofrId, ofr)
@@ -51,29 +64,397 @@ func (s *Service) CreatePolicy(target string, permissions, prohibitions []odrl.R
 	return ofrId, nil
 }
 
-// CreateDataset currently supports only one data distribution per a dataset
-func (s *Service) CreateDataset(title, format string, descriptions, keywords, endpoints, offerIds []string) (dsId string, err error) {
+func (s *Service) Policy(id string) (odrl.Offer, error) {
+	ofr, err := s.ofrStore.Offer(id)
+	if err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return odrl.Offer{}, errors.Client(errors.InvalidKey(stores.TypeOffer, `offer id`, err))
+		}
+		return odrl.Offer{}, errors.StoreFailed(stores.TypeOffer, `Offer`, err)
+	}
+	return ofr, nil
+}
+
+func (s *Service) Policies() ([]odrl.Offer, error) {
+	ofrs, err := s.ofrStore.Offers()
+	if err != nil {
+		return nil, errors.StoreFailed(stores.TypeOffer, `Offers`, err)
+	}
+	return ofrs, nil
+}
+
+// UpdatePolicy replaces the rules of an offer which is not being negotiated, and updates
+// the offer published in datasets accordingly. Agreements concluded on the offer are not
+// affected since they hold a copy of the rules.
+func (s *Service) UpdatePolicy(id, target string, permissions, prohibitions []odrl.Rule,
+	obligations []odrl.Duty) error {
+	ofr, err := s.Policy(id)
+	if err != nil {
+		return err
+	}
+
+	ofr.Target = odrl.Target(target)
+	ofr.Permissions = permissions
+	ofr.Prohibitions = prohibitions
+	ofr.Obligations = obligations
+	err = s.ofrStore.UpdateOffer(id, ofr, func(tx pkg.Transaction) error {
+		return s.validateOfferUsage(tx, id, false)
+	})
+	if err != nil {
+		return storeError(stores.TypeOffer, `UpdateOffer`, `offer id`, err)
+	}
+
+	if err = s.updatePublishedOffers(id, &ofr); err != nil {
+		return errors.CustomFuncError(`updatePublishedOffers`, err)
+	}
+
+	s.log.Trace("updated the offer", ofr)
+	return nil
+}
+
+// DeletePolicy retires an offer which is neither being negotiated nor agreed upon,
+// and then removes it from the datasets which publish it
+func (s *Service) DeletePolicy(id string) error {
+	err := s.ofrStore.DeleteOffer(id, func(tx pkg.Transaction) error {
+		return s.validateOfferUsage(tx, id, true)
+	})
+	if err != nil {
+		return storeError(stores.TypeOffer, `DeleteOffer`, `offer id`, err)
+	}
+
+	if err = s.updatePublishedOffers(id, nil); err != nil {
+		return errors.CustomFuncError(`updatePublishedOffers`, err)
+	}
+
+	s.log.Debug(fmt.Sprintf("deleted the offer (id: %s)", id))
+	return nil
+}
+
+func (s *Service) CreateCatalog(parentId, title string, descriptions, keywords,
+	endpoints []string) (catId string, err error) {
+	catId, err = s.urn.NewURN()
+	if err != nil {
+		return ``, errors.PkgError(pkg.TypeURN, `NewURN`, err, `catalog id`)
+	}
+
+	meta, err := s.catalogMetadata(catId, title, descriptions, keywords, endpoints)
+	if err != nil {
+		return ``, errors.CustomFuncError(`catalogMetadata`, err)
+	}
+
+	if err = s.catalog.AddCatalog(parentId, meta); err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return ``, errors.Client(errors.InvalidKey(stores.TypeProviderCatalog, `parent catalog id`, err))
+		}
+		return ``, errors.StoreFailed(stores.TypeProviderCatalog, `AddCatalog`, err)
+	}
+
+	s.log.Trace("created and stored a new catalog", meta)
+	return catId, nil
+}
+
+// UpdateCatalog replaces the metadata of a catalog, except for the root catalog which
+// is defined by the configuration
+func (s *Service) UpdateCatalog(id, title string, descriptions, keywords, endpoints []string) error {
+	if id == s.catalog.RootID() {
+		return errors.Client(errors.IncorrectReqValues(rootCatalog(id).Error()))
+	}
+
+	meta, err := s.catalogMetadata(id, title, descriptions, keywords, endpoints)
+	if err != nil {
+		return errors.CustomFuncError(`catalogMetadata`, err)
+	}
+
+	if err = s.catalog.UpdateCatalog(meta); err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return errors.Client(errors.InvalidKey(stores.TypeProviderCatalog, `catalog id`, err))
+		}
+		return errors.StoreFailed(stores.TypeProviderCatalog, `UpdateCatalog`, err)
+	}
+
+	s.log.Trace("updated the catalog", meta)
+	return nil
+}
+
+func (s *Service) DeleteCatalog(id string) error {
+	if err := s.catalog.DeleteCatalog(id); err != nil {
+		switch {
+		case defaultErr.Is(err, stores.TypeInvalidKey):
+			return errors.Client(errors.InvalidKey(stores.TypeProviderCatalog, `catalog id`, err))
+		case defaultErr.Is(err, stores.TypeInUse):
+			return errors.Client(errors.IncorrectReqValues(err.Error()))
+		default:
+			return errors.StoreFailed(stores.TypeProviderCatalog, `DeleteCatalog`, err)
+		}
+	}
+
+	s.log.Debug(fmt.Sprintf("deleted the catalog (id: %s)", id))
+	return nil
+}
+
+// CreateDataset creates a dataset in the catalog (or the root catalog if the ID is empty)
+// with a distribution for each format (and media type) in which the dataset is offered
+// to consumers.
+func (s *Service) CreateDataset(catalogId, title string, descriptions, keywords, offerIds []string,
+	distributions []core.Distribution) (dsId string, err error) {
+	if err = s.validateDistributions(distributions); err != nil {
+		return ``, errors.Client(errors.IncorrectReqValues(err.Error()))
+	}
+
 	// construct policies
+	ofrs, err := s.offers(offerIds)
+	if err != nil {
+		return ``, errors.CustomFuncError(`offers`, err)
+	}
+
+	// construct data distributions
+	var dists []dcat.Distribution
+	sources := make(map[string]dataplane.Source)
+	for _, d := range distributions {
+		dist, err := s.distribution(d)
+		if err != nil {
+			return ``, errors.CustomFuncError(`distribution`, err)
+		}
+
+		if d.Source.ID != `` {
+			sources[dist.ID] = d.Source
+		}
+		dists = append(dists, dist)
+	}
+
+	// construct and store final dataset
+	dsId, err = s.urn.NewURN()
+	if err != nil {
+		return ``, errors.PkgError(pkg.TypeURN, `NewURN`, err, `dataset id`)
+	}
+
+	if len(ofrs) == 0 {
+		s.log.Trace(fmt.Sprintf(`no policy offers associated with the dataset 
+			(dataset Id: %s, offer Ids: %s)`, dsId, offerIds))
+	}
+
+	ds := dcat.Dataset{
+		ID:               dsId,
+		Type:             dcat.TypeDataset,
+		DctTitle:         title,
+		DctDescription:   s.descriptions(descriptions),
+		DcatKeyword:      s.keywords(keywords),
+		OdrlHasPolicy:    ofrs,
+		DcatDistribution: dists,
+	}
+
+	if err = s.catalog.AddDataset(catalogId, dsId, ds, sources); err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return ``, errors.Client(errors.InvalidKey(stores.TypeProviderCatalog, `catalog id`, err))
+		}
+		return ``, errors.StoreFailed(stores.TypeProviderCatalog, `AddDataset`, err)
+	}
+	s.log.Trace("created and stored a new dataset", ds)
+	return dsId, nil
+}
+
+func (s *Service) Dataset(id string) (dcat.Dataset, error) {
+	ds, err := s.catalog.Dataset(id)
+	if err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return dcat.Dataset{}, errors.Client(errors.InvalidKey(stores.TypeProviderCatalog, `dataset id`, err))
+		}
+		return dcat.Dataset{}, errors.StoreFailed(stores.TypeProviderCatalog, `Dataset`, err)
+	}
+	return ds, nil
+}
+
+func (s *Service) Datasets() ([]dcat.Dataset, error) {
+	datasets, err := s.catalog.Datasets()
+	if err != nil {
+		return nil, errors.StoreFailed(stores.TypeProviderCatalog, `Datasets`, err)
+	}
+	return datasets, nil
+}
+
+// UpdateDataset replaces the metadata and the offers of a dataset while its
+// distributions remain unchanged
+func (s *Service) UpdateDataset(id, title string, descriptions, keywords, offerIds []string) error {
+	ds, err := s.Dataset(id)
+	if err != nil {
+		return err
+	}
+
+	ofrs, err := s.offers(offerIds)
+	if err != nil {
+		return errors.CustomFuncError(`offers`, err)
+	}
+
+	ds.DctTitle = title
+	ds.DctDescription = s.descriptions(descriptions)
+	ds.DcatKeyword = s.keywords(keywords)
+	ds.OdrlHasPolicy = ofrs
+	if err = s.catalog.UpdateDataset(id, ds); err != nil {
+		return errors.StoreFailed(stores.TypeProviderCatalog, `UpdateDataset`, err)
+	}
+
+	s.log.Trace("updated the dataset", ds)
+	return nil
+}
+
+// DeleteDataset removes a dataset only if none of its offers is being negotiated
+// or agreed upon, since transfers of an agreement are served from the dataset
+func (s *Service) DeleteDataset(id string) error {
+	err := s.catalog.DeleteDataset(id, func(tx pkg.Transaction, ds dcat.Dataset) error {
+		for _, ofr := range ds.OdrlHasPolicy {
+			if err := s.validateOfferUsage(tx, ofr.Id, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return storeError(stores.TypeProviderCatalog, `DeleteDataset`, `dataset id`, err)
+	}
+
+	s.log.Debug(fmt.Sprintf("deleted the dataset (id: %s)", id))
+	return nil
+}
+
+// offers returns the stored offers to be published in a dataset
+func (s *Service) offers(offerIds []string) ([]odrl.Offer, error) {
 	var ofrs []odrl.Offer
 	for _, ofrId := range offerIds {
-		ofr, err := s.ofrStore.Offer(ofrId)
+		ofr, err := s.Policy(ofrId)
 		if err != nil {
-			if defaultErr.Is(err, stores.TypeInvalidKey) {
-				return ``, errors.Client(errors.InvalidKey(stores.TypeOffer, `offer id`, err))
-			}
-			return ``, errors.StoreFailed(stores.TypeOffer, `Offer`, err)
+			return nil, err
 		}
 
 		ofr.Target = `` // since associated dataset id represents the target implicitly
 		ofrs = append(ofrs, ofr)
 	}
+	return ofrs, nil
+}
 
-	// construct data distribution
-	var svcList []dcat.AccessService
+// updatePublishedOffers replaces the offer in each dataset which publishes it, or
+// removes the offer from the datasets if it is nil
+func (s *Service) updatePublishedOffers(offerId string, ofr *odrl.Offer) error {
+	datasets, err := s.catalog.Datasets()
+	if err != nil {
+		return errors.StoreFailed(stores.TypeProviderCatalog, `Datasets`, err)
+	}
+
+	for _, ds := range datasets {
+		var ofrs []odrl.Offer
+		var published bool
+		for _, o := range ds.OdrlHasPolicy {
+			if o.Id != offerId {
+				ofrs = append(ofrs, o)
+				continue
+			}
+
+			published = true
+			if ofr != nil {
+				updated := *ofr
+				updated.Target = ``
+				ofrs = append(ofrs, updated)
+			}
+		}
+
+		if !published {
+			continue
+		}
+
+		ds.OdrlHasPolicy = ofrs
+		if err = s.catalog.UpdateDataset(ds.ID, ds); err != nil {
+			return errors.StoreFailed(stores.TypeProviderCatalog, `UpdateDataset`, err)
+		}
+	}
+	return nil
+}
+
+// validateOfferUsage checks if the offer is referred by a contract negotiation which
+// is in progress, or has concluded an agreement if agreements should be considered.
+// Negotiations are read within the transaction of the write which depends on the check.
+func (s *Service) validateOfferUsage(tx pkg.Transaction, offerId string, agreements bool) error {
+	states, err := s.cnStore.StatesByOffer(tx, offerId)
+	if err != nil {
+		return errors.StoreFailed(stores.TypeContractNegotiation, `StatesByOffer`, err)
+	}
+
+	cnIds := make([]string, 0, len(states))
+	for cnId := range states {
+		cnIds = append(cnIds, cnId)
+	}
+	sort.Strings(cnIds)
+
+	for _, cnId := range cnIds {
+		switch state := states[cnId]; state {
+		case negotiation.StateTerminated:
+			continue
+		case negotiation.StateFinalized:
+			if agreements {
+				return offerAgreed(offerId, cnId)
+			}
+		default:
+			return offerInNegotiation(offerId, cnId, string(state))
+		}
+	}
+	return nil
+}
+
+func (s *Service) catalogMetadata(id, title string, descriptions, keywords,
+	endpoints []string) (dcat.CatalogMetadata, error) {
+	var svcs []dcat.AccessService
 	for _, e := range endpoints {
+		svcId, err := s.urn.NewURN()
+		if err != nil {
+			return dcat.CatalogMetadata{}, errors.PkgError(pkg.TypeURN, `NewURN`, err, `service id`)
+		}
+
+		svcs = append(svcs, dcat.AccessService{
+			ID:                  svcId,
+			Type:                dcat.TypeDataService,
+			EndpointURL:         e,
+			EndpointDescription: core.ServiceConnector,
+		})
+	}
+
+	return dcat.CatalogMetadata{
+		ID:             id,
+		Type:           dcat.TypeCatalog,
+		DctTitle:       title,
+		DctDescription: s.descriptions(descriptions),
+		DcatKeyword:    s.keywords(keywords),
+		DcatService:    svcs,
+	}, nil
+}
+
+func (s *Service) descriptions(vals []string) []dcat.Description {
+	var descs []dcat.Description
+	for _, desc := range vals {
+		descs = append(descs, dcat.Description{
+			Value:    desc,
+			Language: dcat.LanguageEnglish, // support other languages
+		})
+	}
+	return descs
+}
+
+func (s *Service) keywords(vals []string) []dcat.Keyword {
+	var kws []dcat.Keyword
+	for _, kw := range vals {
+		kws = append(kws, dcat.Keyword(kw))
+	}
+	return kws
+}
+
+func (s *Service) distribution(d core.Distribution) (dcat.Distribution, error) {
+	distId, err := s.urn.NewURN()
+	if err != nil {
+		return dcat.Distribution{}, errors.PkgError(pkg.TypeURN, `NewURN`, err, `distribution id`)
+	}
+
+	var svcList []dcat.AccessService
+	for _, e := range d.Endpoints {
 		accessServiceId, err := s.urn.NewURN()
 		if err != nil {
-			return ``, errors.PkgError(pkg.TypeURN, `NewURN`, err, `access service id`)
+			return dcat.Distribution{}, errors.PkgError(pkg.TypeURN, `NewURN`, err, `access service id`)
 		}
 
 		svcList = append(svcList, dcat.AccessService{
@@ -83,47 +464,69 @@ func (s *Service) CreateDataset(title, format string, descriptions, keywords, en
 		})
 	}
 
-	dist := dcat.Distribution{
+	return dcat.Distribution{
+		ID:                distId,
 		Type:              dcat.TypeDistribution,
-		DctFormat:         format,
+		DctFormat:         d.Format,
+		DcatMediaType:     d.MediaType,
 		DcatAccessService: svcList,
-	}
+	}, nil
+}
 
-	// construct and store final dataset
-	dsId, err = s.urn.NewURN()
-	if err != nil {
-		return ``, errors.PkgError(pkg.TypeURN, `NewURN`, err, `dataset id`)
+// validateDistributions checks if each distribution is served with a supported transfer
+// type and can be selected unambiguously by its format and media type
+func (s *Service) validateDistributions(distributions []core.Distribution) error {
+	if len(distributions) == 0 {
+		return noDistributions()
 	}
 
-	var descs []dcat.Description
-	for _, desc := range descriptions {
-		descs = append(descs, dcat.Description{
-			Value:    desc,
-			Language: dcat.LanguageEnglish, // support other languages
-		})
-	}
+	selectors := make(map[string]bool)
+	for _, d := range distributions {
+		if !transfer.DataTransferType(d.Format).Supported() {
+			return unsupportedFormat(d.Format)
+		}
 
-	var kws []dcat.Keyword
-	for _, kw := range keywords {
-		kws = append(kws, dcat.Keyword(kw))
+		selector := strings.ToLower(d.Format + `;` + d.MediaType)
+		if selectors[selector] {
+			return duplicateDistribution(d.Format, d.MediaType)
+		}
+		selectors[selector] = true
+
+		if d.Source.ID != `` {
+			if err := s.validateSource(d.Source); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
+}
 
-	if len(ofrs) == 0 {
-		s.log.Trace(fmt.Sprintf(`no policy offers associated with the dataset 
-			(dataset Id: %s, offer Ids: %s)`, dsId, offerIds))
+// validateSource checks if the content referred by the source exists
+func (s *Service) validateSource(source dataplane.Source) error {
+	src, err := s.sources.DataSource(source.ID)
+	if err != nil {
+		return err
 	}
 
-	ds := dcat.Dataset{
-		ID:               dsId,
-		Type:             dcat.TypeDataset,
-		DctTitle:         title,
-		DctDescription:   descs,
-		DcatKeyword:      kws,
-		OdrlHasPolicy:    ofrs,
-		DcatDistribution: []dcat.Distribution{dist}, // support more distributions
+	info, err := src.Stat(context.Background(), source.Path)
+	if err != nil {
+		return err
 	}
 
-	s.catalog.AddDataset(dsId, ds)
-	s.log.Trace("created and stored a new dataset", ds)
-	return dsId, nil
+	s.log.Debug(fmt.Sprintf("validated data source of the dataset (source: %s, path: %s, size: %d)",
+		source.ID, source.Path, info.Size))
+	return nil
+}
+
+// storeError returns a client error if the stored value does not exist or is referred
+// by other values, and a store error otherwise
+func storeError(store, query, key string, err error) error {
+	switch {
+	case defaultErr.Is(err, stores.TypeInvalidKey):
+		return errors.Client(errors.InvalidKey(store, key, err))
+	case defaultErr.Is(err, stores.TypeInUse):
+		return errors.Client(errors.IncorrectReqValues(err.Error()))
+	default:
+		return errors.StoreFailed(store, query, err)
+	}
 }
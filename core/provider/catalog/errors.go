@@ -0,0 +1,7 @@
+package catalog
+
+import "fmt"
+
+func invalidCursor(cursor string, err error) error {
+	return fmt.Errorf("cursor of the catalog page is invalid (cursor: %s) - %s", cursor, err)
+}
@@ -1,40 +1,89 @@
 package catalog
 
 import (
+	"encoding/base64"
+	"fmt"
 	"github.com/YasiruR/connector/domain/api/dsp/http/catalog"
+	"github.com/YasiruR/connector/domain/boot"
 	"github.com/YasiruR/connector/domain/core"
 	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/models/dcat"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
+	"slices"
+	"strings"
 )
 
 // Catalog Protocol (reference: https://docs.internationaldataspaces.org/ids-knowledgebase/v/dataspace-protocol/catalog/catalog.protocol)
 
+const defaultPageSize = 100
+
 type Handler struct {
 	participantId string // data space specific identifier for Provider
+	pageSize      int
 	catStore      stores.ProviderCatalog
 	log           pkg.Log
 }
 
-func NewHandler(participantId string, cnStore stores.ProviderCatalog, log pkg.Log) *Handler {
+func NewHandler(cfg boot.Config, cnStore stores.ProviderCatalog, log pkg.Log) *Handler {
+	pageSize := cfg.Catalog.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
 	return &Handler{
-		participantId: participantId,
+		participantId: cfg.DataSpace.ParticipantId,
+		pageSize:      pageSize,
 		catStore:      cnStore,
 		log:           log,
 	}
 }
 
-func (h *Handler) HandleCatalogRequest(_ any) (catalog.Response, error) {
-	cat, err := h.catStore.Catalog()
+// HandleCatalogRequest returns the root catalog with a page of the datasets matching the
+// filter, where each dataset is included in its nested catalog. Datasets are ordered by
+// their IDs so that the cursor, which refers to the last dataset of the previous page,
+// remains valid when datasets are added in between.
+func (h *Handler) HandleCatalogRequest(filter []string, page catalog.Page) (catalog.Response, catalog.Page, error) {
+	f, err := catalog.ParseFilter(filter)
+	if err != nil {
+		return catalog.Response{}, catalog.Page{}, errors.Catalog(errors.IncorrectReqValues(err.Error()))
+	}
+
+	after, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return catalog.Response{}, catalog.Page{}, errors.Catalog(errors.IncorrectReqValues(err.Error()))
+	}
+
+	cat, err := h.catStore.Catalog(``)
 	if err != nil {
-		return catalog.Response{}, errors.StoreFailed(stores.TypeProviderCatalog, `Get`, err)
+		return catalog.Response{}, catalog.Page{}, errors.StoreFailed(stores.TypeProviderCatalog, `Catalog`, err)
 	}
 
+	all := cat.Datasets()
+	total := len(all)
+	datasets := f.Apply(all)
+	if len(filter) > 0 {
+		h.log.Debug(fmt.Sprintf("filtered datasets of the catalog (filter: %v, matched: %d, total: %d)",
+			filter, len(datasets), total))
+	}
+
+	limit := page.Limit
+	if limit <= 0 || limit > h.pageSize {
+		limit = h.pageSize
+	}
+
+	datasets, next := paginate(datasets, after, limit)
+	selected := make(map[string]bool)
+	for _, ds := range datasets {
+		selected[ds.ID] = true
+	}
+
+	cat = prune(cat, selected)
 	return catalog.Response{
 		Context:             core.Context,
 		DspaceParticipantID: h.participantId,
 		Catalog:             cat,
-	}, nil
+	}, next, nil
 }
 
 func (h *Handler) HandleDatasetRequest(id string) (catalog.DatasetResponse, error) {
@@ -49,3 +98,55 @@ func (h *Handler) HandleDatasetRequest(id string) (catalog.DatasetResponse, erro
 		Dataset: ds,
 	}, nil
 }
+
+// paginate returns at most limit datasets with IDs following the given ID, along with
+// the page of the remaining datasets if any
+func paginate(datasets []dcat.Dataset, after string, limit int) ([]dcat.Dataset, catalog.Page) {
+	datasets = slices.Clone(datasets)
+	slices.SortFunc(datasets, func(a, b dcat.Dataset) int { return strings.Compare(a.ID, b.ID) })
+
+	start, _ := slices.BinarySearchFunc(datasets, after, func(ds dcat.Dataset, id string) int {
+		return strings.Compare(ds.ID, id)
+	})
+	if start < len(datasets) && after != `` && datasets[start].ID == after {
+		start++
+	}
+
+	end := min(start+limit, len(datasets))
+	if end == len(datasets) {
+		return datasets[start:], catalog.Page{}
+	}
+
+	return datasets[start:end], catalog.Page{Limit: limit, Cursor: encodeCursor(datasets[end-1].ID)}
+}
+
+// prune removes the datasets which are not selected from the catalog and its nested
+// catalogs, whereas nested catalogs are retained to preserve the catalog structure
+func prune(cat dcat.Catalog, selected map[string]bool) dcat.Catalog {
+	datasets := make([]dcat.Dataset, 0)
+	for _, ds := range cat.DcatDataset {
+		if selected[ds.ID] {
+			datasets = append(datasets, ds)
+		}
+	}
+	cat.DcatDataset = datasets
+
+	var nested []dcat.Catalog
+	for _, n := range cat.DcatCatalog {
+		nested = append(nested, prune(n, selected))
+	}
+	cat.DcatCatalog = nested
+	return cat
+}
+
+func encodeCursor(datasetId string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(datasetId))
+}
+
+func decodeCursor(cursor string) (datasetId string, err error) {
+	id, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ``, invalidCursor(cursor, err)
+	}
+	return string(id), nil
+}
@@ -0,0 +1,128 @@
+package negotiation
+
+import (
+	"fmt"
+	"github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
+	"github.com/YasiruR/connector/domain/boot"
+	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/domain/stores"
+)
+
+// wildcard matches any offer or participant in a rule
+const wildcard = `*`
+
+// automation agrees to or finalizes the contract negotiations matching the configured
+// rules on behalf of the provider, leaving the others for manual review
+type automation struct {
+	rules    []boot.NegotiationRule
+	cnStore  stores.ContractNegotiationStore
+	agree    func(offerId, providerPid string) (string, error)
+	finalize func(providerPid string) error
+	log      pkg.Log
+}
+
+// validate warns about the rules which can never match since they do not list any offer
+// or participant
+func (a automation) validate() {
+	for i, rule := range a.rules {
+		if len(rule.Offers) == 0 || len(rule.Participants) == 0 {
+			a.log.Warn(fmt.Sprintf("negotiation automation rule %d does not match any negotiation since "+
+				"offers or participants are empty ('%s' matches any)", i, wildcard))
+		}
+	}
+}
+
+// onRequest agrees to the offer proposed by the consumer, which has already been
+// validated against the published offer
+func (a automation) onRequest(providerPid string, r negotiation.Round, participantId string) {
+	rule, ok := a.match(r.Offer.Id, participantId)
+	if !ok || !rule.Agree || (rule.Exact && len(r.Changes) != 0) {
+		a.log.Info(fmt.Sprintf("contract request is left for manual review (id: %s, offer: %s, "+
+			"participant: %s)", providerPid, r.Offer.Id, participantId))
+		return
+	}
+
+	go a.run(`AgreeContract`, providerPid, func() error {
+		_, err := a.agree(``, providerPid)
+		return err
+	})
+}
+
+// onAccept agrees to the offer of the provider accepted by the consumer
+func (a automation) onAccept(providerPid string) {
+	rule, ok := a.negotiationRule(providerPid)
+	if !ok || !rule.Agree {
+		a.log.Info(fmt.Sprintf("accepted offer is left for manual review (id: %s)", providerPid))
+		return
+	}
+
+	go a.run(`AgreeContract`, providerPid, func() error {
+		_, err := a.agree(``, providerPid)
+		return err
+	})
+}
+
+// onVerification finalizes the agreement verified by the consumer
+func (a automation) onVerification(providerPid string) {
+	rule, ok := a.negotiationRule(providerPid)
+	if !ok || !rule.Finalize {
+		a.log.Info(fmt.Sprintf("verified agreement is left for manual review (id: %s)", providerPid))
+		return
+	}
+
+	go a.run(`FinalizeContract`, providerPid, func() error { return a.finalize(providerPid) })
+}
+
+// negotiationRule returns the rule matching the offer of the latest round and the
+// consumer of the negotiation
+func (a automation) negotiationRule(providerPid string) (boot.NegotiationRule, bool) {
+	if len(a.rules) == 0 {
+		return boot.NegotiationRule{}, false
+	}
+
+	rounds, err := a.cnStore.Rounds(providerPid)
+	if err != nil {
+		a.log.Error(errors.StoreFailed(stores.TypeContractNegotiation, `Rounds`, err))
+		return boot.NegotiationRule{}, false
+	}
+
+	assignee, err := a.cnStore.Assignee(providerPid)
+	if err != nil {
+		a.log.Error(errors.StoreFailed(stores.TypeContractNegotiation, `Assignee`, err))
+		return boot.NegotiationRule{}, false
+	}
+
+	return a.match(rounds[len(rounds)-1].Offer.Id, string(assignee))
+}
+
+// match returns the first rule which applies to both the offer and the participant
+func (a automation) match(offerId, participantId string) (boot.NegotiationRule, bool) {
+	for _, rule := range a.rules {
+		if contains(rule.Offers, offerId) && contains(rule.Participants, participantId) {
+			return rule, true
+		}
+	}
+	return boot.NegotiationRule{}, false
+}
+
+// run executes the step and logs its outcome, and therefore, should be run in a separate
+// goroutine so that the message which triggered the step is acknowledged without delay
+func (a automation) run(step, providerPid string, fn func() error) {
+	if err := fn(); err != nil {
+		a.log.Error(errors.CustomFuncError(step, err))
+		return
+	}
+	a.log.Info(fmt.Sprintf("provider automated %s (id: %s)", step, providerPid))
+}
+
+// contains returns true if the list includes the value or the wildcard, and therefore,
+// an empty list does not match any value
+func contains(list []string, val string) bool {
+	for _, v := range list {
+		if v == val || v == wildcard {
+			return true
+		}
+	}
+	return false
+}
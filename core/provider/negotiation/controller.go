@@ -14,35 +14,47 @@ import (
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Controller struct {
 	callbackAddr string
+	db           pkg.Database
 	cnStore      stores.ContractNegotiationStore
 	policyStore  stores.OfferStore
 	agrStore     stores.AgreementStore
+	catalog      stores.ProviderCatalog
 	urn          pkg.URNService
 	client       pkg.Client
+	automation   automation
 	log          pkg.Log
 }
 
 func NewController(cfg boot.Config, stores domain.Stores, plugins domain.Plugins) *Controller {
-	return &Controller{
+	c := &Controller{
 		callbackAddr: cfg.Servers.IP + `:` + strconv.Itoa(cfg.Servers.DSP.HTTP.Port),
+		db:           plugins.Database,
 		cnStore:      stores.ContractNegotiationStore,
 		policyStore:  stores.OfferStore,
 		agrStore:     stores.AgreementStore,
+		catalog:      stores.ProviderCatalog,
 		urn:          plugins.URNService,
 		client:       plugins.Client,
 		log:          plugins.Log,
 	}
+
+	c.automation = automation{rules: cfg.Negotiation.Automation, cnStore: stores.ContractNegotiationStore,
+		agree: c.AgreeContract, finalize: c.FinalizeContract, log: plugins.Log}
+	c.automation.validate()
+	return c
 }
 
-func (c *Controller) OfferContract(offerId, providerPid, consumerAddr string) (cnId string, err error) {
+func (c *Controller) OfferContract(offerId, providerPid, consumerAddr, consumerId string,
+	constraints map[string]string) (cnId string, err error) {
 	// include datasetId (optional) if provider is the initiator
 
-	ofr, err := c.policyStore.Offer(offerId)
+	published, err := c.policyStore.Offer(offerId)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
 			return ``, errors.Client(errors.InvalidKey(stores.TypeOffer, `offer id`, err))
@@ -50,6 +62,22 @@ func (c *Controller) OfferContract(offerId, providerPid, consumerAddr string) (c
 		return ``, errors.StoreFailed(stores.TypeOffer, `Offer`, err)
 	}
 
+	ofr, err := c.setConstraints(published, constraints)
+	if err != nil {
+		return ``, errors.CustomFuncError(`setConstraints`, err)
+	}
+
+	// offer refers to the dataset publishing it, if it is not published by several datasets,
+	// so that the consumer can validate the target of the agreement
+	dsIds, err := c.catalog.DatasetsByOffer(offerId)
+	if err != nil {
+		return ``, errors.StoreFailed(stores.TypeProviderCatalog, `DatasetsByOffer`, err)
+	}
+
+	if tgt, ok := publishedTarget(dsIds, ofr); ok {
+		ofr.Target = tgt
+	}
+
 	var consumerPid, endpoint string
 	if providerPid != `` {
 		cn, err := c.cnStore.Negotiation(providerPid)
@@ -61,7 +89,7 @@ func (c *Controller) OfferContract(offerId, providerPid, consumerAddr string) (c
 			return ``, errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
 		}
 
-		if cn.State != negotiation.StateRequested {
+		if !negotiation.ValidTransition(cn.State, negotiation.StateOffered) {
 			return ``, errors.Client(errors.StateError(`offer contract`, string(cn.State)))
 		}
 
@@ -89,14 +117,19 @@ func (c *Controller) OfferContract(offerId, providerPid, consumerAddr string) (c
 				`must be provided when Provider is the initiator`))
 		}
 
+		if consumerId == `` {
+			return ``, errors.Client(errors.MissingAttrError(`consumer id`,
+				`must be provided when Provider is the initiator`))
+		}
+
 		providerPid, err = c.urn.NewURN()
 		if err != nil {
 			return ``, errors.PkgError(pkg.TypeURN, `NewURN`, err, `contract negotiation id`)
 		}
+		ofr.Assignee = odrl.Assignee(consumerId)
 		endpoint = negotiation.ContractOfferEndpoint
 	}
 
-	c.cnStore.SetParticipants(providerPid, consumerAddr, ofr.Assigner, ofr.Assignee)
 	req := negotiation.ContractOffer{
 		Ctx:          core.Context,
 		Type:         negotiation.MsgTypeContractOffer,
@@ -105,19 +138,49 @@ func (c *Controller) OfferContract(offerId, providerPid, consumerAddr string) (c
 		Offer:        ofr,
 		CallbackAddr: c.callbackAddr,
 	}
-	// todo offer must have a target but not in policies
 
-	ack, err := c.send(providerPid, endpoint, req)
+	ack, err := c.sendTo(consumerAddr, endpoint, req)
 	if err != nil {
-		return ``, errors.CustomFuncError(`send`, err)
+		return ``, errors.CustomFuncError(`sendTo`, err)
 	}
 
 	if !c.validAck(providerPid, ack, negotiation.StateOffered) {
 		return ``, errors.Client(errors.InvalidAckError(`ContractOffer`, ``, ack))
 	}
 
+	// participants, counterparty, offer and round are stored along with the negotiation so
+	// that they do not exist without the negotiation if the offer fails
 	ack.Type = negotiation.MsgTypeNegotiation
-	c.cnStore.AddNegotiation(providerPid, negotiation.Negotiation(ack))
+	if err = c.db.Transaction(func(tx pkg.Transaction) error {
+		cnStore := c.cnStore.WithTx(tx)
+		if err := cnStore.SetNegotiation(providerPid, negotiation.Negotiation(ack), consumerAddr,
+			ofr.Assigner, ofr.Assignee); err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `SetNegotiation`, err)
+		}
+
+		// messages of a negotiation initiated by the provider are accepted only from the consumer it
+		// was offered to
+		if consumerPid == `` {
+			if err := cnStore.SetCounterparty(providerPid, consumerId); err != nil {
+				return errors.StoreFailed(stores.TypeContractNegotiation, `SetCounterparty`, err)
+			}
+		}
+
+		if err := cnStore.SetOffer(providerPid, offerId); err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `SetOffer`, err)
+		}
+
+		if err := cnStore.AddRound(providerPid, negotiation.Round{
+			Proposer: negotiation.ProposerProvider,
+			Offer:    ofr,
+			Changes:  negotiation.Changes(ofr, published),
+		}); err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `AddRound`, err)
+		}
+		return nil
+	}); err != nil {
+		return ``, err
+	}
 
 	c.log.Info(fmt.Sprintf("provider controller updated negotiation state (id: %s, state: %s)",
 		providerPid, negotiation.StateOffered))
@@ -134,7 +197,12 @@ func (c *Controller) AgreeContract(offerId, providerPid string) (agreementId str
 		return ``, errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
 	}
 
-	if cn.State != negotiation.StateRequested && cn.State != negotiation.StateAccepted {
+	// agreement is sent again if it was stored but could not be delivered to the consumer
+	if cn.State == negotiation.StateAgreed {
+		return c.resendAgreement(cn)
+	}
+
+	if !negotiation.ValidTransition(cn.State, negotiation.StateAgreed) {
 		return ``, errors.Client(errors.StateError(`agree contract`, string(cn.State)))
 	}
 
@@ -143,12 +211,9 @@ func (c *Controller) AgreeContract(offerId, providerPid string) (agreementId str
 		return ``, errors.PkgError(pkg.TypeURN, `NewURN`, err, `contract negotiation id`)
 	}
 
-	offer, err := c.policyStore.Offer(offerId)
+	offer, err := c.agreedOffer(offerId, providerPid)
 	if err != nil {
-		if defaultErr.Is(err, stores.TypeInvalidKey) {
-			return ``, errors.Client(errors.InvalidKey(stores.TypeOffer, `offer id`, err))
-		}
-		return ``, errors.StoreFailed(stores.TypeOffer, `Offer`, err)
+		return ``, errors.CustomFuncError(`agreedOffer`, err)
 	}
 
 	assignee, err := c.cnStore.Assignee(providerPid)
@@ -156,40 +221,99 @@ func (c *Controller) AgreeContract(offerId, providerPid string) (agreementId str
 		return ``, errors.StoreFailed(stores.TypeContractNegotiation, `Assignee`, err)
 	}
 
+	target, err := c.target(offer)
+	if err != nil {
+		return ``, errors.CustomFuncError(`target`, err)
+	}
+
 	req := negotiation.ContractAgreement{
 		Ctx:     core.Context,
 		Type:    negotiation.MsgTypeContractAgreement,
 		ProvPId: cn.ProvPId,
 		ConsPId: cn.ConsPId,
 		Agreement: odrl.Agreement{
-			Id:          agreementId,
-			Type:        odrl.TypeAgreement,
-			Target:      offer.Target,
-			Assigner:    offer.Assigner,
-			Assignee:    assignee,
-			Timestamp:   time.Now().UTC().String(), // change format into XSD
-			Permissions: offer.Permissions,         // should be able to select a subset in future
+			Id:           agreementId,
+			Type:         odrl.TypeAgreement,
+			Target:       target,
+			Assigner:     offer.Assigner,
+			Assignee:     assignee,
+			Timestamp:    time.Now().UTC().String(), // change format into XSD
+			Permissions:  offer.Permissions,         // should be able to select a subset in future
+			Prohibitions: offer.Prohibitions,
+			Obligations:  offer.Obligations,
 		},
 		CallbackAddr: c.callbackAddr,
 	}
-	// todo agreement must have a target but not in policies
+
+	// agreement is stored before it is sent since the consumer may verify the agreement
+	// before the response is received. It is stored along with the agreed offer and state
+	// only if the negotiation was not modified after it was read, so that a conflicting
+	// update does not leave an agreement which is not referred by the negotiation.
+	if err = c.db.Transaction(func(tx pkg.Transaction) error {
+		cnStore := c.cnStore.WithTx(tx)
+		cur, err := cnStore.Negotiation(providerPid)
+		if err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
+		}
+
+		if cur != cn {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`,
+				stores.Conflict(providerPid))
+		}
+
+		if err = c.agrStore.WithTx(tx).AddAgreement(providerPid, req.Agreement); err != nil {
+			return errors.StoreFailed(stores.TypeAgreement, `AddAgreement`, err)
+		}
+
+		// agreed offer may differ from the offer requested by the consumer
+		if err = cnStore.SetOffer(providerPid, offer.Id); err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `SetOffer`, err)
+		}
+
+		if err = cnStore.UpdateState(providerPid, negotiation.StateAgreed); err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `UpdateState`, err)
+		}
+		return nil
+	}); err != nil {
+		return ``, err
+	}
+
+	c.log.Trace(fmt.Sprintf("stored contract agreement (id: %s) for negotation (id: %s)",
+		req.Agreement.Id, providerPid))
+	c.log.Debug(fmt.Sprintf("provider controller updated negotiation state (id: %s, state: %s)",
+		providerPid, negotiation.StateAgreed))
 
 	if _, err = c.send(providerPid, api.SetParamConsumerPid(negotiation.ContractAgreementEndpoint,
 		cn.ConsPId), req); err != nil {
 		return ``, errors.CustomFuncError(`send`, err)
 	}
+	return agreementId, nil
+}
+
+// resendAgreement sends the stored agreement of the negotiation to the consumer
+func (c *Controller) resendAgreement(cn negotiation.Negotiation) (agreementId string, err error) {
+	agr, err := c.agrStore.AgreementByNegotiationID(cn.ProvPId)
+	if err != nil {
+		return ``, errors.StoreFailed(stores.TypeAgreement, `AgreementByNegotiationID`, err)
+	}
 
-	c.agrStore.AddAgreement(req.Agreement.Id, req.Agreement)
-	c.log.Trace(fmt.Sprintf("stored contract agreement (id: %s) for negotation (id: %s)",
-		req.Agreement.Id, providerPid))
+	req := negotiation.ContractAgreement{
+		Ctx:          core.Context,
+		Type:         negotiation.MsgTypeContractAgreement,
+		ProvPId:      cn.ProvPId,
+		ConsPId:      cn.ConsPId,
+		Agreement:    agr,
+		CallbackAddr: c.callbackAddr,
+	}
 
-	if err = c.cnStore.UpdateState(providerPid, negotiation.StateAgreed); err != nil {
-		return ``, errors.StoreFailed(stores.TypeContractNegotiation, `UpdateState`, err)
+	if _, err = c.send(cn.ProvPId, api.SetParamConsumerPid(negotiation.ContractAgreementEndpoint,
+		cn.ConsPId), req); err != nil {
+		return ``, errors.CustomFuncError(`send`, err)
 	}
 
-	c.log.Debug(fmt.Sprintf("provider controller updated negotiation state (id: %s, state: %s)",
-		providerPid, negotiation.StateAgreed))
-	return agreementId, nil
+	c.log.Debug(fmt.Sprintf("provider controller sent the stored agreement again (id: %s, agreement: %s)",
+		cn.ProvPId, agr.Id))
+	return agr.Id, nil
 }
 
 func (c *Controller) FinalizeContract(providerPid string) error {
@@ -202,7 +326,7 @@ func (c *Controller) FinalizeContract(providerPid string) error {
 		return errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
 	}
 
-	if cn.State != negotiation.StateVerified {
+	if !negotiation.ValidTransition(cn.State, negotiation.StateFinalized) {
 		return errors.Client(errors.StateError(`finalize contract`, string(cn.State)))
 	}
 
@@ -227,12 +351,109 @@ func (c *Controller) FinalizeContract(providerPid string) error {
 	return nil
 }
 
+// agreedOffer returns the published offer if the offer ID is provided, and the offer
+// proposed in the latest round of the negotiation otherwise, i.e. the request of the
+// consumer or the counter-offer accepted by the consumer. An offer without a target
+// refers to the dataset requested in the latest round which specifies a target.
+func (c *Controller) agreedOffer(offerId, providerPid string) (odrl.Offer, error) {
+	rounds, err := c.cnStore.Rounds(providerPid)
+	if err != nil && !defaultErr.Is(err, stores.TypeInvalidKey) {
+		return odrl.Offer{}, errors.StoreFailed(stores.TypeContractNegotiation, `Rounds`, err)
+	}
+
+	var ofr odrl.Offer
+	if offerId != `` {
+		ofr, err = c.policyStore.Offer(offerId)
+		if err != nil {
+			if defaultErr.Is(err, stores.TypeInvalidKey) {
+				return odrl.Offer{}, errors.Client(errors.InvalidKey(stores.TypeOffer, `offer id`, err))
+			}
+			return odrl.Offer{}, errors.StoreFailed(stores.TypeOffer, `Offer`, err)
+		}
+		// since the datasets publishing the offer represent its target
+		ofr.Target = ``
+	} else {
+		if len(rounds) == 0 {
+			return odrl.Offer{}, errors.Client(errors.MissingAttrError(`offer id`,
+				`no offer has been proposed during the negotiation`))
+		}
+		ofr = rounds[len(rounds)-1].Offer
+	}
+
+	for i := len(rounds) - 1; i >= 0 && ofr.Target == ``; i-- {
+		ofr.Target = rounds[i].Offer.Target
+	}
+	return ofr, nil
+}
+
+// setConstraints replaces the right operands of the constraints of the permissions in
+// the published offer by the values provided for their left operands
+func (c *Controller) setConstraints(published odrl.Offer, vals map[string]string) (odrl.Offer, error) {
+	ofr := published
+	ofr.Permissions = nil
+	used := make(map[string]bool)
+	for _, perm := range published.Permissions {
+		var consList []odrl.Constraint
+		for _, cons := range perm.Constraints {
+			if val, ok := vals[cons.LeftOperand]; ok && cons.LeftOperand != `` {
+				cons.RightOperand = odrl.TypedLiteral(val, cons.RightOperand.Type)
+				used[cons.LeftOperand] = true
+			}
+			consList = append(consList, cons)
+		}
+
+		perm.Constraints = consList
+		ofr.Permissions = append(ofr.Permissions, perm)
+	}
+
+	for leftOperand := range vals {
+		if !used[leftOperand] {
+			return odrl.Offer{}, errors.Client(errors.IncorrectReqValues(fmt.Sprintf(
+				"offer does not contain a constraint for the left operand '%s'", leftOperand)))
+		}
+	}
+	return ofr, nil
+}
+
+// target returns the dataset which publishes the agreed offer, so that the agreement never
+// refers to a dataset which is only proposed by the consumer
+func (c *Controller) target(ofr odrl.Offer) (odrl.Target, error) {
+	dsIds, err := c.catalog.DatasetsByOffer(ofr.Id)
+	if err != nil {
+		return ``, errors.StoreFailed(stores.TypeProviderCatalog, `DatasetsByOffer`, err)
+	}
+
+	tgt, ok := publishedTarget(dsIds, ofr)
+	if !ok {
+		return ``, errors.Client(errors.InvalidValue(`target`, strings.Join(dsIds, `, `), string(ofr.Target)))
+	}
+	return tgt, nil
+}
+
+// publishedTarget returns the target of the offer if it is one of the datasets publishing
+// the offer, or the dataset publishing the offer if the offer does not specify a target and
+// is published by a single dataset
+func publishedTarget(datasetIds []string, ofr odrl.Offer) (odrl.Target, bool) {
+	for _, id := range datasetIds {
+		if ofr.Target == odrl.Target(id) || (ofr.Target == `` && len(datasetIds) == 1) {
+			return odrl.Target(id), true
+		}
+	}
+	return ``, false
+}
+
 func (c *Controller) send(providerPid, endpoint string, req any) (negotiation.Ack, error) {
 	consumerAddr, err := c.cnStore.CallbackAddr(providerPid)
 	if err != nil {
 		return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `CallbackAddr`, err)
 	}
 
+	return c.sendTo(consumerAddr, endpoint, req)
+}
+
+// sendTo sends the request to the given address of the consumer, which is used when the
+// address is not stored yet
+func (c *Controller) sendTo(consumerAddr, endpoint string, req any) (negotiation.Ack, error) {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return negotiation.Ack{}, errors.Client(errors.MarshalError(``, err))
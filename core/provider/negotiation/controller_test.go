@@ -0,0 +1,92 @@
+package negotiation
+
+import (
+	defaultErr "errors"
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
+	"github.com/YasiruR/connector/domain/boot"
+	"github.com/YasiruR/connector/domain/models/dcat"
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"github.com/YasiruR/connector/domain/stores"
+	"github.com/YasiruR/connector/pkg/database/memory"
+	"github.com/YasiruR/connector/pkg/log"
+	pkgPolicy "github.com/YasiruR/connector/pkg/policy"
+	"github.com/YasiruR/connector/pkg/urn"
+	"github.com/YasiruR/connector/stores/catalog"
+	"github.com/YasiruR/connector/stores/policy"
+	"github.com/YasiruR/connector/stores/protocol"
+	"sync"
+	"testing"
+)
+
+// racingStore terminates the negotiation once it is read for the first time, as if the
+// consumer terminated it concurrently
+type racingStore struct {
+	stores.ContractNegotiationStore
+	once sync.Once
+}
+
+func (r *racingStore) Negotiation(cnId string) (negotiation.Negotiation, error) {
+	cn, err := r.ContractNegotiationStore.Negotiation(cnId)
+	r.once.Do(func() { _ = r.UpdateState(cnId, negotiation.StateTerminated) })
+	return cn, err
+}
+
+func TestController_AgreeContract(t *testing.T) {
+	l := log.NewLogger()
+	plugins := domain.Plugins{Database: memory.NewStore(l), URNService: urn.NewGenerator(),
+		PolicyEngine: pkgPolicy.NewEngine(l), Client: consumerClient{}, Log: l}
+	s := domain.Stores{
+		ProviderCatalog:          catalog.NewProviderCatalog(boot.Config{}, plugins),
+		OfferStore:               policy.NewOfferStore(plugins),
+		ContractNegotiationStore: protocol.NewContractNegotiationStore(plugins),
+		AgreementStore:           policy.NewAgreementStore(plugins),
+	}
+
+	ofr := odrl.Offer{Id: `offer`, Assigner: `provider`, Permissions: []odrl.Rule{{Action: `use`}}}
+	s.OfferStore.AddOffer(ofr.Id, ofr)
+	if err := s.ProviderCatalog.AddDataset(``, `dataset`, dcat.Dataset{ID: `dataset`,
+		OdrlHasPolicy: []odrl.Offer{ofr}}, nil); err != nil {
+		t.Fatalf("AddDataset failed - %s", err)
+	}
+
+	c := NewController(boot.Config{}, s, plugins)
+	h := NewHandler(boot.Config{}, s, plugins, c)
+	request := func() string {
+		proposed := ofr
+		proposed.Target, proposed.Assignee = `dataset`, `consumer`
+		ack, err := h.HandleContractRequest(negotiation.ContractRequest{ConsPId: `consumer-pid`,
+			Offer: proposed, CallbackAddr: `http://localhost:8080`}, `consumer`)
+		if err != nil {
+			t.Fatalf("HandleContractRequest failed - %s", err)
+		}
+		return ack.ProvPId
+	}
+
+	// agreement is not stored if the negotiation is modified while it is being agreed upon
+	raced := request()
+	c.cnStore = &racingStore{ContractNegotiationStore: s.ContractNegotiationStore}
+	if _, err := c.AgreeContract(``, raced); !defaultErr.Is(err, stores.TypeConflict) {
+		t.Errorf("AgreeContract of a concurrently terminated negotiation returned %v, want a conflict", err)
+	}
+
+	if agr, err := s.AgreementStore.AgreementByNegotiationID(raced); !defaultErr.Is(err, stores.TypeInvalidKey) {
+		t.Errorf("agreement (%s) of a concurrently terminated negotiation was stored (error: %v)", agr.Id, err)
+	}
+
+	if state, err := s.ContractNegotiationStore.State(raced); err != nil || state != negotiation.StateTerminated {
+		t.Errorf("state of the concurrently terminated negotiation is %s (error: %v)", state, err)
+	}
+
+	// agreeing again sends the stored agreement instead of creating another
+	c.cnStore = s.ContractNegotiationStore
+	cnId := request()
+	agrId, err := c.AgreeContract(``, cnId)
+	if err != nil {
+		t.Fatalf("AgreeContract failed - %s", err)
+	}
+
+	if resent, err := c.AgreeContract(``, cnId); err != nil || resent != agrId {
+		t.Errorf("AgreeContract of an agreed negotiation returned %s (error: %v), want %s", resent, err, agrId)
+	}
+}
@@ -8,25 +8,35 @@ import (
 	"github.com/YasiruR/connector/domain/boot"
 	"github.com/YasiruR/connector/domain/core"
 	"github.com/YasiruR/connector/domain/errors"
-	"github.com/YasiruR/connector/domain/models/odrl"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
+	"strings"
 )
 
 type Handler struct {
 	assignerId  string
+	db          pkg.Database
 	cnStore     stores.ContractNegotiationStore
 	policyStore stores.OfferStore
+	catalog     stores.ProviderCatalog
 	urn         pkg.URNService
+	policy      pkg.PolicyEngine
+	automation  automation
 	log         pkg.Log
 }
 
-func NewHandler(cfg boot.Config, stores domain.Stores, plugins domain.Plugins) *Handler {
+// NewHandler requires the controller of the provider since the handler triggers the
+// steps of the negotiation automated by the controller
+func NewHandler(cfg boot.Config, stores domain.Stores, plugins domain.Plugins, c *Controller) *Handler {
 	return &Handler{
 		assignerId:  cfg.DataSpace.AssignerId,
+		db:          plugins.Database,
 		cnStore:     stores.ContractNegotiationStore,
 		policyStore: stores.OfferStore,
+		catalog:     stores.ProviderCatalog,
 		urn:         plugins.URNService,
+		policy:      plugins.PolicyEngine,
+		automation:  c.automation,
 		log:         plugins.Log,
 	}
 }
@@ -44,11 +54,13 @@ func (h *Handler) HandleNegotiationsRequest(providerPid string) (negotiation.Ack
 	return negotiation.Ack(ack), nil
 }
 
-func (h *Handler) HandleContractRequest(cr negotiation.ContractRequest) (ack negotiation.Ack, err error) {
+func (h *Handler) HandleContractRequest(cr negotiation.ContractRequest,
+	participantId string) (ack negotiation.Ack, err error) {
 	// associate with existing contract negotiation if providerPid exists and create a new contract
 	// negotiation if otherwise
 	var cn negotiation.Negotiation
-	provPId := cr.ProvPId
+	var curState negotiation.State
+	provPId, callbackAddr := cr.ProvPId, cr.CallbackAddr
 	if provPId != `` {
 		cn, err = h.cnStore.Negotiation(provPId)
 		if err != nil {
@@ -59,16 +71,30 @@ func (h *Handler) HandleContractRequest(cr negotiation.ContractRequest) (ack neg
 			return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
 		}
 
-		if cn.State != negotiation.StateOffered {
-			return negotiation.Ack{}, errors.Negotiation(cn.ProvPId,
-				cn.ConsPId, errors.StateError(`request contract`, string(cn.State)))
-		}
-
 		if cn.ConsPId != cr.ConsPId {
 			return negotiation.Ack{}, errors.Negotiation(cn.ProvPId, cn.ConsPId,
 				errors.InvalidValue(`consumerPid`, cn.ConsPId, cr.ConsPId))
 		}
 
+		// a request of an existing negotiation must be sent by its assignee, unless the provider
+		// offered the contract without an assignee, and the callback address of the consumer
+		// can not be replaced by the request
+		assignee, err := h.cnStore.Assignee(provPId)
+		if err != nil {
+			return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `Assignee`, err)
+		}
+
+		if assignee != `` && string(assignee) != participantId {
+			return negotiation.Ack{}, errors.Negotiation(cn.ProvPId, cn.ConsPId,
+				errors.InvalidValue(`participant`, string(assignee), participantId))
+		}
+
+		callbackAddr, err = h.cnStore.CallbackAddr(provPId)
+		if err != nil {
+			return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `CallbackAddr`, err)
+		}
+
+		curState = cn.State
 		cn.State = negotiation.StateRequested
 		h.log.Debug("a valid contract negotiation exists", cn.ProvPId)
 	} else {
@@ -86,30 +112,100 @@ func (h *Handler) HandleContractRequest(cr negotiation.ContractRequest) (ack neg
 		}
 	}
 
-	// return error message if the offer is invalid
-	if !h.validOffer(cr.Offer) {
-		return negotiation.Ack{}, fmt.Errorf("received an invalid offer")
+	// reject the request if the offer weakens or alters the published offer
+	storedOfr, err := h.policyStore.Offer(cr.Offer.Id)
+	if err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return negotiation.Ack{}, errors.Negotiation(provPId, cr.ConsPId,
+				errors.InvalidKey(stores.TypeOffer, `offer id`, err))
+		}
+		return negotiation.Ack{}, errors.StoreFailed(stores.TypeOffer, `Offer`, err)
+	}
+
+	// offer must refer to a dataset which publishes it, since the agreement is bound to its target
+	dsIds, err := h.catalog.DatasetsByOffer(cr.Offer.Id)
+	if err != nil {
+		return negotiation.Ack{}, errors.StoreFailed(stores.TypeProviderCatalog, `DatasetsByOffer`, err)
+	}
+
+	tgt, ok := publishedTarget(dsIds, cr.Offer)
+	if !ok || tgt != cr.Offer.Target {
+		return negotiation.Ack{}, errors.Negotiation(provPId, cr.ConsPId,
+			errors.InvalidValue(`target`, strings.Join(dsIds, `, `), string(cr.Offer.Target)))
+	}
+
+	// offers are stored without a target since it is represented by the dataset
+	storedOfr.Target = tgt
+
+	if err = h.policy.ValidateOffer(cr.Offer, storedOfr); err != nil {
+		return negotiation.Ack{}, errors.Negotiation(provPId, cr.ConsPId, errors.PolicyViolated(err))
+	}
+
+	// agreement can only be assigned to the participant requesting the contract
+	if string(cr.Offer.Assignee) != participantId {
+		return negotiation.Ack{}, errors.Negotiation(provPId, cr.ConsPId,
+			errors.InvalidValue(`assignee`, participantId, string(cr.Offer.Assignee)))
 	}
 
 	// return error message if callback address is invalid
-	if !h.validAddress(cr.CallbackAddr) {
+	if !h.validAddress(callbackAddr) {
 		return negotiation.Ack{}, fmt.Errorf("received an invalid callback address")
 	}
 
+	// proposed offer is recorded so that the provider can agree to the exact terms or
+	// respond with a counter-offer
+	round := negotiation.Round{
+		Proposer: negotiation.ProposerConsumer,
+		Offer:    cr.Offer,
+		Changes:  negotiation.Changes(cr.Offer, storedOfr),
+	}
+
 	// store (new or updated) contract negotiation, assignee, assigner and its callback address
-	h.cnStore.AddNegotiation(provPId, cn)
-	h.cnStore.SetParticipants(provPId, cr.CallbackAddr, cr.Offer.Assigner, cr.Offer.Assignee)
+	// along with the counterparty, offer and round so that a failed write does not leave a
+	// negotiation without any of them
+	if err = h.db.Transaction(func(tx pkg.Transaction) error {
+		cnStore := h.cnStore.WithTx(tx)
+		if err := cnStore.SetNegotiation(provPId, cn, callbackAddr, cr.Offer.Assigner,
+			cr.Offer.Assignee); err != nil {
+			switch {
+			case defaultErr.Is(err, stores.TypeInvalidTransition):
+				return errors.Negotiation(provPId, cr.ConsPId, errors.StateError(`request contract`,
+					string(curState)))
+			case defaultErr.Is(err, stores.TypeConflict):
+				return errors.Negotiation(provPId, cr.ConsPId, errors.Conflict(`request contract`, err))
+			default:
+				return errors.StoreFailed(stores.TypeContractNegotiation, `SetNegotiation`, err)
+			}
+		}
+
+		if err := cnStore.SetCounterparty(provPId, participantId); err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `SetCounterparty`, err)
+		}
+
+		if err := cnStore.SetOffer(provPId, cr.Offer.Id); err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `SetOffer`, err)
+		}
+
+		if err := cnStore.AddRound(provPId, round); err != nil {
+			return errors.StoreFailed(stores.TypeContractNegotiation, `AddRound`, err)
+		}
+		return nil
+	}); err != nil {
+		return negotiation.Ack{}, err
+	}
 
 	h.log.Trace(fmt.Sprintf("provider stored contract negotiation (id: %s, assigner: %s, assignee: %s, address: %s)",
-		provPId, cr.Offer.Assigner, cr.Offer.Assignee, cr.CallbackAddr))
+		provPId, cr.Offer.Assigner, cr.Offer.Assignee, callbackAddr))
 	h.log.Debug(fmt.Sprintf("provider handler updated negotiation state (id: %s, state: %s)",
 		provPId, negotiation.StateRequested))
 
+	h.automation.onRequest(provPId, round, participantId)
 	cn.Type = negotiation.MsgTypeNegotiationAck
 	return negotiation.Ack(cn), nil
 }
 
-func (h *Handler) HandleAcceptOffer(e negotiation.ContractNegotiationEvent) (negotiation.Ack, error) {
+func (h *Handler) HandleAcceptOffer(e negotiation.ContractNegotiationEvent,
+	participantId string) (negotiation.Ack, error) {
 	// validate other attributes (consPid)
 
 	cn, err := h.cnStore.Negotiation(e.ProvPId)
@@ -121,23 +217,34 @@ func (h *Handler) HandleAcceptOffer(e negotiation.ContractNegotiationEvent) (neg
 		return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
 	}
 
-	if cn.State != negotiation.StateOffered {
-		return negotiation.Ack{}, errors.Negotiation(cn.ProvPId,
-			cn.ConsPId, errors.StateError(`accept offer`, string(cn.State)))
+	if err = h.validateCounterparty(cn, participantId); err != nil {
+		return negotiation.Ack{}, err
 	}
 
 	if err = h.cnStore.UpdateState(e.ProvPId, negotiation.StateAccepted); err != nil {
-		return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `UpdateState`, err)
+		switch {
+		case defaultErr.Is(err, stores.TypeInvalidTransition):
+			return negotiation.Ack{}, errors.Negotiation(cn.ProvPId,
+				cn.ConsPId, errors.StateError(`accept offer`, string(cn.State)))
+		case defaultErr.Is(err, stores.TypeConflict):
+			return negotiation.Ack{}, errors.Negotiation(cn.ProvPId,
+				cn.ConsPId, errors.Conflict(`accept offer`, err))
+		default:
+			return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `UpdateState`, err)
+		}
 	}
 
 	cn.State = negotiation.StateAccepted
 	cn.Type = negotiation.MsgTypeNegotiationAck
 	h.log.Debug(fmt.Sprintf("provider handler updated negotiation state (id: %s, state: %s)",
 		e.ProvPId, negotiation.StateAccepted))
+
+	h.automation.onAccept(e.ProvPId)
 	return negotiation.Ack(cn), nil
 }
 
-func (h *Handler) HandleAgreementVerification(cv negotiation.ContractVerification) (negotiation.Ack, error) {
+func (h *Handler) HandleAgreementVerification(cv negotiation.ContractVerification,
+	participantId string) (negotiation.Ack, error) {
 	// validate message (must contain consumerPid, providerPid)
 
 	cn, err := h.cnStore.Negotiation(cv.ProvPId)
@@ -149,23 +256,34 @@ func (h *Handler) HandleAgreementVerification(cv negotiation.ContractVerificatio
 		return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
 	}
 
-	if cn.State != negotiation.StateAgreed {
-		return negotiation.Ack{}, errors.Negotiation(cn.ProvPId,
-			cn.ConsPId, errors.StateError(`verify agreement`, string(cn.State)))
+	if err = h.validateCounterparty(cn, participantId); err != nil {
+		return negotiation.Ack{}, err
 	}
 
 	if err = h.cnStore.UpdateState(cv.ProvPId, negotiation.StateVerified); err != nil {
-		return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `UpdateState`, err)
+		switch {
+		case defaultErr.Is(err, stores.TypeInvalidTransition):
+			return negotiation.Ack{}, errors.Negotiation(cn.ProvPId,
+				cn.ConsPId, errors.StateError(`verify agreement`, string(cn.State)))
+		case defaultErr.Is(err, stores.TypeConflict):
+			return negotiation.Ack{}, errors.Negotiation(cn.ProvPId,
+				cn.ConsPId, errors.Conflict(`verify agreement`, err))
+		default:
+			return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `UpdateState`, err)
+		}
 	}
 
 	cn.State = negotiation.StateVerified
 	cn.Type = negotiation.MsgTypeNegotiationAck
 	h.log.Debug(fmt.Sprintf("provider handler updated negotiation state (id: %s, state: %s)",
 		cv.ProvPId, negotiation.StateVerified))
+
+	h.automation.onVerification(cv.ProvPId)
 	return negotiation.Ack(cn), nil
 }
 
-func (h *Handler) HandleContractTermination(ct negotiation.ContractTermination) (negotiation.Ack, error) {
+func (h *Handler) HandleContractTermination(ct negotiation.ContractTermination,
+	participantId string) (negotiation.Ack, error) {
 	cn, err := h.cnStore.Negotiation(ct.ProvPId)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -175,9 +293,22 @@ func (h *Handler) HandleContractTermination(ct negotiation.ContractTermination)
 		return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
 	}
 
+	if err = h.validateCounterparty(cn, participantId); err != nil {
+		return negotiation.Ack{}, err
+	}
+
 	// can clear stores instead of this
 	if err = h.cnStore.UpdateState(ct.ProvPId, negotiation.StateTerminated); err != nil {
-		return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `UpdateState`, err)
+		switch {
+		case defaultErr.Is(err, stores.TypeInvalidTransition):
+			return negotiation.Ack{}, errors.Negotiation(cn.ProvPId,
+				cn.ConsPId, errors.StateError(`terminate contract`, string(cn.State)))
+		case defaultErr.Is(err, stores.TypeConflict):
+			return negotiation.Ack{}, errors.Negotiation(cn.ProvPId,
+				cn.ConsPId, errors.Conflict(`terminate contract`, err))
+		default:
+			return negotiation.Ack{}, errors.StoreFailed(stores.TypeContractNegotiation, `UpdateState`, err)
+		}
 	}
 
 	cn.State = negotiation.StateTerminated
@@ -186,23 +317,20 @@ func (h *Handler) HandleContractTermination(ct negotiation.ContractTermination)
 	return negotiation.Ack(cn), nil
 }
 
-// todo move policy engine validator as a package
-func (h *Handler) validOffer(receivedOfr odrl.Offer) bool {
-	storedOfr, err := h.policyStore.Offer(receivedOfr.Id)
-	if err != nil {
-		// todo handle invalid key error
-		h.log.Debug(errors.StoreFailed(stores.TypeOffer, `Offer`, err))
-		return false
+// validateCounterparty rejects a message of the negotiation unless it is sent by the
+// consumer which requested the contract, including when the provider offered the contract
+// and the consumer has not requested it yet
+func (h *Handler) validateCounterparty(cn negotiation.Negotiation, participantId string) error {
+	counterparty, err := h.cnStore.Counterparty(cn.ProvPId)
+	if err != nil && !defaultErr.Is(err, stores.TypeInvalidKey) {
+		return errors.StoreFailed(stores.TypeContractNegotiation, `Counterparty`, err)
 	}
 
-	if receivedOfr.Assigner != storedOfr.Assigner {
-		h.log.Debug("assigner in the received offer did not match with the stored offer",
-			"received:"+receivedOfr.Assigner, "stored:"+storedOfr.Assigner)
-		return false
+	if counterparty == `` || counterparty != participantId {
+		return errors.Negotiation(cn.ProvPId, cn.ConsPId, errors.InvalidValue(`participant`, counterparty,
+			participantId))
 	}
-
-	// validate rules
-	return true
+	return nil
 }
 
 func (h *Handler) validAddress(addr string) bool {
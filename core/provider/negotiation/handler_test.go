@@ -0,0 +1,216 @@
+package negotiation
+
+import (
+	"encoding/json"
+	defaultErr "errors"
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
+	"github.com/YasiruR/connector/domain/boot"
+	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/models/dcat"
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"github.com/YasiruR/connector/domain/stores"
+	"github.com/YasiruR/connector/pkg/database/memory"
+	"github.com/YasiruR/connector/pkg/log"
+	pkgPolicy "github.com/YasiruR/connector/pkg/policy"
+	"github.com/YasiruR/connector/pkg/urn"
+	"github.com/YasiruR/connector/stores/catalog"
+	"github.com/YasiruR/connector/stores/policy"
+	"github.com/YasiruR/connector/stores/protocol"
+	"testing"
+)
+
+// consumerClient acknowledges the contract offers sent by the provider on behalf of the consumer
+type consumerClient struct{}
+
+func (consumerClient) Send(data []byte, _ any) ([]byte, error) {
+	var co negotiation.ContractOffer
+	if err := json.Unmarshal(data, &co); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(negotiation.Ack{ProvPId: co.ProvPId, ConsPId: `consumer-pid`,
+		State: negotiation.StateOffered})
+}
+
+func (consumerClient) SendPage(data []byte, destination any) ([]byte, any, error) {
+	return nil, nil, nil
+}
+
+func TestHandler_Counterparty(t *testing.T) {
+	l := log.NewLogger()
+	plugins := domain.Plugins{Database: memory.NewStore(l), URNService: urn.NewGenerator(),
+		Client: consumerClient{}, Log: l}
+	s := domain.Stores{
+		ProviderCatalog:          catalog.NewProviderCatalog(boot.Config{}, plugins),
+		OfferStore:               policy.NewOfferStore(plugins),
+		ContractNegotiationStore: protocol.NewContractNegotiationStore(plugins),
+	}
+	cnStore := s.ContractNegotiationStore
+	h := &Handler{cnStore: cnStore, automation: automation{cnStore: cnStore, log: l}, log: l}
+
+	// bound is a negotiation requested by the consumer whereas offered is initiated by the provider
+	cn := negotiation.Negotiation{ProvPId: `bound`, ConsPId: `consumer-pid`, State: negotiation.StateOffered}
+	if err := cnStore.AddNegotiation(cn.ProvPId, cn); err != nil {
+		t.Fatalf("AddNegotiation failed - %s", err)
+	}
+
+	if err := cnStore.SetCounterparty(cn.ProvPId, `consumer`); err != nil {
+		t.Fatalf("SetCounterparty failed - %s", err)
+	}
+
+	ofr := odrl.Offer{Id: `offer`, Assigner: `provider`, Permissions: []odrl.Rule{{Action: `use`}}}
+	s.OfferStore.AddOffer(ofr.Id, ofr)
+	if err := s.ProviderCatalog.AddDataset(``, `dataset`, dcat.Dataset{ID: `dataset`,
+		OdrlHasPolicy: []odrl.Offer{ofr}}, nil); err != nil {
+		t.Fatalf("AddDataset failed - %s", err)
+	}
+
+	c := NewController(boot.Config{}, s, plugins)
+	if _, err := c.OfferContract(ofr.Id, ``, `http://localhost:8080`, ``, nil); err == nil {
+		t.Errorf("OfferContract initiated a negotiation without the participant ID of the consumer")
+	}
+
+	offered, err := c.OfferContract(ofr.Id, ``, `http://localhost:8080`, `consumer`, nil)
+	if err != nil {
+		t.Fatalf("OfferContract failed - %s", err)
+	}
+
+	// offer refers to the dataset publishing it so that the consumer can validate the agreement
+	if rounds, err := cnStore.Rounds(offered); err != nil || len(rounds) != 1 || rounds[0].Offer.Target != `dataset` {
+		t.Errorf("OfferContract recorded the rounds %v (error: %v), want an offer of the dataset", rounds, err)
+	}
+
+	handlers := map[string]func(provPid, participantId string) error{
+		`HandleAcceptOffer`: func(provPid, participantId string) error {
+			_, err := h.HandleAcceptOffer(negotiation.ContractNegotiationEvent{ProvPId: provPid,
+				ConsPId: `consumer-pid`, EventType: negotiation.EventAccepted}, participantId)
+			return err
+		},
+		`HandleAgreementVerification`: func(provPid, participantId string) error {
+			_, err := h.HandleAgreementVerification(negotiation.ContractVerification{ProvPId: provPid,
+				ConsPId: `consumer-pid`}, participantId)
+			return err
+		},
+		`HandleContractTermination`: func(provPid, participantId string) error {
+			_, err := h.HandleContractTermination(negotiation.ContractTermination{ProvPId: provPid,
+				ConsPId: `consumer-pid`}, participantId)
+			return err
+		},
+	}
+
+	for name, handle := range handlers {
+		for _, id := range []string{`bound`, offered} {
+			if err = handle(id, `other`); err == nil {
+				t.Errorf("%s accepted a message from another participant (negotiation: %s)", name, id)
+			}
+		}
+	}
+
+	for _, id := range []string{`bound`, offered} {
+		if state, err := cnStore.State(id); err != nil || state != negotiation.StateOffered {
+			t.Errorf("state of the negotiation (%s) was updated by rejected messages (state: %s, error: %v)",
+				id, state, err)
+		}
+
+		if err = handlers[`HandleAcceptOffer`](id, `consumer`); err != nil {
+			t.Errorf("HandleAcceptOffer rejected a message from the counterparty (negotiation: %s) - %s", id, err)
+		}
+	}
+}
+
+func TestHandler_Target(t *testing.T) {
+	l := log.NewLogger()
+	plugins := domain.Plugins{Database: memory.NewStore(l), URNService: urn.NewGenerator(),
+		PolicyEngine: pkgPolicy.NewEngine(l), Log: l}
+	s := domain.Stores{
+		ProviderCatalog:          catalog.NewProviderCatalog(boot.Config{}, plugins),
+		OfferStore:               policy.NewOfferStore(plugins),
+		ContractNegotiationStore: protocol.NewContractNegotiationStore(plugins),
+		AgreementStore:           policy.NewAgreementStore(plugins),
+	}
+
+	ofr := odrl.Offer{Id: `offer`, Assigner: `provider`, Permissions: []odrl.Rule{{Action: `use`}}}
+	s.OfferStore.AddOffer(ofr.Id, ofr)
+	for id, ofrs := range map[string][]odrl.Offer{`dataset`: {ofr}, `other`: nil} {
+		if err := s.ProviderCatalog.AddDataset(``, id, dcat.Dataset{ID: id, OdrlHasPolicy: ofrs}, nil); err != nil {
+			t.Fatalf("AddDataset failed - %s", err)
+		}
+	}
+
+	c := NewController(boot.Config{}, s, plugins)
+	h := NewHandler(boot.Config{}, s, plugins, c)
+	proposed := func(target odrl.Target) odrl.Offer {
+		o := ofr
+		o.Target, o.Assignee = target, `consumer`
+		return o
+	}
+
+	for target, valid := range map[odrl.Target]bool{`dataset`: true, `other`: false, ``: false} {
+		_, err := h.HandleContractRequest(negotiation.ContractRequest{ConsPId: `consumer-pid`,
+			Offer: proposed(target), CallbackAddr: `http://localhost:8080`}, `consumer`)
+		if (err == nil) != valid {
+			t.Errorf("HandleContractRequest with the target '%s' returned %v, want valid: %t", target, err, valid)
+		}
+	}
+
+	// agreement refers to the dataset publishing the offer irrespective of the proposed target
+	for target, valid := range map[odrl.Target]bool{`dataset`: true, `other`: false, ``: true} {
+		tgt, err := c.target(proposed(target))
+		if valid && (err != nil || tgt != `dataset`) {
+			t.Errorf("target of the offer proposed for '%s' returned (%s, %v), want the dataset", target, tgt, err)
+		}
+
+		if !valid && err == nil {
+			t.Errorf("target of the offer proposed for '%s' returned %s, want an error", target, tgt)
+		}
+	}
+}
+
+// conflictingStore rejects state updates as if the negotiation was modified concurrently
+type conflictingStore struct {
+	stores.ContractNegotiationStore
+}
+
+func (conflictingStore) UpdateState(cnId string, _ negotiation.State) error {
+	return stores.Conflict(cnId)
+}
+
+func TestHandler_Conflict(t *testing.T) {
+	l := log.NewLogger()
+	plugins := domain.Plugins{Database: memory.NewStore(l), URNService: urn.NewGenerator(),
+		PolicyEngine: pkgPolicy.NewEngine(l), Log: l}
+	s := domain.Stores{
+		ProviderCatalog:          catalog.NewProviderCatalog(boot.Config{}, plugins),
+		OfferStore:               policy.NewOfferStore(plugins),
+		ContractNegotiationStore: protocol.NewContractNegotiationStore(plugins),
+		AgreementStore:           policy.NewAgreementStore(plugins),
+	}
+
+	ofr := odrl.Offer{Id: `offer`, Assigner: `provider`, Permissions: []odrl.Rule{{Action: `use`}}}
+	s.OfferStore.AddOffer(ofr.Id, ofr)
+	if err := s.ProviderCatalog.AddDataset(``, `dataset`, dcat.Dataset{ID: `dataset`,
+		OdrlHasPolicy: []odrl.Offer{ofr}}, nil); err != nil {
+		t.Fatalf("AddDataset failed - %s", err)
+	}
+
+	h := NewHandler(boot.Config{}, s, plugins, NewController(boot.Config{}, s, plugins))
+	proposed := ofr
+	proposed.Target, proposed.Assignee = `dataset`, `consumer`
+	ack, err := h.HandleContractRequest(negotiation.ContractRequest{ConsPId: `consumer-pid`,
+		Offer: proposed, CallbackAddr: `http://localhost:8080`}, `consumer`)
+	if err != nil {
+		t.Fatalf("HandleContractRequest failed - %s", err)
+	}
+
+	// a concurrent modification is returned to the consumer as a negotiation error
+	h.cnStore = conflictingStore{ContractNegotiationStore: s.ContractNegotiationStore}
+	_, err = h.HandleContractTermination(negotiation.ContractTermination{ProvPId: ack.ProvPId,
+		ConsPId: ack.ConsPId}, `consumer`)
+
+	var negErr errors.NegotiationError
+	if !defaultErr.As(err, &negErr) || negErr.Code != `ne_20018` || negErr.ProvPid != ack.ProvPId {
+		t.Errorf("HandleContractTermination of a concurrently modified negotiation returned %v, "+
+			"want a negotiation error of a conflict", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/YasiruR/connector/core/provider/transfer"
 	"github.com/YasiruR/connector/domain"
 	"github.com/YasiruR/connector/domain/boot"
+	"github.com/YasiruR/connector/domain/core/dataplane"
 	"github.com/YasiruR/connector/domain/core/provider"
 )
 
@@ -17,12 +18,14 @@ type Provider struct {
 	provider.TransferHandler
 }
 
-func New(cfg boot.Config, stores domain.Stores, plugins domain.Plugins) *Provider {
+func New(cfg boot.Config, stores domain.Stores, plugins domain.Plugins, dp dataplane.Provider) *Provider {
+	nc := negotiation.NewController(cfg, stores, plugins)
+	tc := transfer.NewController(stores, plugins, dp)
 	return &Provider{
-		CatalogHandler:        catalog.NewHandler(cfg.DataSpace.ParticipantId, stores.ProviderCatalog, plugins.Log),
-		NegotiationController: negotiation.NewController(cfg, stores, plugins),
-		NegotiationHandler:    negotiation.NewHandler(cfg, stores, plugins),
-		TransferController:    transfer.NewController(stores.TransferStore, plugins),
-		TransferHandler:       transfer.NewHandler(stores, plugins),
+		CatalogHandler:        catalog.NewHandler(cfg, stores.ProviderCatalog, plugins.Log),
+		NegotiationController: nc,
+		NegotiationHandler:    negotiation.NewHandler(cfg, stores, plugins, nc),
+		TransferController:    tc,
+		TransferHandler:       transfer.NewHandler(stores, plugins, dp, tc),
 	}
 }
@@ -8,23 +8,34 @@ import (
 	"github.com/YasiruR/connector/domain/api"
 	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
 	"github.com/YasiruR/connector/domain/core"
+	"github.com/YasiruR/connector/domain/core/dataplane"
 	"github.com/YasiruR/connector/domain/errors"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
 )
 
 type Controller struct {
-	tpStore stores.TransferStore
-	client  pkg.Client
-	log     pkg.Log
+	tpStore   stores.TransferStore
+	enforcer  enforcer
+	dataPlane dataplane.Provider
+	executor  executor
+	client    pkg.Client
+	log       pkg.Log
 }
 
-func NewController(tpStore stores.TransferStore, plugins domain.Plugins) *Controller {
-	return &Controller{
-		tpStore: tpStore,
-		client:  plugins.Client,
-		log:     plugins.Log,
+func NewController(stores domain.Stores, plugins domain.Plugins, dp dataplane.Provider) *Controller {
+	c := &Controller{
+		tpStore: stores.TransferStore,
+		enforcer: enforcer{agrStore: stores.AgreementStore, tpStore: stores.TransferStore,
+			policy: plugins.PolicyEngine},
+		dataPlane: dp,
+		client:    plugins.Client,
+		log:       plugins.Log,
 	}
+
+	c.executor = executor{dataPlane: dp, tpStore: stores.TransferStore, complete: c.CompleteTransfer,
+		terminate: c.TerminateTransfer, log: plugins.Log}
+	return c
 }
 
 func (c *Controller) StartTransfer(tpId, sourceEndpoint string) error {
@@ -36,10 +47,17 @@ func (c *Controller) StartTransfer(tpId, sourceEndpoint string) error {
 		return errors.StoreFailed(stores.TypeTransfer, `Process`, err)
 	}
 
-	if tp.State != transfer.StateRequested && tp.State != transfer.StateSuspended {
+	if !transfer.ValidTransition(tp.State, transfer.StateStarted) {
 		return errors.Client(errors.StateError(`start transfer`, string(tp.State)))
 	}
 
+	if err = c.enforcer.enforceProcess(tpId); err != nil {
+		if defaultErr.Is(err, pkg.TypePolicyViolation) {
+			return errors.Client(errors.PolicyViolated(err))
+		}
+		return errors.CustomFuncError(`enforceProcess`, err)
+	}
+
 	req := transfer.StartRequest{
 		Ctx:     core.Context,
 		Type:    transfer.MsgTypeStart,
@@ -47,16 +65,22 @@ func (c *Controller) StartTransfer(tpId, sourceEndpoint string) error {
 		ProvPId: tpId,
 	}
 
-	if tp.Type == transfer.HTTPPull {
-		if sourceEndpoint == `` {
-			return errors.Client(errors.MissingAttrError(`source endpoint`,
-				`mandatory for pull transfers`))
-		}
-		req.Address = transfer.Address{
-			Type:               transfer.MsgTypeDataAddress,
-			EndpointType:       transfer.EndpointTypeHTTP,
-			Endpoint:           sourceEndpoint,
-			EndpointProperties: nil, // e.g. auth tokens
+	tr, err := c.tpStore.Request(tpId)
+	if err != nil {
+		return errors.StoreFailed(stores.TypeTransfer, `Request`, err)
+	}
+
+	// data of pull transfers is served by the data plane unless an external source
+	// endpoint is provided
+	if tr.Format == transfer.HTTPPull {
+		if sourceEndpoint != `` {
+			req.Address = transfer.Address{
+				Type:         transfer.MsgTypeDataAddress,
+				EndpointType: transfer.EndpointTypeHTTP,
+				Endpoint:     sourceEndpoint,
+			}
+		} else if req.Address, err = c.dataPlane.Activate(tpId); err != nil {
+			return errors.CustomFuncError(`Activate`, err)
 		}
 	}
 
@@ -64,12 +88,17 @@ func (c *Controller) StartTransfer(tpId, sourceEndpoint string) error {
 		return errors.CustomFuncError(`send`, err)
 	}
 
-	if err = c.tpStore.UpdateState(tpId, transfer.StateStarted); err != nil {
+	if err = c.tpStore.UpdateState(tpId, transfer.StateStarted,
+		transfer.Cause{Initiator: core.RoleProvider}); err != nil {
 		return errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
 	}
 
 	c.log.Debug(fmt.Sprintf("provider controller updated transfer process state (id: %s, state: %s)",
 		tpId, transfer.StateStarted))
+
+	if tr.Format == transfer.HTTPPush {
+		go c.executor.execute(tpId)
+	}
 	return nil
 }
 
@@ -84,7 +113,7 @@ func (c *Controller) SuspendTransfer(tpId, code string, reasons []interface{}) e
 
 	// validate tp
 
-	if tp.State != transfer.StateStarted {
+	if !transfer.ValidTransition(tp.State, transfer.StateSuspended) {
 		return errors.Client(errors.StateError(`suspend transfer`, string(tp.State)))
 	}
 
@@ -101,10 +130,15 @@ func (c *Controller) SuspendTransfer(tpId, code string, reasons []interface{}) e
 		return errors.CustomFuncError(`send`, err)
 	}
 
-	if err = c.tpStore.UpdateState(tpId, transfer.StateSuspended); err != nil {
+	if err = c.tpStore.UpdateState(tpId, transfer.StateSuspended,
+		transfer.Cause{Initiator: core.RoleProvider, Code: code, Reasons: reasons}); err != nil {
 		return errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
 	}
 
+	if err = c.dataPlane.Revoke(tpId); err != nil {
+		return errors.CustomFuncError(`Revoke`, err)
+	}
+
 	c.log.Debug(fmt.Sprintf("provider controller updated transfer process state (id: %s, state: %s)",
 		tpId, transfer.StateSuspended))
 	return nil
@@ -121,7 +155,7 @@ func (c *Controller) CompleteTransfer(tpId string) error {
 
 	// validate tp
 
-	if tp.State != transfer.StateStarted {
+	if !transfer.ValidTransition(tp.State, transfer.StateCompleted) {
 		return errors.Client(errors.StateError(`complete transfer`, string(tp.State)))
 	}
 
@@ -136,10 +170,15 @@ func (c *Controller) CompleteTransfer(tpId string) error {
 		return errors.CustomFuncError(`send`, err)
 	}
 
-	if err = c.tpStore.UpdateState(tpId, transfer.StateCompleted); err != nil {
+	if err = c.tpStore.UpdateState(tpId, transfer.StateCompleted,
+		transfer.Cause{Initiator: core.RoleProvider}); err != nil {
 		return errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
 	}
 
+	if err = c.dataPlane.Revoke(tpId); err != nil {
+		return errors.CustomFuncError(`Revoke`, err)
+	}
+
 	c.log.Info(fmt.Sprintf("data exchange process completed successfully (id: %s)", tpId))
 	return nil
 }
@@ -155,15 +194,15 @@ func (c *Controller) TerminateTransfer(tpId, code string, reasons []interface{})
 
 	// validate tp
 
-	if tp.State != transfer.StateRequested && tp.State != transfer.StateStarted && tp.State != transfer.StateSuspended {
+	if !transfer.ValidTransition(tp.State, transfer.StateTerminated) {
 		return errors.Client(errors.StateError(`terminate transfer`, string(tp.State)))
 	}
 
 	req := transfer.TerminateRequest{
 		Ctx:     core.Context,
 		Type:    transfer.MsgTypeTerminate,
-		ConsPId: tpId,
-		ProvPId: tp.ProvPId,
+		ConsPId: tp.ConsPId,
+		ProvPId: tpId,
 		Code:    code,
 		Reason:  reasons,
 	}
@@ -172,10 +211,15 @@ func (c *Controller) TerminateTransfer(tpId, code string, reasons []interface{})
 		return errors.CustomFuncError(`send`, err)
 	}
 
-	if err = c.tpStore.UpdateState(tpId, transfer.StateTerminated); err != nil {
+	if err = c.tpStore.UpdateState(tpId, transfer.StateTerminated,
+		transfer.Cause{Initiator: core.RoleProvider, Code: code, Reasons: reasons}); err != nil {
 		return errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
 	}
 
+	if err = c.dataPlane.Revoke(tpId); err != nil {
+		return errors.CustomFuncError(`Revoke`, err)
+	}
+
 	c.log.Info(fmt.Sprintf("terminated data exchange process (id: %s)", tpId))
 	return nil
 }
@@ -0,0 +1,150 @@
+package transfer
+
+import (
+	defaultErr "errors"
+	"fmt"
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/domain/stores"
+	"strconv"
+	"time"
+)
+
+// enforcer evaluates the agreement of a transfer process against the context of the
+// transfer before the process is created or started. A pkg.PolicyViolation is returned
+// if the agreement is not satisfied, including when a constraint refers to a left operand
+// which can not be resolved at runtime (e.g. region of the consumer).
+type enforcer struct {
+	agrStore stores.AgreementStore
+	tpStore  stores.TransferStore
+	policy   pkg.PolicyEngine
+}
+
+// withTx returns the enforcer which reads the stores within the transaction, so that the
+// transfers counted for the agreement can not change until the transaction ends
+func (e enforcer) withTx(tx pkg.Transaction) enforcer {
+	return enforcer{agrStore: e.agrStore.WithTx(tx), tpStore: e.tpStore.WithTx(tx), policy: e.policy}
+}
+
+// enforceRequest evaluates the agreement of a transfer request which is not stored yet and
+// must therefore be called within the transaction storing the request (see withTx)
+func (e enforcer) enforceRequest(agr odrl.Agreement, tr transfer.Request, recipient string) error {
+	count, err := e.tpStore.CountByAgreement(agr.Id)
+	if err != nil {
+		return errors.StoreFailed(stores.TypeTransfer, `CountByAgreement`, err)
+	}
+
+	return e.enforce(agr, recipient, tr.Format, count+1)
+}
+
+// enforceProcess evaluates the agreement of an existing transfer process, where the
+// recipient is the assignee since it was authenticated when the process was requested
+func (e enforcer) enforceProcess(tpId string) error {
+	req, err := e.tpStore.Request(tpId)
+	if err != nil {
+		return errors.StoreFailed(stores.TypeTransfer, `Request`, err)
+	}
+
+	agr, err := e.agrStore.Agreement(req.AgreementId)
+	if err != nil {
+		return errors.StoreFailed(stores.TypeAgreement, `Agreement`, err)
+	}
+
+	count, err := e.tpStore.CountByAgreement(req.AgreementId)
+	if err != nil {
+		return errors.StoreFailed(stores.TypeTransfer, `CountByAgreement`, err)
+	}
+
+	return e.enforce(agr, string(agr.Assignee), req.Format, count)
+}
+
+// enforce checks if any of the permissions of the agreement to use the dataset is satisfied
+// by the transfer and none of its prohibitions to use the dataset applies to the transfer.
+// Executions refer to the number of transfers of the agreement including the current one.
+func (e enforcer) enforce(agr odrl.Agreement, recipient string, format transfer.DataTransferType,
+	executions int) error {
+	ctx := pkg.PolicyContext{
+		odrl.LeftOperandDateTime:   time.Now().UTC().Format(time.RFC3339),
+		odrl.LeftOperandCount:      strconv.Itoa(executions),
+		odrl.LeftOperandRecipient:  recipient,
+		odrl.LeftOperandFileFormat: string(format),
+	}
+
+	if err := e.prohibited(agr.Prohibitions, ctx); err != nil {
+		return err
+	}
+
+	var err error = pkg.PolicyViolation{Reason: `agreement does not grant any permission to use the dataset`}
+	for _, perm := range agr.Permissions {
+		if !transfers(perm.Action) {
+			continue
+		}
+
+		if err = e.policy.Evaluate(perm, ctx); err == nil {
+			return nil
+		}
+	}
+
+	// agreements which can not be evaluated (e.g. unsupported operators) are never satisfied
+	if !defaultErr.Is(err, pkg.TypePolicyViolation) {
+		return pkg.PolicyViolation{Reason: err.Error()}
+	}
+	return err
+}
+
+// prohibited returns a PolicyViolation if a prohibition to use the dataset applies to
+// the transfer, i.e. all of its constraints are satisfied by the context. Since it can
+// not be determined whether a prohibition with constraints which can not be evaluated at
+// runtime applies, such a prohibition denies the transfer as well.
+func (e enforcer) prohibited(prohibitions []odrl.Rule, ctx pkg.PolicyContext) error {
+	for _, proh := range prohibitions {
+		if !transfers(proh.Action) || len(proh.Constraints) == 0 {
+			continue
+		}
+
+		if !evaluable(proh.Constraints, ctx) {
+			return pkg.PolicyViolation{Reason: fmt.Sprintf("prohibition of the agreement can not be "+
+				"evaluated at runtime (action: %s)", proh.Action)}
+		}
+
+		err := e.policy.Evaluate(proh, ctx)
+		if err == nil {
+			return pkg.PolicyViolation{Reason: fmt.Sprintf("transfer is prohibited by the agreement "+
+				"(action: %s)", proh.Action)}
+		}
+
+		if !defaultErr.Is(err, pkg.TypePolicyViolation) {
+			return pkg.PolicyViolation{Reason: err.Error()}
+		}
+	}
+	return nil
+}
+
+// transfers returns true if the action is exercised by a transfer of the dataset, so that
+// both permissions and prohibitions are filtered by the same actions
+func transfers(action odrl.Action) bool {
+	return odrl.Prefixed(string(action)) == odrl.ActionUse
+}
+
+// evaluable returns true if all the constraints can be evaluated with the context
+func evaluable(constraints []odrl.Constraint, ctx pkg.PolicyContext) bool {
+	for _, c := range constraints {
+		switch {
+		case len(c.And) > 0, len(c.Or) > 0, len(c.Xone) > 0:
+			if !evaluable(c.And, ctx) || !evaluable(c.Or, ctx) || !evaluable(c.Xone, ctx) {
+				return false
+			}
+		default:
+			if _, ok := ctx[odrl.Prefixed(c.LeftOperand)]; !ok {
+				return false
+			}
+
+			if _, ok := ctx[odrl.Prefixed(c.RightOperandReference)]; c.RightOperandReference != `` && !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
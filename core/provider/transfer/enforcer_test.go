@@ -0,0 +1,62 @@
+package transfer
+
+import (
+	defaultErr "errors"
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/pkg/log"
+	"github.com/YasiruR/connector/pkg/policy"
+	"testing"
+)
+
+func TestEnforcer_Enforce(t *testing.T) {
+	count := odrl.Constraint{LeftOperand: `count`, Operator: `lteq`,
+		RightOperand: odrl.TypedLiteral(`2`, odrl.DataTypeInteger)}
+	region := odrl.Constraint{LeftOperand: `region`, Operator: `eq`, RightOperand: odrl.Literal(`eu`)}
+	recipient := odrl.Constraint{LeftOperand: `recipient`, Operator: `eq`, RightOperand: odrl.Literal(`consumer`)}
+	agreement := func(perms []odrl.Rule, prohs []odrl.Rule) odrl.Agreement {
+		return odrl.Agreement{Id: `agreement`, Assignee: `consumer`, Permissions: perms, Prohibitions: prohs}
+	}
+
+	tests := []struct {
+		name       string
+		agr        odrl.Agreement
+		executions int
+		permitted  bool
+	}{
+		{`runtime constraints`, agreement([]odrl.Rule{{Action: `use`,
+			Constraints: []odrl.Constraint{count, recipient}}}, nil), 1, true},
+		{`exceeded count`, agreement([]odrl.Rule{{Action: `use`,
+			Constraints: []odrl.Constraint{count, recipient}}}, nil), 3, false},
+		{`unresolvable operand`, agreement([]odrl.Rule{{Action: `use`,
+			Constraints: []odrl.Constraint{count, region}}}, nil), 1, false},
+		{`unresolvable operand of and`, agreement([]odrl.Rule{{Action: `use`,
+			Constraints: []odrl.Constraint{{And: []odrl.Constraint{count, region}}}}}, nil), 1, false},
+		{`unresolvable operand of or`, agreement([]odrl.Rule{{Action: `use`,
+			Constraints: []odrl.Constraint{{Or: []odrl.Constraint{region, count}}}}}, nil), 1, true},
+		{`unresolvable permission with an alternative`, agreement([]odrl.Rule{
+			{Action: `use`, Constraints: []odrl.Constraint{region}},
+			{Action: `use`, Constraints: []odrl.Constraint{recipient}}}, nil), 1, true},
+		{`applicable prohibition`, agreement([]odrl.Rule{{Action: `use`}},
+			[]odrl.Rule{{Action: `use`, Constraints: []odrl.Constraint{recipient}}}), 1, false},
+		{`unresolvable prohibition`, agreement([]odrl.Rule{{Action: `use`}},
+			[]odrl.Rule{{Action: `use`, Constraints: []odrl.Constraint{region}}}), 1, false},
+		{`no permission`, agreement(nil, nil), 1, false},
+		{`permission of another action`, agreement([]odrl.Rule{{Action: `odrl:print`}}, nil), 1, false},
+		{`prohibition of another action`, agreement([]odrl.Rule{{Action: `odrl:use`}},
+			[]odrl.Rule{{Action: `print`, Constraints: []odrl.Constraint{recipient}}}), 1, true},
+	}
+
+	e := enforcer{policy: policy.NewEngine(log.NewLogger())}
+	for _, test := range tests {
+		err := e.enforce(test.agr, `consumer`, transfer.HTTPPull, test.executions)
+		if test.permitted && err != nil {
+			t.Errorf("%s: enforce returned %s, want permitted", test.name, err)
+		}
+
+		if !test.permitted && !defaultErr.Is(err, pkg.TypePolicyViolation) {
+			t.Errorf("%s: enforce returned %v, want a policy violation", test.name, err)
+		}
+	}
+}
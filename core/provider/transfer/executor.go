@@ -0,0 +1,64 @@
+package transfer
+
+import (
+	defaultErr "errors"
+	"fmt"
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"github.com/YasiruR/connector/domain/core/dataplane"
+	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/domain/stores"
+)
+
+// codePushFailed is the code of the termination message sent when the data can not
+// be pushed to the sink of the consumer
+const codePushFailed = `PUSH_FAILED`
+
+// executor pushes the data of a started push transfer and concludes the transfer
+// process with a completion or termination message based on the outcome
+type executor struct {
+	dataPlane dataplane.Provider
+	tpStore   stores.TransferStore
+	complete  func(tpId string) error
+	terminate func(tpId, code string, reasons []interface{}) error
+	log       pkg.Log
+}
+
+// execute blocks until the push is concluded and therefore, should be run in a
+// separate goroutine. A process which has already been completed by the consumer,
+// once its sink committed the pushed data, is considered to be concluded successfully.
+func (e executor) execute(tpId string) {
+	err := e.dataPlane.Push(tpId)
+	switch {
+	case err == nil:
+		if err = e.complete(tpId); err != nil && !e.completed(tpId) {
+			e.log.Error(errors.CustomFuncError(`CompleteTransfer`, err))
+		}
+	case defaultErr.Is(err, dataplane.TypeCancelled):
+		// transfer process has been suspended or concluded meanwhile (e.g. completed by
+		// the consumer once its sink received the data)
+		e.log.Info(fmt.Sprintf("push of the transfer process was cancelled since the process was "+
+			"suspended or concluded (id: %s)", tpId))
+	case e.completed(tpId):
+		// push may fail after the sink committed the data (e.g. the response of the final
+		// chunk was lost) although the consumer has completed the process
+		e.log.Info(fmt.Sprintf("push of the transfer process failed after it was completed by the "+
+			"consumer (id: %s, error: %s)", tpId, err))
+	default:
+		e.log.Error(errors.CustomFuncError(`Push`, err))
+		err = e.terminate(tpId, codePushFailed, []interface{}{err.Error()})
+		if err != nil && !e.completed(tpId) {
+			e.log.Error(errors.CustomFuncError(`TerminateTransfer`, err))
+		}
+	}
+}
+
+// completed returns true if the transfer process is in the completed state
+func (e executor) completed(tpId string) bool {
+	tp, err := e.tpStore.Process(tpId)
+	if err != nil {
+		e.log.Error(errors.StoreFailed(stores.TypeTransfer, `Process`, err))
+		return false
+	}
+	return tp.State == transfer.StateCompleted
+}
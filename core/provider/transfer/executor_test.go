@@ -0,0 +1,85 @@
+package transfer
+
+import (
+	defaultErr "errors"
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"github.com/YasiruR/connector/domain/core"
+	"github.com/YasiruR/connector/domain/core/dataplane"
+	"github.com/YasiruR/connector/domain/stores"
+	"github.com/YasiruR/connector/pkg/database/memory"
+	"github.com/YasiruR/connector/pkg/log"
+	"github.com/YasiruR/connector/stores/protocol"
+	"testing"
+)
+
+// sink completes the transfer process on behalf of the consumer, as the sink of the
+// consumer does once it committed the data, before the push returns the given error
+type sink struct {
+	dataplane.Provider
+	tpStore  stores.TransferStore
+	complete bool
+	err      error
+}
+
+func (s sink) Push(tpId string) error {
+	if s.complete {
+		if err := s.tpStore.UpdateState(tpId, transfer.StateCompleted,
+			transfer.Cause{Initiator: core.RoleConsumer}); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+func TestExecutor_Execute(t *testing.T) {
+	errPush := defaultErr.New(`push failed`)
+	tests := []struct {
+		name       string
+		sink       sink
+		terminated bool
+	}{
+		{name: `completed by the provider`, sink: sink{}},
+		{name: `completed by the consumer`, sink: sink{complete: true}},
+		{name: `failed after completion`, sink: sink{complete: true, err: errPush}},
+		{name: `failed`, sink: sink{err: errPush}, terminated: true},
+	}
+
+	l := log.NewLogger()
+	for _, test := range tests {
+		tpStore := protocol.NewTransferStore(domain.Plugins{Database: memory.NewStore(l), Log: l})
+		for _, s := range []transfer.State{transfer.StateRequested, transfer.StateStarted} {
+			if err := tpStore.AddProcess(`tp`, transfer.Process{ProvPId: `tp`, State: s},
+				transfer.Cause{Initiator: core.RoleConsumer}); err != nil {
+				t.Fatalf("%s: AddProcess failed - %s", test.name, err)
+			}
+		}
+
+		var completed, terminated bool
+		test.sink.tpStore = tpStore
+		e := executor{dataPlane: test.sink, tpStore: tpStore, log: l,
+			complete: func(tpId string) error {
+				completed = true
+				return tpStore.UpdateState(tpId, transfer.StateCompleted, transfer.Cause{Initiator: core.RoleProvider})
+			},
+			terminate: func(tpId, _ string, _ []interface{}) error {
+				terminated = true
+				return tpStore.UpdateState(tpId, transfer.StateTerminated, transfer.Cause{Initiator: core.RoleProvider})
+			},
+		}
+
+		e.execute(`tp`)
+		if terminated != test.terminated {
+			t.Errorf("%s: transfer process terminated: %t, want %t", test.name, terminated, test.terminated)
+		}
+
+		// completion is attempted after a successful push even if the consumer has completed it
+		if completed != (test.sink.err == nil) {
+			t.Errorf("%s: transfer process completed by the provider: %t", test.name, completed)
+		}
+
+		if got := e.completed(`tp`); got == test.terminated {
+			t.Errorf("%s: transfer process is completed: %t, want %t", test.name, got, !test.terminated)
+		}
+	}
+}
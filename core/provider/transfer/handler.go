@@ -4,26 +4,44 @@ import (
 	defaultErr "errors"
 	"fmt"
 	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
 	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
 	"github.com/YasiruR/connector/domain/core"
+	"github.com/YasiruR/connector/domain/core/dataplane"
 	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/models/odrl"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
 )
 
 type Handler struct {
-	urn      pkg.URNService
-	agrStore stores.AgreementStore
-	tpStore  stores.TransferStore
-	log      pkg.Log
+	urn       pkg.URNService
+	db        pkg.Database
+	catalog   stores.ProviderCatalog
+	cnStore   stores.ContractNegotiationStore
+	agrStore  stores.AgreementStore
+	tpStore   stores.TransferStore
+	enforcer  enforcer
+	dataPlane dataplane.Provider
+	executor  executor
+	log       pkg.Log
 }
 
-func NewHandler(stores domain.Stores, plugins domain.Plugins) *Handler {
+// NewHandler requires the controller of the provider since the handler resumes push
+// transfers which are concluded by the controller
+func NewHandler(stores domain.Stores, plugins domain.Plugins, dp dataplane.Provider, c *Controller) *Handler {
 	return &Handler{
+		catalog:  stores.ProviderCatalog,
+		cnStore:  stores.ContractNegotiationStore,
 		agrStore: stores.AgreementStore,
 		tpStore:  stores.TransferStore,
-		urn:      plugins.URNService,
-		log:      plugins.Log,
+		enforcer: enforcer{agrStore: stores.AgreementStore, tpStore: stores.TransferStore,
+			policy: plugins.PolicyEngine},
+		dataPlane: dp,
+		executor:  c.executor,
+		urn:       plugins.URNService,
+		db:        plugins.Database,
+		log:       plugins.Log,
 	}
 }
 
@@ -40,14 +58,22 @@ func (h *Handler) HandleGetProcess(tpId string) (transfer.Ack, error) {
 	return transfer.Ack(tp), nil
 }
 
-func (h *Handler) HandleTransferRequest(tr transfer.Request) (transfer.Ack, error) {
+func (h *Handler) HandleTransferRequest(tr transfer.Request, participantId string) (transfer.Ack, error) {
 	// validate agreement
-	_, err := h.agrStore.Agreement(tr.AgreementId)
+	agr, err := h.agrStore.Agreement(tr.AgreementId)
 	if err != nil {
 		return transfer.Ack{}, errors.Transfer(``, ``,
 			errors.InvalidKey(stores.TypeAgreement, `agreement id`, err))
 	}
 
+	if err = h.validateParticipant(agr, tr, participantId); err != nil {
+		return transfer.Ack{}, err
+	}
+
+	if err = h.validateFormat(agr, tr); err != nil {
+		return transfer.Ack{}, err
+	}
+
 	tpId, err := h.urn.NewURN()
 	if err != nil {
 		return transfer.Ack{}, errors.PkgError(pkg.TypeURN, `NewURN`, err, `transfer process id`)
@@ -61,15 +87,38 @@ func (h *Handler) HandleTransferRequest(tr transfer.Request) (transfer.Ack, erro
 		State:   transfer.StateRequested,
 	}
 
-	h.tpStore.AddProcess(tpId, transfer.Process(ack))
-	h.tpStore.SetCallbackAddr(tpId, tr.CallbackAddr)
+	// agreement is enforced within the transaction storing the process so that concurrent
+	// requests can not exceed the number of transfers permitted by the agreement. Process is
+	// stored along with its counterparty so that it is never accessible without being bound
+	// to the consumer.
+	if err = h.db.Transaction(func(tx pkg.Transaction) error {
+		if err := h.enforcer.withTx(tx).enforceRequest(agr, tr, participantId); err != nil {
+			if defaultErr.Is(err, pkg.TypePolicyViolation) {
+				return errors.Transfer(``, tr.ConsPId, errors.PolicyViolated(err))
+			}
+			return errors.CustomFuncError(`enforceRequest`, err)
+		}
+
+		tpStore := h.tpStore.WithTx(tx)
+		if err := tpStore.SetCounterparty(tpId, participantId); err != nil {
+			return errors.StoreFailed(stores.TypeTransfer, `SetCounterparty`, err)
+		}
+
+		if err := tpStore.SetProcess(tpId, transfer.Process(ack), tr,
+			transfer.Cause{Initiator: core.RoleConsumer}); err != nil {
+			return errors.StoreFailed(stores.TypeTransfer, `SetProcess`, err)
+		}
+		return nil
+	}); err != nil {
+		return transfer.Ack{}, err
+	}
 	h.log.Trace("stored transfer process", ack)
 	h.log.Debug(fmt.Sprintf("provider handler updated transfer process (id: %s, state: %s)",
 		tpId, transfer.StateRequested))
 	return ack, nil
 }
 
-func (h *Handler) HandleTransferSuspension(sr transfer.SuspendRequest) (transfer.Ack, error) {
+func (h *Handler) HandleTransferSuspension(sr transfer.SuspendRequest, participantId string) (transfer.Ack, error) {
 	tp, err := h.tpStore.Process(sr.ProvPId)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -79,15 +128,26 @@ func (h *Handler) HandleTransferSuspension(sr transfer.SuspendRequest) (transfer
 		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `Process`, err)
 	}
 
-	// validate tp
+	if err = h.validateCounterparty(tp, participantId); err != nil {
+		return transfer.Ack{}, err
+	}
 
-	if tp.State != transfer.StateStarted {
-		return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
-			errors.StateError(`suspend transfer`, string(tp.State)))
+	if err = h.tpStore.UpdateState(sr.ProvPId, transfer.StateSuspended,
+		transfer.Cause{Initiator: core.RoleConsumer, Code: sr.Code, Reasons: sr.Reason}); err != nil {
+		switch {
+		case defaultErr.Is(err, stores.TypeInvalidTransition):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.StateError(`suspend transfer`, string(tp.State)))
+		case defaultErr.Is(err, stores.TypeConflict):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.Conflict(`suspend transfer`, err))
+		default:
+			return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+		}
 	}
 
-	if err = h.tpStore.UpdateState(sr.ProvPId, transfer.StateSuspended); err != nil {
-		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+	if err = h.dataPlane.Revoke(sr.ProvPId); err != nil {
+		return transfer.Ack{}, errors.CustomFuncError(`Revoke`, err)
 	}
 
 	tp.State = transfer.StateSuspended
@@ -96,7 +156,7 @@ func (h *Handler) HandleTransferSuspension(sr transfer.SuspendRequest) (transfer
 	return transfer.Ack(tp), nil
 }
 
-func (h *Handler) HandleTransferStart(sr transfer.StartRequest) (transfer.Ack, error) {
+func (h *Handler) HandleTransferStart(sr transfer.StartRequest, participantId string) (transfer.Ack, error) {
 	tp, err := h.tpStore.Process(sr.ProvPId)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -106,15 +166,54 @@ func (h *Handler) HandleTransferStart(sr transfer.StartRequest) (transfer.Ack, e
 		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `Process`, err)
 	}
 
+	if err = h.validateCounterparty(tp, participantId); err != nil {
+		return transfer.Ack{}, err
+	}
+
 	// validate if received details are compatible with existing TP
 
+	// consumer can only restart a suspended transfer process while the initial start
+	// is always sent by the provider
 	if tp.State != transfer.StateSuspended {
 		return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
 			errors.StateError(`start transfer`, string(tp.State)))
 	}
 
-	if err = h.tpStore.UpdateState(sr.ProvPId, transfer.StateStarted); err != nil {
-		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+	if err = h.enforcer.enforceProcess(tp.ProvPId); err != nil {
+		if defaultErr.Is(err, pkg.TypePolicyViolation) {
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId, errors.PolicyViolated(err))
+		}
+		return transfer.Ack{}, errors.CustomFuncError(`enforceProcess`, err)
+	}
+
+	if err = h.tpStore.UpdateState(sr.ProvPId, transfer.StateStarted,
+		transfer.Cause{Initiator: core.RoleConsumer}); err != nil {
+		switch {
+		case defaultErr.Is(err, stores.TypeInvalidTransition):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.StateError(`start transfer`, string(tp.State)))
+		case defaultErr.Is(err, stores.TypeConflict):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.Conflict(`start transfer`, err))
+		default:
+			return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+		}
+	}
+
+	// consumer continues a pull transfer with the data address of the initial start
+	// and therefore, its token is reinstated whereas push transfers are pushed again
+	req, err := h.tpStore.Request(sr.ProvPId)
+	if err != nil {
+		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `Request`, err)
+	}
+
+	switch req.Format {
+	case transfer.HTTPPull:
+		if _, err = h.dataPlane.Activate(sr.ProvPId); err != nil {
+			return transfer.Ack{}, errors.CustomFuncError(`Activate`, err)
+		}
+	case transfer.HTTPPush:
+		go h.executor.execute(sr.ProvPId)
 	}
 
 	tp.State = transfer.StateStarted
@@ -123,7 +222,7 @@ func (h *Handler) HandleTransferStart(sr transfer.StartRequest) (transfer.Ack, e
 	return transfer.Ack(tp), nil
 }
 
-func (h *Handler) HandleTransferCompletion(cr transfer.CompleteRequest) (transfer.Ack, error) {
+func (h *Handler) HandleTransferCompletion(cr transfer.CompleteRequest, participantId string) (transfer.Ack, error) {
 	tp, err := h.tpStore.Process(cr.ProvPId)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -133,13 +232,26 @@ func (h *Handler) HandleTransferCompletion(cr transfer.CompleteRequest) (transfe
 		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `Process`, err)
 	}
 
-	if tp.State != transfer.StateStarted {
-		return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
-			errors.StateError(`complete transfer`, string(tp.State)))
+	if err = h.validateCounterparty(tp, participantId); err != nil {
+		return transfer.Ack{}, err
 	}
 
-	if err = h.tpStore.UpdateState(cr.ProvPId, transfer.StateCompleted); err != nil {
-		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+	if err = h.tpStore.UpdateState(cr.ProvPId, transfer.StateCompleted,
+		transfer.Cause{Initiator: core.RoleConsumer}); err != nil {
+		switch {
+		case defaultErr.Is(err, stores.TypeInvalidTransition):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.StateError(`complete transfer`, string(tp.State)))
+		case defaultErr.Is(err, stores.TypeConflict):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.Conflict(`complete transfer`, err))
+		default:
+			return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+		}
+	}
+
+	if err = h.dataPlane.Revoke(cr.ProvPId); err != nil {
+		return transfer.Ack{}, errors.CustomFuncError(`Revoke`, err)
 	}
 
 	tp.State = transfer.StateCompleted
@@ -147,7 +259,7 @@ func (h *Handler) HandleTransferCompletion(cr transfer.CompleteRequest) (transfe
 	return transfer.Ack(tp), nil
 }
 
-func (h *Handler) HandleTransferTermination(tr transfer.TerminateRequest) (transfer.Ack, error) {
+func (h *Handler) HandleTransferTermination(tr transfer.TerminateRequest, participantId string) (transfer.Ack, error) {
 	tp, err := h.tpStore.Process(tr.ProvPId)
 	if err != nil {
 		if defaultErr.Is(err, stores.TypeInvalidKey) {
@@ -157,13 +269,26 @@ func (h *Handler) HandleTransferTermination(tr transfer.TerminateRequest) (trans
 		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `Process`, err)
 	}
 
-	if tp.State != transfer.StateRequested && tp.State != transfer.StateStarted && tp.State != transfer.StateSuspended {
-		return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
-			errors.StateError(`terminate transfer`, string(tp.State)))
+	if err = h.validateCounterparty(tp, participantId); err != nil {
+		return transfer.Ack{}, err
 	}
 
-	if err = h.tpStore.UpdateState(tr.ProvPId, transfer.StateTerminated); err != nil {
-		return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+	if err = h.tpStore.UpdateState(tr.ProvPId, transfer.StateTerminated,
+		transfer.Cause{Initiator: core.RoleConsumer, Code: tr.Code, Reasons: tr.Reason}); err != nil {
+		switch {
+		case defaultErr.Is(err, stores.TypeInvalidTransition):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.StateError(`terminate transfer`, string(tp.State)))
+		case defaultErr.Is(err, stores.TypeConflict):
+			return transfer.Ack{}, errors.Transfer(tp.ProvPId, tp.ConsPId,
+				errors.Conflict(`terminate transfer`, err))
+		default:
+			return transfer.Ack{}, errors.StoreFailed(stores.TypeTransfer, `UpdateState`, err)
+		}
+	}
+
+	if err = h.dataPlane.Revoke(tr.ProvPId); err != nil {
+		return transfer.Ack{}, errors.CustomFuncError(`Revoke`, err)
 	}
 
 	tp.State = transfer.StateTerminated
@@ -171,3 +296,82 @@ func (h *Handler) HandleTransferTermination(tr transfer.TerminateRequest) (trans
 		tr.ProvPId, tr.Reason))
 	return transfer.Ack(tp), nil
 }
+
+// validateParticipant checks if the agreement was concluded by a finalized negotiation
+// with the authenticated participant requesting the transfer
+func (h *Handler) validateParticipant(agr odrl.Agreement, tr transfer.Request, participantId string) error {
+	cnId, err := h.agrStore.NegotiationID(agr.Id)
+	if err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return errors.Transfer(``, tr.ConsPId, errors.InvalidKey(stores.TypeAgreement, `agreement id`, err))
+		}
+		return errors.StoreFailed(stores.TypeAgreement, `NegotiationID`, err)
+	}
+
+	cn, err := h.cnStore.Negotiation(cnId)
+	if err != nil {
+		return errors.StoreFailed(stores.TypeContractNegotiation, `Negotiation`, err)
+	}
+
+	if cn.State != negotiation.StateFinalized {
+		return errors.Transfer(``, tr.ConsPId, errors.StateError(`request transfer`, string(cn.State)))
+	}
+
+	assignee, err := h.cnStore.Assignee(cnId)
+	if err != nil {
+		return errors.StoreFailed(stores.TypeContractNegotiation, `Assignee`, err)
+	}
+
+	if assignee != agr.Assignee {
+		return errors.Transfer(``, tr.ConsPId, errors.InvalidValue(`assignee`, string(agr.Assignee),
+			string(assignee)))
+	}
+
+	if string(agr.Assignee) != participantId {
+		return errors.Transfer(``, tr.ConsPId, errors.InvalidValue(`participant`, string(agr.Assignee),
+			participantId))
+	}
+
+	return nil
+}
+
+// validateCounterparty rejects a message of the transfer process unless it is sent by the
+// consumer which requested the process
+func (h *Handler) validateCounterparty(tp transfer.Process, participantId string) error {
+	counterparty, err := h.tpStore.Counterparty(tp.ProvPId)
+	if err != nil && !defaultErr.Is(err, stores.TypeInvalidKey) {
+		return errors.StoreFailed(stores.TypeTransfer, `Counterparty`, err)
+	}
+
+	if counterparty == `` || counterparty != participantId {
+		return errors.Transfer(tp.ProvPId, tp.ConsPId, errors.InvalidValue(`participant`, counterparty,
+			participantId))
+	}
+	return nil
+}
+
+// validateFormat checks if the dataset of the agreement has a distribution matching the
+// format and media type of the request, which the data plane serves for the transfer
+func (h *Handler) validateFormat(agr odrl.Agreement, tr transfer.Request) error {
+	ds, err := h.catalog.Dataset(string(agr.Target))
+	if err != nil {
+		if defaultErr.Is(err, stores.TypeInvalidKey) {
+			return errors.Transfer(``, tr.ConsPId, errors.InvalidKey(stores.TypeProviderCatalog,
+				`dataset id`, err))
+		}
+		return errors.StoreFailed(stores.TypeProviderCatalog, `Dataset`, err)
+	}
+
+	if !tr.Format.Supported() {
+		return errors.Transfer(``, tr.ConsPId, errors.UnsupportedFormat(string(tr.Format), tr.MediaType))
+	}
+
+	dist, ok := ds.Distribution(string(tr.Format), tr.MediaType)
+	if !ok {
+		return errors.Transfer(``, tr.ConsPId, errors.UnsupportedFormat(string(tr.Format), tr.MediaType))
+	}
+
+	h.log.Trace(fmt.Sprintf("selected distribution for the transfer request (dataset: %s, distribution: %s)",
+		ds.ID, dist.ID))
+	return nil
+}
@@ -0,0 +1,86 @@
+package transfer
+
+import (
+	"fmt"
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"github.com/YasiruR/connector/domain/boot"
+	"github.com/YasiruR/connector/domain/models/dcat"
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"github.com/YasiruR/connector/pkg/database/memory"
+	"github.com/YasiruR/connector/pkg/log"
+	pkgPolicy "github.com/YasiruR/connector/pkg/policy"
+	"github.com/YasiruR/connector/pkg/urn"
+	"github.com/YasiruR/connector/stores/catalog"
+	"github.com/YasiruR/connector/stores/policy"
+	"github.com/YasiruR/connector/stores/protocol"
+	"sync"
+	"testing"
+)
+
+func TestHandler_TransferRequestCount(t *testing.T) {
+	l := log.NewLogger()
+	plugins := domain.Plugins{Database: memory.NewStore(l), URNService: urn.NewGenerator(),
+		PolicyEngine: pkgPolicy.NewEngine(l), Log: l}
+	s := domain.Stores{
+		ProviderCatalog:          catalog.NewProviderCatalog(boot.Config{}, plugins),
+		ContractNegotiationStore: protocol.NewContractNegotiationStore(plugins),
+		AgreementStore:           policy.NewAgreementStore(plugins),
+		TransferStore:            protocol.NewTransferStore(plugins),
+	}
+
+	if err := s.ProviderCatalog.AddDataset(``, `dataset`, dcat.Dataset{ID: `dataset`,
+		DcatDistribution: []dcat.Distribution{{DctFormat: string(transfer.HTTPPull)}}}, nil); err != nil {
+		t.Fatalf("AddDataset failed - %s", err)
+	}
+
+	if err := s.ContractNegotiationStore.SetNegotiation(`cn`, negotiation.Negotiation{ProvPId: `cn`,
+		State: negotiation.StateRequested}, `http://localhost:8080`, `provider`, `consumer`); err != nil {
+		t.Fatalf("SetNegotiation failed - %s", err)
+	}
+
+	for _, state := range []negotiation.State{negotiation.StateAgreed, negotiation.StateVerified,
+		negotiation.StateFinalized} {
+		if err := s.ContractNegotiationStore.UpdateState(`cn`, state); err != nil {
+			t.Fatalf("UpdateState failed - %s", err)
+		}
+	}
+
+	count := odrl.Constraint{LeftOperand: `count`, Operator: `lteq`,
+		RightOperand: odrl.TypedLiteral(`2`, odrl.DataTypeInteger)}
+	if err := s.AgreementStore.AddAgreement(`cn`, odrl.Agreement{Id: `agreement`, Target: `dataset`,
+		Assigner: `provider`, Assignee: `consumer`, Permissions: []odrl.Rule{{Action: `use`,
+			Constraints: []odrl.Constraint{count}}}}); err != nil {
+		t.Fatalf("AddAgreement failed - %s", err)
+	}
+
+	h := &Handler{urn: plugins.URNService, db: plugins.Database, catalog: s.ProviderCatalog,
+		cnStore: s.ContractNegotiationStore, agrStore: s.AgreementStore, tpStore: s.TransferStore,
+		enforcer: enforcer{agrStore: s.AgreementStore, tpStore: s.TransferStore, policy: plugins.PolicyEngine},
+		log:      l}
+
+	// concurrent requests must not exceed the number of transfers permitted by the agreement
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	accepted := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := h.HandleTransferRequest(transfer.Request{ConsPId: fmt.Sprintf("consumer-pid-%d", i),
+				AgreementId: `agreement`, Format: transfer.HTTPPull, CallbackAddr: `http://localhost:8080`},
+				`consumer`)
+			if err == nil {
+				lock.Lock()
+				accepted++
+				lock.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if accepted != 2 {
+		t.Errorf("HandleTransferRequest accepted %d concurrent requests, want 2", accepted)
+	}
+}
@@ -0,0 +1,18 @@
+package http
+
+import (
+	"github.com/YasiruR/connector/domain/api"
+	"net/http"
+)
+
+// Endpoints of the data plane which are accessed with the data address of a transfer process
+const (
+	PullEndpoint = `/data/{` + api.ParamPid + `}`
+	SinkEndpoint = `/sink/{` + api.ParamPid + `}`
+)
+
+type Handler interface {
+	HandlePull(w http.ResponseWriter, r *http.Request)
+	HandleSink(w http.ResponseWriter, r *http.Request)
+	HandleSinkCommit(w http.ResponseWriter, r *http.Request)
+}
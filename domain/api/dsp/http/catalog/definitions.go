@@ -9,6 +9,14 @@ const (
 	MsgTypeError         = `dspace:CatalogError`
 )
 
+// Query parameters of a paginated catalog request, which are included in the link to
+// the next page of the response
+const (
+	ParamLimit  = `limit`
+	ParamCursor = `cursor`
+	LinkRelNext = `next`
+)
+
 // Endpoints
 const (
 	RequestEndpoint        = `/catalog/request`
@@ -0,0 +1,147 @@
+package catalog
+
+import (
+	"fmt"
+	"github.com/YasiruR/connector/domain/models/dcat"
+	"strings"
+)
+
+// Filter fields of the expressions in dspace:filter, where each expression is of the
+// form <field>:<value>. Alternative values of an expression are separated by '|', and
+// a dataset is returned only if it matches all the expressions of the filter.
+//
+//	keyword: dataset has the keyword
+//	title:   title of the dataset contains the value
+//	format:  dataset has a distribution with the format or media type
+//	action:  dataset has a policy permitting the action (e.g. use or odrl:use)
+//	text:    title, descriptions or keywords of the dataset contain the value
+const (
+	FilterKeyword = `keyword`
+	FilterTitle   = `title`
+	FilterFormat  = `format`
+	FilterAction  = `action`
+	FilterText    = `text`
+)
+
+const (
+	filterSeparator   = `:`
+	filterAlternative = `|`
+	odrlPrefix        = `odrl:`
+)
+
+type Filter []expression
+
+type expression struct {
+	field  string
+	values []string
+}
+
+// ParseFilter validates the expressions of dspace:filter, where values are compared
+// case-insensitively
+func ParseFilter(exprs []string) (Filter, error) {
+	var f Filter
+	for _, e := range exprs {
+		field, val, ok := strings.Cut(e, filterSeparator)
+		if !ok {
+			return nil, fmt.Errorf("filter expression should be of the form <field>:<value> (expression: %s)", e)
+		}
+
+		field = strings.ToLower(strings.TrimSpace(field))
+		switch field {
+		case FilterKeyword, FilterTitle, FilterFormat, FilterAction, FilterText:
+		default:
+			return nil, fmt.Errorf("unsupported field in filter expression (field: %s)", field)
+		}
+
+		var vals []string
+		for _, v := range strings.Split(val, filterAlternative) {
+			if v = strings.ToLower(strings.TrimSpace(v)); v != `` {
+				vals = append(vals, v)
+			}
+		}
+
+		if len(vals) == 0 {
+			return nil, fmt.Errorf("filter expression does not have a value (expression: %s)", e)
+		}
+		f = append(f, expression{field: field, values: vals})
+	}
+	return f, nil
+}
+
+// Apply returns the datasets matching the filter
+func (f Filter) Apply(datasets []dcat.Dataset) []dcat.Dataset {
+	if len(f) == 0 {
+		return datasets
+	}
+
+	matched := make([]dcat.Dataset, 0)
+	for _, ds := range datasets {
+		if f.Match(ds) {
+			matched = append(matched, ds)
+		}
+	}
+	return matched
+}
+
+func (f Filter) Match(ds dcat.Dataset) bool {
+	for _, e := range f {
+		if !e.match(ds) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e expression) match(ds dcat.Dataset) bool {
+	for _, v := range e.values {
+		if e.matchValue(ds, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e expression) matchValue(ds dcat.Dataset, val string) bool {
+	switch e.field {
+	case FilterKeyword:
+		for _, kw := range ds.DcatKeyword {
+			if strings.EqualFold(string(kw), val) {
+				return true
+			}
+		}
+	case FilterTitle:
+		return strings.Contains(strings.ToLower(ds.DctTitle), val)
+	case FilterFormat:
+		for _, dist := range ds.DcatDistribution {
+			if strings.EqualFold(dist.DctFormat, val) || strings.EqualFold(dist.DcatMediaType, val) {
+				return true
+			}
+		}
+	case FilterAction:
+		val = strings.TrimPrefix(val, odrlPrefix)
+		for _, ofr := range ds.OdrlHasPolicy {
+			for _, rule := range ofr.Permissions {
+				if strings.EqualFold(strings.TrimPrefix(string(rule.Action), odrlPrefix), val) {
+					return true
+				}
+			}
+		}
+	case FilterText:
+		if strings.Contains(strings.ToLower(ds.DctTitle), val) {
+			return true
+		}
+
+		for _, desc := range ds.DctDescription {
+			if strings.Contains(strings.ToLower(desc.Value), val) {
+				return true
+			}
+		}
+
+		for _, kw := range ds.DcatKeyword {
+			if strings.Contains(strings.ToLower(string(kw)), val) {
+				return true
+			}
+		}
+	}
+	return false
+}
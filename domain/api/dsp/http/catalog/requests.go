@@ -6,7 +6,14 @@ package catalog
 type Request struct {
 	Context      string   `json:"@context" default:"https://w3id.org/dspace/2024/1/context.json"`
 	Type         string   `json:"@type" default:"dspace:CatalogRequestMessage"`
-	DspaceFilter []string `json:"dspace:filter"` // optional and implementation-specific
+	DspaceFilter []string `json:"dspace:filter,omitempty"` // optional, expressions as defined in filter.go
+}
+
+// Page selects at most Limit datasets following the dataset referred by the opaque
+// Cursor, where the first page is selected if the cursor is empty
+type Page struct {
+	Limit  int
+	Cursor string
 }
 
 type DatasetRequest struct {
@@ -0,0 +1,281 @@
+package negotiation
+
+import (
+	"encoding/json"
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Participants proposing offers during a negotiation
+const (
+	ProposerConsumer = `consumer`
+	ProposerProvider = `provider`
+)
+
+// Round is an offer proposed by either participant during a contract negotiation,
+// along with the changes of the offer compared to the published offer
+type Round struct {
+	Timestamp time.Time  `json:"timestamp"`
+	Proposer  string     `json:"proposer"`
+	Offer     odrl.Offer `json:"offer"`
+	Changes   []Change   `json:"changes"`
+}
+
+// Kinds of changes of a proposed offer compared to the published offer
+const (
+	ChangeConstraint = `constraint` // constraint of a rule present in both offers
+	ChangeRule       = `rule`       // rule or obligation present in only one of the offers
+	ChangeDuty       = `duty`       // duty or remedy of a rule which is added, removed or altered
+)
+
+// Change is a difference between a proposed offer and the published offer. Rules and
+// duties of the same action are paired in order once the identical ones are matched, and
+// constraints are matched by their left operands and operators. A constraint is described
+// by its operator and right operand, whereas rules and duties are described by their
+// compact JSON-LD form. The published value is empty if the constraint, rule or duty is
+// added and the proposed value is empty if it is removed.
+type Change struct {
+	Kind        string      `json:"kind"`
+	Rule        string      `json:"rule"`
+	Action      odrl.Action `json:"action"`
+	Duty        odrl.Action `json:"duty,omitempty"`
+	LeftOperand string      `json:"leftOperand,omitempty"`
+	Operator    string      `json:"operator,omitempty"`
+	Published   string      `json:"published"`
+	Proposed    string      `json:"proposed"`
+}
+
+// Changes compares the permissions, prohibitions and obligations of the proposed offer
+// with those of the published offer in both directions, so that rules removed from the
+// proposed offer are included as well
+func Changes(proposed, published odrl.Offer) []Change {
+	changes := ruleChanges(`odrl:permission`, proposed.Permissions, published.Permissions)
+	changes = append(changes, ruleChanges(`odrl:prohibition`, proposed.Prohibitions, published.Prohibitions)...)
+	// obligations are rules of the offer itself rather than duties of a rule
+	for _, c := range dutyChanges(proposed.Obligations, published.Obligations) {
+		changes = append(changes, Change{Kind: ChangeRule, Rule: `odrl:obligation`, Action: c.Duty,
+			Published: c.Published, Proposed: c.Proposed})
+	}
+	return changes
+}
+
+func ruleChanges(typ string, proposed, published []odrl.Rule) []Change {
+	var changes []Change
+	for _, action := range ruleActions(proposed, published) {
+		props, pubs := unmatched(rules(proposed, action), rules(published, action), func(prop, pub odrl.Rule) bool {
+			return len(ruleDiff(prop, pub)) == 0
+		})
+
+		for i := 0; i < len(props) || i < len(pubs); i++ {
+			if i >= len(props) || i >= len(pubs) {
+				changes = append(changes, Change{
+					Kind:      ChangeRule,
+					Rule:      typ,
+					Action:    odrl.Action(action),
+					Published: describe(pubs, i),
+					Proposed:  describe(props, i),
+				})
+				continue
+			}
+
+			for _, c := range ruleDiff(props[i], pubs[i]) {
+				c.Rule, c.Action = typ, props[i].Action
+				changes = append(changes, c)
+			}
+		}
+	}
+	return changes
+}
+
+// ruleDiff returns the changes of the constraints, duties and remedies of the proposed rule
+// compared to the published rule of the same action, where the rule of each change is left empty
+func ruleDiff(proposed, published odrl.Rule) []Change {
+	var changes []Change
+	pubTerms := terms(published.Constraints)
+	propTerms := terms(proposed.Constraints)
+	for _, key := range keys(proposed.Constraints, published.Constraints) {
+		if propTerms[key] == pubTerms[key] {
+			continue
+		}
+
+		changes = append(changes, Change{
+			Kind:        ChangeConstraint,
+			LeftOperand: key.leftOperand,
+			Operator:    key.operator,
+			Published:   pubTerms[key],
+			Proposed:    propTerms[key],
+		})
+	}
+
+	return append(changes, append(dutyChanges(proposed.Duties, published.Duties),
+		dutyChanges(proposed.Remedies, published.Remedies)...)...)
+}
+
+// dutyChanges returns the duties which are present in only one of the lists or have
+// different constraints or consequences, where the rule of each change is left empty
+func dutyChanges(proposed, published []odrl.Duty) []Change {
+	var changes []Change
+	for _, action := range dutyActions(proposed, published) {
+		props, pubs := unmatched(duties(proposed, action), duties(published, action), sameDuty)
+		for i := 0; i < len(props) || i < len(pubs); i++ {
+			changes = append(changes, Change{
+				Kind:      ChangeDuty,
+				Duty:      odrl.Action(action),
+				Published: describe(pubs, i),
+				Proposed:  describe(props, i),
+			})
+		}
+	}
+	return changes
+}
+
+func sameDuty(proposed, published odrl.Duty) bool {
+	if !reflect.DeepEqual(terms(proposed.Constraints), terms(published.Constraints)) {
+		return false
+	}
+	return len(dutyChanges(proposed.Consequences, published.Consequences)) == 0
+}
+
+// unmatched removes the proposed values which are the same as a published value, where each
+// published value is matched at most once, and returns the remaining values of both lists in order
+func unmatched[T any](proposed, published []T, same func(prop, pub T) bool) (props, pubs []T) {
+	matched := make([]bool, len(published))
+	for _, prop := range proposed {
+		found := false
+		for i, pub := range published {
+			if !matched[i] && same(prop, pub) {
+				matched[i], found = true, true
+				break
+			}
+		}
+
+		if !found {
+			props = append(props, prop)
+		}
+	}
+
+	for i, pub := range published {
+		if !matched[i] {
+			pubs = append(pubs, pub)
+		}
+	}
+	return props, pubs
+}
+
+// ruleActions returns the actions of both proposed and published rules in order
+func ruleActions(proposed, published []odrl.Rule) []string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, r := range append(append([]odrl.Rule{}, proposed...), published...) {
+		if action := odrl.Prefixed(string(r.Action)); !seen[action] {
+			seen[action] = true
+			list = append(list, action)
+		}
+	}
+	return list
+}
+
+// dutyActions returns the actions of both proposed and published duties in order
+func dutyActions(proposed, published []odrl.Duty) []string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, d := range append(append([]odrl.Duty{}, proposed...), published...) {
+		if action := odrl.Prefixed(string(d.Action)); !seen[action] {
+			seen[action] = true
+			list = append(list, action)
+		}
+	}
+	return list
+}
+
+func rules(list []odrl.Rule, action string) []odrl.Rule {
+	var matched []odrl.Rule
+	for _, r := range list {
+		if odrl.Prefixed(string(r.Action)) == action {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+func duties(list []odrl.Duty, action string) []odrl.Duty {
+	var matched []odrl.Duty
+	for _, d := range list {
+		if odrl.Prefixed(string(d.Action)) == action {
+			matched = append(matched, d)
+		}
+	}
+	return matched
+}
+
+// describe returns the compact JSON-LD form of the rule or duty at the index, or an empty
+// string if it is not present
+func describe[T any](list []T, i int) string {
+	if i >= len(list) {
+		return ``
+	}
+	data, _ := json.Marshal(list[i])
+	return string(data)
+}
+
+// term identifies the constraints of a rule, where logical constraints have neither a left
+// operand nor an operator
+type term struct {
+	leftOperand string
+	operator    string
+}
+
+// terms describes the constraints of each left operand and operator, so that both bounds of
+// a range are compared. Constraints of the same term are sorted to be compared as a multiset,
+// and logical constraints are described by their compact JSON-LD form.
+func terms(constraints []odrl.Constraint) map[term]string {
+	vals := make(map[term][]string)
+	for _, c := range constraints {
+		if c.LeftOperand == `` {
+			data, _ := json.Marshal(c)
+			vals[term{}] = append(vals[term{}], string(data))
+			continue
+		}
+
+		val := []string{odrl.Prefixed(c.Operator), c.RightOperand.String()}
+		if c.RightOperandReference != `` {
+			val[1] = c.RightOperandReference
+		}
+		if c.Unit != `` {
+			val = append(val, c.Unit)
+		}
+
+		key := constraintTerm(c)
+		vals[key] = append(vals[key], strings.Join(val, ` `))
+	}
+
+	described := make(map[term]string)
+	for key, list := range vals {
+		sort.Strings(list)
+		described[key] = strings.Join(list, `, `)
+	}
+	return described
+}
+
+// keys returns the terms of both proposed and published constraints in order
+func keys(proposed, published []odrl.Constraint) []term {
+	var list []term
+	seen := make(map[term]bool)
+	for _, c := range append(append([]odrl.Constraint{}, proposed...), published...) {
+		if key := constraintTerm(c); !seen[key] {
+			seen[key] = true
+			list = append(list, key)
+		}
+	}
+	return list
+}
+
+func constraintTerm(c odrl.Constraint) term {
+	if c.LeftOperand == `` {
+		return term{}
+	}
+	return term{leftOperand: odrl.Prefixed(c.LeftOperand), operator: odrl.Prefixed(c.Operator)}
+}
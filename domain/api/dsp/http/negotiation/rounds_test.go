@@ -0,0 +1,144 @@
+package negotiation
+
+import (
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"testing"
+)
+
+func TestChanges(t *testing.T) {
+	count := func(val string) odrl.Constraint {
+		return odrl.Constraint{LeftOperand: `count`, Operator: `lteq`,
+			RightOperand: odrl.TypedLiteral(val, odrl.DataTypeInteger)}
+	}
+	published := odrl.Offer{
+		Permissions: []odrl.Rule{{Action: `odrl:use`, Constraints: []odrl.Constraint{count(`10`)},
+			Duties: []odrl.Duty{{Action: `odrl:attribute`}}}},
+		Prohibitions: []odrl.Rule{{Action: `odrl:distribute`}},
+		Obligations:  []odrl.Duty{{Action: `odrl:compensate`}},
+	}
+
+	tests := []struct {
+		name     string
+		proposed func(ofr odrl.Offer) odrl.Offer
+		changes  []Change
+	}{
+		{`published offer`, func(ofr odrl.Offer) odrl.Offer { return ofr }, nil},
+		{`plain actions`, func(ofr odrl.Offer) odrl.Offer {
+			ofr.Permissions = []odrl.Rule{{Action: `use`, Constraints: []odrl.Constraint{count(`10`)},
+				Duties: []odrl.Duty{{Action: `attribute`}}}}
+			ofr.Prohibitions = []odrl.Rule{{Action: `distribute`}}
+			return ofr
+		}, nil},
+		{`altered constraint`, func(ofr odrl.Offer) odrl.Offer {
+			ofr.Permissions = []odrl.Rule{{Action: `odrl:use`, Constraints: []odrl.Constraint{count(`5`)},
+				Duties: []odrl.Duty{{Action: `odrl:attribute`}}}}
+			return ofr
+		}, []Change{{Kind: ChangeConstraint, Rule: `odrl:permission`, Action: `odrl:use`,
+			LeftOperand: `odrl:count`}}},
+		{`removed permission`, func(ofr odrl.Offer) odrl.Offer {
+			ofr.Permissions = nil
+			return ofr
+		}, []Change{{Kind: ChangeRule, Rule: `odrl:permission`, Action: `odrl:use`}}},
+		{`added permission`, func(ofr odrl.Offer) odrl.Offer {
+			ofr.Permissions = append([]odrl.Rule{{Action: `odrl:modify`}}, ofr.Permissions...)
+			return ofr
+		}, []Change{{Kind: ChangeRule, Rule: `odrl:permission`, Action: `odrl:modify`}}},
+		{`removed prohibition`, func(ofr odrl.Offer) odrl.Offer {
+			ofr.Prohibitions = nil
+			return ofr
+		}, []Change{{Kind: ChangeRule, Rule: `odrl:prohibition`, Action: `odrl:distribute`}}},
+		{`removed duty`, func(ofr odrl.Offer) odrl.Offer {
+			ofr.Permissions = []odrl.Rule{{Action: `odrl:use`, Constraints: []odrl.Constraint{count(`10`)}}}
+			return ofr
+		}, []Change{{Kind: ChangeDuty, Rule: `odrl:permission`, Action: `odrl:use`, Duty: `odrl:attribute`}}},
+		{`altered duty`, func(ofr odrl.Offer) odrl.Offer {
+			ofr.Permissions = []odrl.Rule{{Action: `odrl:use`, Constraints: []odrl.Constraint{count(`10`)},
+				Duties: []odrl.Duty{{Action: `odrl:attribute`, Constraints: []odrl.Constraint{count(`1`)}}}}}
+			return ofr
+		}, []Change{{Kind: ChangeDuty, Rule: `odrl:permission`, Action: `odrl:use`, Duty: `odrl:attribute`}}},
+		{`removed obligation`, func(ofr odrl.Offer) odrl.Offer {
+			ofr.Obligations = nil
+			return ofr
+		}, []Change{{Kind: ChangeRule, Rule: `odrl:obligation`, Action: `odrl:compensate`}}},
+	}
+
+	for _, test := range tests {
+		changes := Changes(test.proposed(published), published)
+		if len(changes) != len(test.changes) {
+			t.Errorf("%s: Changes returned %d changes (%v), want %d", test.name, len(changes), changes,
+				len(test.changes))
+			continue
+		}
+
+		for i, c := range changes {
+			want := test.changes[i]
+			if c.Kind != want.Kind || c.Rule != want.Rule || c.Action != want.Action || c.Duty != want.Duty ||
+				c.LeftOperand != want.LeftOperand {
+				t.Errorf("%s: Changes returned %+v, want %+v", test.name, c, want)
+			}
+
+			if c.Published == c.Proposed {
+				t.Errorf("%s: published and proposed values of the change are equal (%s)", test.name, c.Published)
+			}
+		}
+	}
+}
+
+func TestChanges_Range(t *testing.T) {
+	bound := func(operator, val string) odrl.Constraint {
+		return odrl.Constraint{LeftOperand: `dateTime`, Operator: operator,
+			RightOperand: odrl.TypedLiteral(val, odrl.DataTypeDateTime)}
+	}
+	use := func(constraints ...odrl.Constraint) odrl.Rule {
+		return odrl.Rule{Action: `odrl:use`, Constraints: constraints}
+	}
+	published := odrl.Offer{Permissions: []odrl.Rule{
+		use(bound(`gteq`, `2024-01-01T00:00:00Z`), bound(`lteq`, `2024-12-31T00:00:00Z`)),
+		use(bound(`gteq`, `2025-01-01T00:00:00Z`)),
+	}}
+
+	tests := []struct {
+		name        string
+		permissions []odrl.Rule
+		operators   []string
+	}{
+		{`reordered rules and bounds`, []odrl.Rule{
+			use(bound(`gteq`, `2025-01-01T00:00:00Z`)),
+			use(bound(`lteq`, `2024-12-31T00:00:00Z`), bound(`gteq`, `2024-01-01T00:00:00Z`)),
+		}, nil},
+		{`altered lower bound`, []odrl.Rule{
+			use(bound(`gteq`, `2023-01-01T00:00:00Z`), bound(`lteq`, `2024-12-31T00:00:00Z`)),
+			use(bound(`gteq`, `2025-01-01T00:00:00Z`)),
+		}, []string{`gteq`}},
+		{`altered upper bound`, []odrl.Rule{
+			use(bound(`gteq`, `2024-01-01T00:00:00Z`), bound(`lteq`, `2025-12-31T00:00:00Z`)),
+			use(bound(`gteq`, `2025-01-01T00:00:00Z`)),
+		}, []string{`lteq`}},
+		{`duplicated bound`, []odrl.Rule{
+			use(bound(`gteq`, `2024-01-01T00:00:00Z`), bound(`gteq`, `2023-01-01T00:00:00Z`),
+				bound(`lteq`, `2024-12-31T00:00:00Z`)),
+			use(bound(`gteq`, `2025-01-01T00:00:00Z`)),
+		}, []string{`gteq`}},
+	}
+
+	for _, test := range tests {
+		changes := Changes(odrl.Offer{Permissions: test.permissions}, published)
+		if len(changes) != len(test.operators) {
+			t.Errorf("%s: Changes returned %d changes (%v), want %d", test.name, len(changes), changes,
+				len(test.operators))
+			continue
+		}
+
+		for i, c := range changes {
+			if c.Kind != ChangeConstraint || c.LeftOperand != `odrl:dateTime` || c.Operator != `odrl:`+test.operators[i] {
+				t.Errorf("%s: Changes returned %+v, want a change of the %s bound", test.name, c, test.operators[i])
+			}
+		}
+	}
+
+	// a removed rule of an action is reported even though another rule of the action remains
+	changes := Changes(odrl.Offer{Permissions: published.Permissions[:1]}, published)
+	if len(changes) != 1 || changes[0].Kind != ChangeRule || changes[0].Proposed != `` {
+		t.Errorf("removed rule: Changes returned %v, want the removed rule", changes)
+	}
+}
@@ -0,0 +1,25 @@
+package negotiation
+
+// transitions defines the state machine of the Contract Negotiation Protocol where
+// each state maps to the states that can follow it. An empty state refers to a
+// negotiation which does not exist yet. Terminal states (FINALIZED and TERMINATED)
+// do not have any transition.
+var transitions = map[State][]State{
+	``:             {StateRequested, StateOffered},
+	StateRequested: {StateOffered, StateAgreed, StateTerminated},
+	StateOffered:   {StateRequested, StateAccepted, StateTerminated},
+	StateAccepted:  {StateAgreed, StateTerminated},
+	StateAgreed:    {StateVerified, StateTerminated},
+	StateVerified:  {StateFinalized, StateTerminated},
+}
+
+// ValidTransition returns true if the negotiation can move from the current state
+// to the next state
+func ValidTransition(current, next State) bool {
+	for _, s := range transitions[current] {
+		if s == next {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package negotiation
+
+import "testing"
+
+func TestValidTransition(t *testing.T) {
+	states := []State{``, StateRequested, StateOffered, StateAccepted, StateAgreed, StateVerified,
+		StateFinalized, StateTerminated}
+	legal := map[[2]State]bool{
+		{``, StateRequested}:              true,
+		{``, StateOffered}:                true,
+		{StateRequested, StateOffered}:    true,
+		{StateRequested, StateAgreed}:     true,
+		{StateRequested, StateTerminated}: true,
+		{StateOffered, StateRequested}:    true,
+		{StateOffered, StateAccepted}:     true,
+		{StateOffered, StateTerminated}:   true,
+		{StateAccepted, StateAgreed}:      true,
+		{StateAccepted, StateTerminated}:  true,
+		{StateAgreed, StateVerified}:      true,
+		{StateAgreed, StateTerminated}:    true,
+		{StateVerified, StateFinalized}:   true,
+		{StateVerified, StateTerminated}:  true,
+	}
+
+	for _, from := range states {
+		for _, to := range states {
+			want := legal[[2]State{from, to}]
+			if got := ValidTransition(from, to); got != want {
+				t.Errorf("ValidTransition(%q, %q) = %t, want %t", from, to, got, want)
+			}
+		}
+	}
+}
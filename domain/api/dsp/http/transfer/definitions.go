@@ -19,6 +19,11 @@ const (
 	HTTPPush DataTransferType = `HTTP_PUSH`
 )
 
+// Supported returns true if the transfer type can be handled by the connector
+func (t DataTransferType) Supported() bool {
+	return t == HTTPPull || t == HTTPPush
+}
+
 // Message types
 const (
 	MsgTypeProcess          = `dspace:TransferProcess`
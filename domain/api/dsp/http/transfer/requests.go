@@ -6,7 +6,8 @@ type Request struct {
 	ConsPId      string           `json:"dspace:consumerPid"`
 	AgreementId  string           `json:"dspace:agreementId"`
 	Format       DataTransferType `json:"dct:format"`
-	Address      Address          `json:"dspace:address"` // required only if format is a push transfer
+	MediaType    string           `json:"dcat:mediaType,omitempty"` // optional, to select among distributions of the format
+	Address      Address          `json:"dspace:address"`           // required only if format is a push transfer
 	CallbackAddr string           `json:"dspace:callbackAddress"`
 }
 
@@ -0,0 +1,41 @@
+package transfer
+
+import "time"
+
+// transitions defines the state machine of the Transfer Process Protocol where each
+// state maps to the states that can follow it. An empty state refers to a transfer
+// process which does not exist yet. Terminal states (COMPLETED and TERMINATED) do
+// not have any transition.
+var transitions = map[State][]State{
+	``:             {StateRequested},
+	StateRequested: {StateStarted, StateTerminated},
+	StateStarted:   {StateSuspended, StateCompleted, StateTerminated},
+	StateSuspended: {StateStarted, StateTerminated},
+}
+
+// ValidTransition returns true if the transfer process can move from the current
+// state to the next state
+func ValidTransition(current, next State) bool {
+	for _, s := range transitions[current] {
+		if s == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Cause describes the participant who initiated a state change of a transfer
+// process along with the code and reasons, if any, included in the message
+type Cause struct {
+	Initiator string        `json:"initiator"`
+	Code      string        `json:"code,omitempty"`
+	Reasons   []interface{} `json:"reasons,omitempty"`
+}
+
+// Transition is an entry of the history of a transfer process
+type Transition struct {
+	Timestamp time.Time `json:"timestamp"`
+	From      State     `json:"from"`
+	To        State     `json:"to"`
+	Cause
+}
@@ -0,0 +1,26 @@
+package transfer
+
+import "testing"
+
+func TestValidTransition(t *testing.T) {
+	states := []State{``, StateRequested, StateStarted, StateSuspended, StateCompleted, StateTerminated}
+	legal := map[[2]State]bool{
+		{``, StateRequested}:              true,
+		{StateRequested, StateStarted}:    true,
+		{StateRequested, StateTerminated}: true,
+		{StateStarted, StateSuspended}:    true,
+		{StateStarted, StateCompleted}:    true,
+		{StateStarted, StateTerminated}:   true,
+		{StateSuspended, StateStarted}:    true,
+		{StateSuspended, StateTerminated}: true,
+	}
+
+	for _, from := range states {
+		for _, to := range states {
+			want := legal[[2]State{from, to}]
+			if got := ValidTransition(from, to); got != want {
+				t.Errorf("ValidTransition(%q, %q) = %t, want %t", from, to, got, want)
+			}
+		}
+	}
+}
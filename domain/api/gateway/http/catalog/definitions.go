@@ -1,8 +1,26 @@
 package catalog
 
+// Path parameters
 const (
+	ParamCatalogId = `catalogId`
+	ParamPolicyId  = `policyId`
+	ParamDatasetId = `datasetId`
+)
+
+const (
+	CreateCatalogEndpoint     = `/gateway/create-catalog`
+	UpdateCatalogEndpoint     = `/gateway/update-catalog/{` + ParamCatalogId + `}`
+	DeleteCatalogEndpoint     = `/gateway/delete-catalog/{` + ParamCatalogId + `}`
 	CreatePolicyEndpoint      = `/gateway/create-policy`
+	UpdatePolicyEndpoint      = `/gateway/update-policy/{` + ParamPolicyId + `}`
+	DeletePolicyEndpoint      = `/gateway/delete-policy/{` + ParamPolicyId + `}`
+	GetPolicyEndpoint         = `/gateway/policy/{` + ParamPolicyId + `}`
+	GetPoliciesEndpoint       = `/gateway/policies`
 	CreateDatasetEndpoint     = `/gateway/create-dataset`
+	UpdateDatasetEndpoint     = `/gateway/update-dataset/{` + ParamDatasetId + `}`
+	DeleteDatasetEndpoint     = `/gateway/delete-dataset/{` + ParamDatasetId + `}`
+	GetDatasetEndpoint        = `/gateway/dataset/{` + ParamDatasetId + `}`
+	GetDatasetsEndpoint       = `/gateway/datasets`
 	RequestCatalogEndpoint    = `/gateway/request-catalog`
 	RequestDatasetEndpoint    = `/gateway/request-dataset`
 	GetStoredCatalogsEndpoint = `/gateway/catalogs`
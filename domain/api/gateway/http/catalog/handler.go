@@ -3,8 +3,19 @@ package catalog
 import "net/http"
 
 type Handler interface {
+	CreateCatalog(w http.ResponseWriter, r *http.Request)
+	UpdateCatalog(w http.ResponseWriter, r *http.Request)
+	DeleteCatalog(w http.ResponseWriter, r *http.Request)
 	CreatePolicy(w http.ResponseWriter, r *http.Request)
+	UpdatePolicy(w http.ResponseWriter, r *http.Request)
+	DeletePolicy(w http.ResponseWriter, r *http.Request)
+	GetPolicy(w http.ResponseWriter, r *http.Request)
+	GetPolicies(w http.ResponseWriter, r *http.Request)
 	CreateDataset(w http.ResponseWriter, r *http.Request)
+	UpdateDataset(w http.ResponseWriter, r *http.Request)
+	DeleteDataset(w http.ResponseWriter, r *http.Request)
+	GetDataset(w http.ResponseWriter, r *http.Request)
+	GetDatasets(w http.ResponseWriter, r *http.Request)
 	RequestCatalog(w http.ResponseWriter, r *http.Request)
 	RequestDataset(w http.ResponseWriter, r *http.Request)
 	GetStoredCatalogs(w http.ResponseWriter, r *http.Request)
@@ -1,7 +1,8 @@
 package catalog
 
 type Request struct {
-	ProviderEndpoint string `json:"providerEndpoint"`
+	ProviderEndpoint string   `json:"providerEndpoint"`
+	Filter           []string `json:"filter"` // e.g. keyword:weather, format:HTTP_PULL|HTTP_PUSH
 }
 
 type DatasetRequest struct {
@@ -9,29 +10,94 @@ type DatasetRequest struct {
 	ProviderEndpoint string `json:"providerEndpoint"`
 }
 
+// CreateCatalogRequest creates a catalog nested within the parent catalog, which is the
+// root catalog of the connector if the parent ID is not provided
+type CreateCatalogRequest struct {
+	ParentId     string   `json:"parentId"`
+	Title        string   `json:"title"`
+	Descriptions []string `json:"descriptions"`
+	Keywords     []string `json:"keywords"`
+	Endpoints    []string `json:"endpoints"` // access services of the catalog
+}
+
+type UpdateCatalogRequest struct {
+	Title        string   `json:"title"`
+	Descriptions []string `json:"descriptions"`
+	Keywords     []string `json:"keywords"`
+	Endpoints    []string `json:"endpoints"`
+}
+
 type CreatePolicyRequest struct {
 	Target       string `json:"target"`
 	Permissions  []Rule `json:"permissions"`
 	Prohibitions []Rule `json:"prohibitions"`
-	Obligations  []Rule `json:"obligations"`
+	Obligations  []Duty `json:"obligations"`
+}
+
+// UpdatePolicyRequest replaces the target and the rules of the policy
+type UpdatePolicyRequest struct {
+	Target       string `json:"target"`
+	Permissions  []Rule `json:"permissions"`
+	Prohibitions []Rule `json:"prohibitions"`
+	Obligations  []Duty `json:"obligations"`
 }
 
 type CreateDatasetRequest struct {
+	CatalogId     string         `json:"catalogId"` // defaults to the root catalog
+	Title         string         `json:"title"`
+	Descriptions  []string       `json:"descriptions"`
+	OfferIds      []string       `json:"offerIds"`
+	Keywords      []string       `json:"keywords"`
+	Distributions []Distribution `json:"distributions"`
+}
+
+// UpdateDatasetRequest replaces the metadata and the offers of the dataset, whereas
+// distributions of the dataset can not be updated
+type UpdateDatasetRequest struct {
 	Title        string   `json:"title"`
 	Descriptions []string `json:"descriptions"`
-	Endpoints    []string `json:"endpoints"`
 	OfferIds     []string `json:"offerIds"`
 	Keywords     []string `json:"keywords"`
-	Format       string   `json:"format"`
 }
 
+type Distribution struct {
+	Format     string   `json:"format"`
+	MediaType  string   `json:"mediaType"`
+	Endpoints  []string `json:"endpoints"`
+	DataSource Source   `json:"dataSource"`
+}
+
+// Source refers to the content of the distribution in a data source configured for the connector
+type Source struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+}
+
+// Rule is either a permission with its duties or a prohibition with its remedies
 type Rule struct {
 	Action      string       `json:"action"`
 	Constraints []Constraint `json:"constraints"`
+	Duties      []Duty       `json:"duties"`
+	Remedies    []Duty       `json:"remedies"`
+}
+
+type Duty struct {
+	Action       string       `json:"action"`
+	Constraints  []Constraint `json:"constraints"`
+	Consequences []Duty       `json:"consequences"`
 }
 
+// Constraint is either an atomic constraint or a logical constraint which combines
+// other constraints by and, or and xone operands. Lists of right operands are separated
+// by commas, and the datatype '@id' denotes that the right operand is an IRI.
 type Constraint struct {
-	LeftOperand  string `json:"leftOperand"`
-	Operator     string `json:"operator"`
-	RightOperand string `json:"rightOperand"`
+	LeftOperand           string       `json:"leftOperand"`
+	Operator              string       `json:"operator"`
+	RightOperand          string       `json:"rightOperand"`
+	DataType              string       `json:"dataType"` // e.g. xsd:dateTime
+	RightOperandReference string       `json:"rightOperandReference"`
+	Unit                  string       `json:"unit"`
+	And                   []Constraint `json:"and"`
+	Or                    []Constraint `json:"or"`
+	Xone                  []Constraint `json:"xone"`
 }
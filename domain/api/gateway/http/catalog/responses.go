@@ -1,5 +1,9 @@
 package catalog
 
+type CatalogResponse struct {
+	Id string `json:"catalogId"`
+}
+
 type PolicyResponse struct {
 	Id string `json:"policyId"`
 }
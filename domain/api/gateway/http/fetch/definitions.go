@@ -0,0 +1,12 @@
+package fetch
+
+// Path parameters
+const (
+	ParamFetchId = `fetchId`
+)
+
+// endpoints exposed by gateway API
+const (
+	NegotiateAndFetchEndpoint = `/gateway/negotiate-and-fetch`
+	GetFetchEndpoint          = `/gateway/fetch/{` + ParamFetchId + `}`
+)
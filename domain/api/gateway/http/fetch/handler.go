@@ -0,0 +1,8 @@
+package fetch
+
+import "net/http"
+
+type Handler interface {
+	NegotiateAndFetch(w http.ResponseWriter, r *http.Request)
+	GetFetch(w http.ResponseWriter, r *http.Request)
+}
@@ -0,0 +1,11 @@
+package fetch
+
+type Request struct {
+	ProviderEndpoint string            `json:"providerEndpoint"`
+	DatasetId        string            `json:"datasetId"`
+	OfferId          string            `json:"offerId"`
+	Constraints      map[string]string `json:"constraints"`
+	TransferFormat   string            `json:"transferFormat"`
+	MediaType        string            `json:"mediaType"`
+	SinkEndpoint     string            `json:"sinkEndpoint"`
+}
@@ -0,0 +1,5 @@
+package fetch
+
+type Response struct {
+	FetchId string `json:"fetchId"`
+}
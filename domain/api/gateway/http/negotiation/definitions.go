@@ -17,4 +17,5 @@ const (
 	VerifyAgreementEndpoint   = `/gateway/verify-agreement/{` + ParamConsumerPid + `}`
 	FinalizeContractEndpoint  = `/gateway/finalize-contract/{` + ParamProviderPid + `}`
 	TerminateContractEndpoint = `/gateway/terminate-contract`
+	RoundsEndpoint            = `/gateway/negotiation/rounds/{` + ParamProviderPid + `}`
 )
@@ -11,4 +11,5 @@ type Handler interface {
 	VerifyAgreement(w http.ResponseWriter, r *http.Request)
 	FinalizeContract(w http.ResponseWriter, r *http.Request)
 	TerminateContract(w http.ResponseWriter, r *http.Request)
+	GetRounds(w http.ResponseWriter, r *http.Request)
 }
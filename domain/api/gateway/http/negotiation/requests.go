@@ -8,9 +8,11 @@ type ContractRequest struct {
 }
 
 type OfferRequest struct {
-	ProviderPid  string `json:"providerPid"`
-	OfferId      string `json:"offerId"`
-	ConsumerAddr string `json:"consumerAddr"`
+	ProviderPid  string            `json:"providerPid"`
+	OfferId      string            `json:"offerId"`
+	ConsumerAddr string            `json:"consumerAddr"`
+	ConsumerId   string            `json:"consumerId"`
+	Constraints  map[string]string `json:"constraints"`
 }
 
 type AgreeContractRequest struct {
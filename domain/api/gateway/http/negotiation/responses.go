@@ -1,5 +1,7 @@
 package negotiation
 
+import "github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
+
 type ContractRequestResponse struct {
 	Id string `json:"contractNegotiationId"`
 }
@@ -7,3 +9,8 @@ type ContractRequestResponse struct {
 type ContractAgreementResponse struct {
 	Id string `json:"contractAgreementId"`
 }
+
+type RoundsResponse struct {
+	ProviderPid string              `json:"providerPid"`
+	Rounds      []negotiation.Round `json:"rounds"`
+}
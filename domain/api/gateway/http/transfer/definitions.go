@@ -9,4 +9,6 @@ const (
 	SuspendEndpoint    = `/gateway/transfer/suspend`
 	CompleteEndpoint   = `/gateway/transfer/complete`
 	TerminateEndpoint  = `/gateway/transfer/terminate`
+	HistoryEndpoint    = `/gateway/transfer/history/{` + api.ParamPid + `}`
+	ProgressEndpoint   = `/gateway/transfer/progress/{` + api.ParamPid + `}`
 )
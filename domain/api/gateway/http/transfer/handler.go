@@ -9,4 +9,6 @@ type Handler interface {
 	SuspendTransfer(w http.ResponseWriter, r *http.Request)
 	CompleteTransfer(w http.ResponseWriter, r *http.Request)
 	TerminateTransfer(w http.ResponseWriter, r *http.Request)
+	GetHistory(w http.ResponseWriter, r *http.Request)
+	GetProgress(w http.ResponseWriter, r *http.Request)
 }
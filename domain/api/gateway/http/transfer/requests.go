@@ -1,12 +1,35 @@
 package transfer
 
+import "encoding/json"
+
 type Request struct {
 	TransferFormat   string `json:"transferFormat"`
+	MediaType        string `json:"mediaType"`
 	AgreementId      string `json:"agreementId"`
 	SinkEndpoint     string `json:"sinkEndpoint"`
 	ProviderEndpoint string `json:"providerEndpoint"`
 }
 
+// UnmarshalJSON accepts the deprecated transferType attribute of earlier clients as the
+// transfer format if transferFormat is not provided
+func (r *Request) UnmarshalJSON(data []byte) error {
+	type request Request
+	var req struct {
+		request
+		TransferType string `json:"transferType"`
+	}
+
+	if err := json.Unmarshal(data, &req); err != nil {
+		return err
+	}
+
+	*r = Request(req.request)
+	if r.TransferFormat == `` {
+		r.TransferFormat = req.TransferType
+	}
+	return nil
+}
+
 type StartRequest struct {
 	Provider       bool   `json:"provider"`
 	TransferId     string `json:"transferProcessId"`
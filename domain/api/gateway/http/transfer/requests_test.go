@@ -0,0 +1,25 @@
+package transfer
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRequest_UnmarshalJSON(t *testing.T) {
+	tests := map[string]string{
+		`{"transferFormat": "HTTP_PULL", "agreementId": "agreement"}`:                              `HTTP_PULL`,
+		`{"transferType": "HTTP_PUSH", "agreementId": "agreement"}`:                                `HTTP_PUSH`,
+		`{"transferFormat": "HTTP_PULL", "transferType": "HTTP_PUSH", "agreementId": "agreement"}`: `HTTP_PULL`,
+	}
+
+	for data, format := range tests {
+		var req Request
+		if err := json.Unmarshal([]byte(data), &req); err != nil {
+			t.Fatalf("unmarshalling %s failed - %s", data, err)
+		}
+
+		if req.TransferFormat != format || req.AgreementId != `agreement` {
+			t.Errorf("%s was unmarshalled to %+v, want the format %s", data, req, format)
+		}
+	}
+}
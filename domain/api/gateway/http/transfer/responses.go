@@ -1,5 +1,20 @@
 package transfer
 
+import (
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"github.com/YasiruR/connector/domain/core/dataplane"
+)
+
 type Response struct {
 	TransferID string `json:"transferProcessId"`
 }
+
+type HistoryResponse struct {
+	TransferID  string                `json:"transferProcessId"`
+	Transitions []transfer.Transition `json:"transitions"`
+}
+
+type ProgressResponse struct {
+	TransferID string `json:"transferProcessId"`
+	dataplane.Progress
+}
@@ -3,15 +3,39 @@ package boot
 type Config struct {
 	DataSpace struct {
 		ParticipantId string `yaml:"participant_id"`
-		AssignerId    string `yaml:"assigner_id"`
-		AssigneeId    string `yaml:"assignee_id"`
+		AssignerId    string `yaml:"assigner_id"` // participant ID by default
+		AssigneeId    string `yaml:"assignee_id"` // participant ID by default
 	} `yaml:"data_space"`
+	IAM struct {
+		Type       string               `yaml:"type"`        // jwt (default)
+		PrivateKey string               `yaml:"private_key"` // PEM file of the Ed25519 key of the participant
+		Trusted    []TrustedParticipant `yaml:"trusted_participants"`
+		Audiences  []string             `yaml:"audiences"` // addresses of the DSP API (access services by default)
+		TokenTTL   int                  `yaml:"token_ttl"` // in seconds
+	} `yaml:"iam"`
 	Catalog struct {
 		Title          string   `yaml:"title"`
 		Keywords       []string `yaml:"keywords"`
 		AccessServices []string `yaml:"access_services"`
 		Descriptions   []string `yaml:"descriptions"`
+		PageSize       int      `yaml:"page_size"` // maximum datasets per catalog response
 	}
+	Negotiation struct {
+		Automation []NegotiationRule `yaml:"automation"` // rules are matched in order
+	} `yaml:"negotiation"`
+	DataPlane struct {
+		TokenTTL  int   `yaml:"token_ttl"`  // validity of access tokens in seconds
+		ChunkSize int64 `yaml:"chunk_size"` // bytes pushed to a sink per request
+	} `yaml:"data_plane"`
+	DataSources []DataSource `yaml:"data_sources"`
+	Storage     struct {
+		Type string `yaml:"type"` // local (default)
+		Path string `yaml:"path"`
+	} `yaml:"storage"`
+	Database struct {
+		Type string `yaml:"type"` // memory (default) or sqlite
+		Path string `yaml:"path"`
+	} `yaml:"database"`
 	Servers struct {
 		IP  string
 		DSP struct {
@@ -24,5 +48,40 @@ type Config struct {
 				Port int `yaml:"port"`
 			} `yaml:"http"`
 		} `yaml:"gateway"`
+		DataPlane struct {
+			HTTP struct {
+				Port int `yaml:"port"`
+			} `yaml:"http"`
+		} `yaml:"data_plane"`
 	} `yaml:"servers"`
 }
+
+// DataSource configures a backend in which the content of datasets is stored, where
+// the attributes used depend on the type
+type DataSource struct {
+	ID        string `yaml:"id"`
+	Type      string `yaml:"type"`       // file, directory, http or s3
+	Path      string `yaml:"path"`       // file or directory
+	URL       string `yaml:"url"`        // base URL of an http source or endpoint of an s3 source
+	Bucket    string `yaml:"bucket"`     // s3
+	Region    string `yaml:"region"`     // s3 (us-east-1 by default)
+	AccessKey string `yaml:"access_key"` // s3 (anonymous requests if empty)
+	SecretKey string `yaml:"secret_key"` // s3
+}
+
+// TrustedParticipant is a participant of the data space of which the tokens are accepted
+type TrustedParticipant struct {
+	ParticipantId string `yaml:"participant_id"`
+	PublicKey     string `yaml:"public_key"` // PEM file of the Ed25519 public key
+}
+
+// NegotiationRule automates the steps of the provider in contract negotiations of the
+// given offers with the given participants, where '*' matches any of them and an empty
+// list matches none
+type NegotiationRule struct {
+	Offers       []string `yaml:"offers"`
+	Participants []string `yaml:"participants"`
+	Agree        bool     `yaml:"agree"`    // agree to requests and accepted offers
+	Exact        bool     `yaml:"exact"`    // agree only if the published constraints are not altered
+	Finalize     bool     `yaml:"finalize"` // finalize verified agreements
+}
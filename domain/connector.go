@@ -20,16 +20,23 @@ type Stores struct {
 	stores.ContractNegotiationStore
 	stores.AgreementStore
 	stores.TransferStore
+	stores.DataPlaneStore
+	stores.FetchStore
 }
 
 type Servers struct {
-	DSP     api.Server
-	Gateway api.Server
+	DSP       api.Server
+	Gateway   api.Server
+	DataPlane api.Server
 }
 
 type Plugins struct {
 	pkg.Client
 	pkg.Database
 	pkg.URNService
+	pkg.PolicyEngine
+	pkg.IAM
+	pkg.Storage
+	pkg.DataSources
 	pkg.Log
 }
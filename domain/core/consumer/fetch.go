@@ -0,0 +1,47 @@
+package consumer
+
+import (
+	"github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"time"
+)
+
+type FetchState string
+
+// States of a fetch, where a fetch is negotiating until the contract negotiation is
+// finalized and transferring until the transfer process is completed or terminated
+const (
+	FetchNegotiating  FetchState = `NEGOTIATING`
+	FetchTransferring FetchState = `TRANSFERRING`
+	FetchCompleted    FetchState = `COMPLETED`
+	FetchFailed       FetchState = `FAILED`
+)
+
+// FetchRequest defines the dataset to be fetched from the provider and the transfer of
+// its data, where an offer of the dataset is selected if the offer ID is not provided
+type FetchRequest struct {
+	ProviderEndpoint string
+	DatasetId        string
+	OfferId          string
+	Constraints      map[string]string
+	Format           string
+	MediaType        string
+	SinkEndpoint     string
+}
+
+// Fetch is the progress of negotiating a contract for a dataset and transferring its
+// data, where the IDs are included once the corresponding step is reached
+type Fetch struct {
+	ID                string            `json:"fetchId"`
+	State             FetchState        `json:"state"`
+	ProviderEndpoint  string            `json:"providerEndpoint"`
+	DatasetId         string            `json:"datasetId"`
+	OfferId           string            `json:"offerId"`
+	ConsumerPid       string            `json:"contractNegotiationId,omitempty"`
+	NegotiationState  negotiation.State `json:"negotiationState,omitempty"`
+	AgreementId       string            `json:"agreementId,omitempty"`
+	TransferProcessId string            `json:"transferProcessId,omitempty"`
+	TransferState     transfer.State    `json:"transferState,omitempty"`
+	Error             string            `json:"error,omitempty"`
+	UpdatedAt         time.Time         `json:"updatedAt"`
+}
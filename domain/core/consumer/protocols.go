@@ -7,7 +7,7 @@ import (
 )
 
 type CatalogController interface {
-	RequestCatalog(endpoint string) (catalog.Response, error) // endpoint should be generic
+	RequestCatalog(endpoint string, filter []string) (catalog.Response, error) // endpoint should be generic
 	RequestDataset(id, endpoint string) (catalog.DatasetResponse, error)
 }
 
@@ -18,24 +18,35 @@ type NegotiationController interface {
 	TerminateContract(consumerPid, code string, reasons []string) error
 }
 
+// NegotiationHandler processes the messages sent by the authenticated participant, where
+// messages of an existing negotiation are rejected unless sent by its provider
 type NegotiationHandler interface {
-	HandleContractOffer(co negotiation.ContractOffer) (ack negotiation.Ack, err error)
-	HandleContractAgreement(ca negotiation.ContractAgreement) (negotiation.Ack, error)
-	HandleFinalizedEvent(e negotiation.ContractNegotiationEvent) (negotiation.Ack, error)
+	HandleContractOffer(co negotiation.ContractOffer, participantId string) (ack negotiation.Ack, err error)
+	HandleContractAgreement(ca negotiation.ContractAgreement, participantId string) (negotiation.Ack, error)
+	HandleFinalizedEvent(e negotiation.ContractNegotiationEvent, participantId string) (negotiation.Ack, error)
 }
 
 type TransferController interface {
 	GetProviderProcess(tpId string) (transfer.Process, error)
-	RequestTransfer(transferType, agreementId, sinkEndpoint, providerAddr string) (tpId string, err error)
+	RequestTransfer(transferType, mediaType, agreementId, sinkEndpoint, providerAddr string) (tpId string, err error)
 	SuspendTransfer(tpId, code string, reasons []interface{}) error
 	StartTransfer(tpId string) error
 	CompleteTransfer(tpId string) error
 	TerminateTransfer(tpId, code string, reasons []interface{}) error
 }
 
+// TransferHandler processes the messages sent by the authenticated participant, where
+// messages are rejected unless sent by the provider of the negotiation of the agreement
 type TransferHandler interface {
-	HandleTransferStart(sr transfer.StartRequest) (transfer.Ack, error)
-	HandleTransferSuspension(sr transfer.SuspendRequest) (transfer.Ack, error)
-	HandleTransferCompletion(cr transfer.CompleteRequest) (transfer.Ack, error)
-	HandleTransferTermination(tr transfer.TerminateRequest) (transfer.Ack, error)
+	HandleTransferStart(sr transfer.StartRequest, participantId string) (transfer.Ack, error)
+	HandleTransferSuspension(sr transfer.SuspendRequest, participantId string) (transfer.Ack, error)
+	HandleTransferCompletion(cr transfer.CompleteRequest, participantId string) (transfer.Ack, error)
+	HandleTransferTermination(tr transfer.TerminateRequest, participantId string) (transfer.Ack, error)
+}
+
+// Fetcher negotiates a contract for a dataset and requests the transfer of its data in
+// the background, where each step is tracked by the fetch returned by the ID
+type Fetcher interface {
+	NegotiateAndFetch(req FetchRequest) (fetchId string, err error)
+	Fetch(id string) (Fetch, error)
 }
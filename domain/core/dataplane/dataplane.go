@@ -0,0 +1,115 @@
+package dataplane
+
+import (
+	"errors"
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"io"
+	"time"
+)
+
+// Names of the endpoint properties included in data addresses
+const (
+	PropertyAuthorization = `authorization`
+	PropertyAuthType      = `authType`
+	AuthTypeBearer        = `bearer`
+)
+
+// Headers used to push data to a sink in chunks, where each chunk starts at the offset
+// and its digest follows RFC 9530 (e.g. sha-256=:<base64 encoded hash>:). Digest is
+// sent as a trailer since it is only known once the chunk is streamed.
+const (
+	HeaderOffset    = `Data-Offset`
+	HeaderSize      = `Data-Size`
+	HeaderDigest    = `Content-Digest`
+	DigestAlgorithm = `sha-256`
+)
+
+var (
+	// TypeCancelled is returned if a data transfer is stopped since the transfer process
+	// was suspended, completed or terminated meanwhile
+	TypeCancelled = errors.New("data transfer was cancelled")
+	// TypeCorrupted is returned if the data received by a sink does not match with the
+	// size or digest declared by the sender
+	TypeCorrupted = errors.New("received data is corrupted")
+)
+
+// Provider exposes the data of started pull transfers to consumers through a
+// per-transfer endpoint which is protected by a short-lived access token, and
+// pushes the data of started push transfers to the sinks of consumers
+type Provider interface {
+	// Activate grants access to the data of the transfer process and returns the
+	// data address to be sent to the consumer. Token of a resumed transfer process
+	// is reinstated so that the consumer can continue with the same address.
+	Activate(tpId string) (transfer.Address, error)
+	// Revoke invalidates the access token of the transfer process, if any, and
+	// cancels an ongoing push of its data
+	Revoke(tpId string) error
+	// Pull returns the data of the transfer process starting from the offset if the
+	// token is valid, where the size of the data is its total size
+	Pull(tpId, token string, offset int64) (Data, error)
+	// Push streams the data of the transfer process in chunks to the sink address
+	// provided by the consumer and returns once the sink has committed the data. Push
+	// of a resumed transfer process continues from the last acknowledged offset.
+	Push(tpId string) error
+}
+
+// Consumer receives the data of push transfers through a per-transfer sink endpoint
+// which is protected by a token that is valid until the data is committed
+type Consumer interface {
+	// SinkAddress returns the data address of the sink to be included in the request
+	// of a push transfer along with a new token
+	SinkAddress(tpId string) (transfer.Address, error)
+	// Receive stores a chunk of the data pushed for the transfer process at the offset
+	// if the token is valid and verifies its size and digest, if provided. Returns the
+	// offset up to which the data has been received.
+	Receive(tpId, token string, offset int64, data Data, digest func() string) (int64, error)
+	// Commit verifies that the data of the given size (-1 if unknown) has been received
+	// and makes it available in the storage, after which the token is invalidated
+	Commit(tpId, token string, size int64) (Receipt, error)
+}
+
+// Ack acknowledges a chunk of data received by a sink
+type Ack struct {
+	Offset int64 `json:"offset"`
+}
+
+// Receipt describes the data received by a sink
+type Receipt struct {
+	Size   int64  `json:"size"`
+	Digest string `json:"digest"`
+}
+
+// Source refers to the content of a dataset in one of the data sources configured
+// for the connector
+type Source struct {
+	ID   string `json:"id"`   // ID of the data source
+	Path string `json:"path"` // path of the content within the data source
+}
+
+// Data is the content of a dataset streamed by the data plane, where the caller
+// is responsible for closing the content
+type Data struct {
+	Content     io.ReadCloser
+	ContentType string
+	Size        int64 // -1 if unknown
+}
+
+// Progress of the data exchanged in a transfer process
+type Progress struct {
+	Transferred int64     `json:"transferredBytes"`
+	Total       int64     `json:"totalBytes"` // -1 if unknown
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Token grants access to the data of a transfer process until it expires or is revoked
+type Token struct {
+	Value     string    `json:"value"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Revoked   bool      `json:"revoked"`
+}
+
+// Valid returns true if the token can be used to access data at the given time,
+// where a token without an expiry is valid until it is revoked
+func (t Token) Valid(at time.Time) bool {
+	return !t.Revoked && (t.ExpiresAt.IsZero() || at.Before(t.ExpiresAt))
+}
@@ -7,7 +7,9 @@ import (
 )
 
 type CatalogHandler interface {
-	HandleCatalogRequest(filter any) (catalog.Response, error)
+	// HandleCatalogRequest returns a page of the datasets matching the filter along with
+	// the next page, which has an empty cursor if the returned page is the last
+	HandleCatalogRequest(filter []string, page catalog.Page) (res catalog.Response, next catalog.Page, err error)
 	HandleDatasetRequest(id string) (catalog.DatasetResponse, error)
 }
 
@@ -15,17 +17,26 @@ type NegotiationController interface {
 	// OfferContract sends an Offer to the consumer. providerPid and consumerAddr parameters are
 	// mutually exclusive. Former should be given when the Provider is responding to a Contract
 	// Request by a Consumer, whereas the latter when the Provider is the initiator of the flow.
-	OfferContract(offerId, providerPid, consumerAddr string) (cnId string, err error)
+	// consumerId is required along with consumerAddr since the negotiation is bound to the
+	// participant ID of the consumer it is offered to.
+	// constraints replace the right operands of the published offer by their left operands so
+	// that a counter-offer can be proposed.
+	OfferContract(offerId, providerPid, consumerAddr, consumerId string, constraints map[string]string) (cnId string, err error)
+	// AgreeContract agrees to the published offer if offerId is given, and to the offer proposed
+	// in the latest round of the negotiation otherwise. The stored agreement is sent again if the
+	// negotiation has already been agreed upon, so that a failed delivery can be retried.
 	AgreeContract(offerId, negotiationId string) (agreementId string, err error)
 	FinalizeContract(providerPid string) error
 }
 
+// NegotiationHandler processes the messages sent by the authenticated participant, where
+// messages of an existing negotiation are rejected unless sent by its consumer
 type NegotiationHandler interface {
 	HandleNegotiationsRequest(providerPid string) (negotiation.Ack, error)
-	HandleContractRequest(cr negotiation.ContractRequest) (negotiation.Ack, error)
-	HandleAcceptOffer(e negotiation.ContractNegotiationEvent) (negotiation.Ack, error)
-	HandleAgreementVerification(cv negotiation.ContractVerification) (negotiation.Ack, error)
-	HandleContractTermination(ct negotiation.ContractTermination) (negotiation.Ack, error)
+	HandleContractRequest(cr negotiation.ContractRequest, participantId string) (negotiation.Ack, error)
+	HandleAcceptOffer(e negotiation.ContractNegotiationEvent, participantId string) (negotiation.Ack, error)
+	HandleAgreementVerification(cv negotiation.ContractVerification, participantId string) (negotiation.Ack, error)
+	HandleContractTermination(ct negotiation.ContractTermination, participantId string) (negotiation.Ack, error)
 }
 
 type TransferController interface {
@@ -35,11 +46,14 @@ type TransferController interface {
 	TerminateTransfer(tpId, code string, reasons []interface{}) error
 }
 
+// TransferHandler processes the messages sent by the authenticated participant, where
+// messages of an existing transfer process are rejected unless sent by the assignee of
+// its agreement
 type TransferHandler interface {
 	HandleGetProcess(tpId string) (transfer.Ack, error)
-	HandleTransferRequest(tr transfer.Request) (transfer.Ack, error) // todo: should be idempotent for multiple transfer requests
-	HandleTransferSuspension(sr transfer.SuspendRequest) (transfer.Ack, error)
-	HandleTransferStart(sr transfer.StartRequest) (transfer.Ack, error)
-	HandleTransferCompletion(cr transfer.CompleteRequest) (transfer.Ack, error)
-	HandleTransferTermination(tr transfer.TerminateRequest) (transfer.Ack, error)
+	HandleTransferRequest(tr transfer.Request, participantId string) (transfer.Ack, error) // todo: should be idempotent for multiple transfer requests
+	HandleTransferSuspension(sr transfer.SuspendRequest, participantId string) (transfer.Ack, error)
+	HandleTransferStart(sr transfer.StartRequest, participantId string) (transfer.Ack, error)
+	HandleTransferCompletion(cr transfer.CompleteRequest, participantId string) (transfer.Ack, error)
+	HandleTransferTermination(tr transfer.TerminateRequest, participantId string) (transfer.Ack, error)
 }
@@ -2,7 +2,9 @@ package core
 
 import (
 	"github.com/YasiruR/connector/domain/core/consumer"
+	"github.com/YasiruR/connector/domain/core/dataplane"
 	"github.com/YasiruR/connector/domain/core/provider"
+	"github.com/YasiruR/connector/domain/models/dcat"
 	"github.com/YasiruR/connector/domain/models/odrl"
 )
 
@@ -26,9 +28,35 @@ type Consumer interface {
 	consumer.NegotiationHandler
 	consumer.TransferController
 	consumer.TransferHandler
+	consumer.Fetcher
 }
 
 type Owner interface {
-	CreatePolicy(target string, permissions, prohibitions []odrl.Rule) (id string, err error)
-	CreateDataset(title, format string, descriptions, keywords, endpoints, offerIds []string) (id string, err error)
+	CreatePolicy(target string, permissions, prohibitions []odrl.Rule, obligations []odrl.Duty) (id string, err error)
+	Policy(id string) (odrl.Offer, error)
+	Policies() ([]odrl.Offer, error)
+	// UpdatePolicy and DeletePolicy fail if the offer is referred by a contract negotiation
+	// in progress, and DeletePolicy fails if the offer is referred by an agreement as well
+	UpdatePolicy(id, target string, permissions, prohibitions []odrl.Rule, obligations []odrl.Duty) error
+	DeletePolicy(id string) error
+	// CreateCatalog creates a catalog nested within the parent catalog, which is the root
+	// catalog of the connector if the parent ID is empty
+	CreateCatalog(parentId, title string, descriptions, keywords, endpoints []string) (id string, err error)
+	UpdateCatalog(id, title string, descriptions, keywords, endpoints []string) error
+	DeleteCatalog(id string) error
+	CreateDataset(catalogId, title string, descriptions, keywords, offerIds []string,
+		distributions []Distribution) (id string, err error)
+	Dataset(id string) (dcat.Dataset, error)
+	Datasets() ([]dcat.Dataset, error)
+	UpdateDataset(id, title string, descriptions, keywords, offerIds []string) error
+	DeleteDataset(id string) error
+}
+
+// Distribution defines a form of a dataset created by the owner. Content of the
+// distribution is served from the source if provided, and from the endpoints otherwise.
+type Distribution struct {
+	Format    string
+	MediaType string
+	Endpoints []string
+	Source    dataplane.Source
 }
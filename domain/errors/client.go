@@ -113,3 +113,45 @@ func ProtocolFailed(typ string, errMsg any, err error) ErrorMessage {
 		err:     fmt.Errorf("%s protocol failed - %s", typ, err),
 	}
 }
+
+func PolicyViolated(err error) ErrorMessage {
+	return ErrorMessage{
+		code:    `20014`,
+		Message: "request does not comply with the policy",
+		Params:  map[string]interface{}{"reason": err.Error()},
+		err:     fmt.Errorf("policy validation failed - %s", err),
+	}
+}
+
+func Unauthorized(err error) ErrorMessage {
+	return ErrorMessage{
+		code:    `20015`,
+		Message: "request could not be authenticated",
+		err:     fmt.Errorf("authentication failed - %s", err),
+	}
+}
+
+func DataUnavailable(err error) ErrorMessage {
+	return ErrorMessage{
+		code:    `20016`,
+		Message: "data of the transfer process is not available",
+		err:     fmt.Errorf("data plane failed - %s", err),
+	}
+}
+
+func UnsupportedFormat(format, mediaType string) ErrorMessage {
+	return ErrorMessage{
+		code:    `20017`,
+		Message: "dataset does not have a distribution in the requested format",
+		Params:  map[string]interface{}{"format": format, "mediaType": mediaType},
+		err:     fmt.Errorf("distribution not found (format: %s, media type: %s)", format, mediaType),
+	}
+}
+
+func Conflict(operation string, err error) ErrorMessage {
+	return ErrorMessage{
+		code:    `20018`,
+		Message: "state was modified by a concurrent request, and the request can be retried",
+		err:     fmt.Errorf("concurrent modification (operation: %s) - %s", operation, err),
+	}
+}
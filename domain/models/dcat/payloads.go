@@ -1,6 +1,9 @@
 package dcat
 
-import "github.com/YasiruR/connector/domain/models/odrl"
+import (
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"strings"
+)
 
 // namespace prefix reference: https://www.w3.org/TR/vocab-dcat-2/#normative-namespaces
 
@@ -17,6 +20,49 @@ type Keyword string
 type Catalog struct {
 	CatalogMetadata
 	DcatDataset []Dataset `json:"dcat:dataset"`
+	DcatCatalog []Catalog `json:"dcat:catalog,omitempty"` // nested catalogs
+}
+
+// Datasets returns the datasets of the catalog including those of nested catalogs
+func (c Catalog) Datasets() []Dataset {
+	datasets := append([]Dataset{}, c.DcatDataset...)
+	for _, nested := range c.DcatCatalog {
+		datasets = append(datasets, nested.Datasets()...)
+	}
+	return datasets
+}
+
+// Merge returns the catalog with the datasets and nested catalogs of the other catalog
+// which are not already included, where nested catalogs with the same ID are merged
+func (c Catalog) Merge(other Catalog) Catalog {
+	merged := c
+	merged.DcatDataset = append([]Dataset{}, c.DcatDataset...)
+	included := make(map[string]bool)
+	for _, ds := range c.DcatDataset {
+		included[ds.ID] = true
+	}
+
+	for _, ds := range other.DcatDataset {
+		if !included[ds.ID] {
+			merged.DcatDataset = append(merged.DcatDataset, ds)
+		}
+	}
+
+	merged.DcatCatalog = append([]Catalog{}, c.DcatCatalog...)
+	for _, o := range other.DcatCatalog {
+		found := false
+		for i, nested := range merged.DcatCatalog {
+			if nested.ID == o.ID {
+				merged.DcatCatalog[i], found = nested.Merge(o), true
+				break
+			}
+		}
+
+		if !found {
+			merged.DcatCatalog = append(merged.DcatCatalog, o)
+		}
+	}
+	return merged
 }
 
 type CatalogMetadata struct {
@@ -38,14 +84,33 @@ type Dataset struct {
 	DcatDistribution []Distribution `json:"dcat:distribution"`
 }
 
+// Distribution returns the first distribution of the dataset which matches the format,
+// where any media type is accepted if it is not specified
+func (d Dataset) Distribution(format, mediaType string) (Distribution, bool) {
+	for _, dist := range d.DcatDistribution {
+		if !strings.EqualFold(dist.DctFormat, format) {
+			continue
+		}
+
+		if mediaType == `` || strings.EqualFold(dist.DcatMediaType, mediaType) {
+			return dist, true
+		}
+	}
+	return Distribution{}, false
+}
+
 type Description struct {
 	Value    string `json:"@value"`
 	Language string `json:"@language"`
 }
 
+// Distribution is a form of the dataset which is served with the transfer type defined by
+// its format, where distributions of the same format are differentiated by media type
 type Distribution struct {
+	ID                string          `json:"@id,omitempty"`
 	Type              string          `json:"@type"`
 	DctFormat         string          `json:"dct:format"`
+	DcatMediaType     string          `json:"dcat:mediaType,omitempty"`
 	DcatAccessService []AccessService `json:"dcat:accessService"`
 }
 
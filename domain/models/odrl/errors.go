@@ -0,0 +1,19 @@
+package odrl
+
+import "fmt"
+
+func invalidAttribute(object, key string, err error) error {
+	return fmt.Errorf("invalid attribute of the %s (%s) - %w", object, key, err)
+}
+
+func invalidObject() error {
+	return fmt.Errorf("object is neither a value, a node nor a list")
+}
+
+func unsupportedValue(val string) error {
+	return fmt.Errorf("value is not supported as a right operand (%s)", val)
+}
+
+func multipleIRIs() error {
+	return fmt.Errorf("expected a single IRI but received a list")
+}
@@ -0,0 +1,368 @@
+package odrl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+/*
+	Policies, rules, duties and constraints are marshalled in the compact JSON-LD form used
+	by the connector, whereas both compact and expanded forms are accepted when
+	unmarshalling since other connectors may send either of them (e.g. 'odrl:rightOperand':
+	'5' or 'http://www.w3.org/ns/odrl/2/rightOperand': [{'@value': '5', '@type': '...#integer'}]).
+*/
+
+const (
+	namespaceODRL = `http://www.w3.org/ns/odrl/2/`
+	namespaceXSD  = `http://www.w3.org/2001/XMLSchema#`
+	// termTimestamp is the expanded form of the DSP timestamp of an agreement
+	termTimestamp = `https://w3id.org/dspace/2024/1/timestamp`
+)
+
+// Compact replaces the ODRL and XSD namespaces of an IRI with their prefixes
+func Compact(iri string) string {
+	switch {
+	case strings.HasPrefix(iri, namespaceODRL):
+		return `odrl:` + strings.TrimPrefix(iri, namespaceODRL)
+	case strings.HasPrefix(iri, namespaceXSD):
+		return `xsd:` + strings.TrimPrefix(iri, namespaceXSD)
+	default:
+		return iri
+	}
+}
+
+func (c Constraint) MarshalJSON() ([]byte, error) {
+	type constraint Constraint
+	var right *RightOperand
+	if !c.RightOperand.IsZero() {
+		right = &c.RightOperand
+	}
+
+	return json.Marshal(struct {
+		constraint
+		RightOperand *RightOperand `json:"odrl:rightOperand,omitempty"`
+	}{constraint: constraint(c), RightOperand: right})
+}
+
+func (o *Offer) UnmarshalJSON(data []byte) error {
+	*o = Offer{}
+	return unmarshalAttrs(`offer`, data, func(term string, val json.RawMessage) (err error) {
+		switch term {
+		case `@id`:
+			err = json.Unmarshal(val, &o.Id)
+		case `@type`:
+			o.Type, err = unmarshalType(val)
+		case `target`:
+			var target string
+			target, err = unmarshalIRI(val)
+			o.Target = Target(target)
+		case `assigner`:
+			var assigner string
+			assigner, err = unmarshalIRI(val)
+			o.Assigner = Assigner(assigner)
+		case `assignee`:
+			var assignee string
+			assignee, err = unmarshalIRI(val)
+			o.Assignee = Assignee(assignee)
+		case `permission`:
+			o.Permissions, err = unmarshalList[Rule](val)
+		case `prohibition`:
+			o.Prohibitions, err = unmarshalList[Rule](val)
+		case `obligation`:
+			o.Obligations, err = unmarshalList[Duty](val)
+		}
+		return err
+	})
+}
+
+func (a *Agreement) UnmarshalJSON(data []byte) error {
+	*a = Agreement{}
+	return unmarshalAttrs(`agreement`, data, func(term string, val json.RawMessage) (err error) {
+		switch term {
+		case `@id`:
+			err = json.Unmarshal(val, &a.Id)
+		case `@type`:
+			a.Type, err = unmarshalType(val)
+		case `target`:
+			var target string
+			target, err = unmarshalIRI(val)
+			a.Target = Target(target)
+		case `assigner`:
+			var assigner string
+			assigner, err = unmarshalIRI(val)
+			a.Assigner = Assigner(assigner)
+		case `assignee`:
+			var assignee string
+			assignee, err = unmarshalIRI(val)
+			a.Assignee = Assignee(assignee)
+		case `dspace:timestamp`, termTimestamp:
+			var timestamp RightOperand
+			err = json.Unmarshal(val, &timestamp)
+			a.Timestamp = timestamp.Value
+		case `permission`:
+			a.Permissions, err = unmarshalList[Rule](val)
+		case `prohibition`:
+			a.Prohibitions, err = unmarshalList[Rule](val)
+		case `obligation`:
+			a.Obligations, err = unmarshalList[Duty](val)
+		}
+		return err
+	})
+}
+
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	*r = Rule{}
+	return unmarshalAttrs(`rule`, data, func(term string, val json.RawMessage) (err error) {
+		switch term {
+		case `action`:
+			var action string
+			action, err = unmarshalIRI(val)
+			r.Action = Action(action)
+		case `constraint`:
+			r.Constraints, err = unmarshalList[Constraint](val)
+		case `duty`:
+			r.Duties, err = unmarshalList[Duty](val)
+		case `remedy`:
+			r.Remedies, err = unmarshalList[Duty](val)
+		}
+		return err
+	})
+}
+
+func (d *Duty) UnmarshalJSON(data []byte) error {
+	*d = Duty{}
+	return unmarshalAttrs(`duty`, data, func(term string, val json.RawMessage) (err error) {
+		switch term {
+		case `action`:
+			var action string
+			action, err = unmarshalIRI(val)
+			d.Action = Action(action)
+		case `constraint`:
+			d.Constraints, err = unmarshalList[Constraint](val)
+		case `consequence`:
+			d.Consequences, err = unmarshalList[Duty](val)
+		}
+		return err
+	})
+}
+
+func (c *Constraint) UnmarshalJSON(data []byte) error {
+	*c = Constraint{}
+	return unmarshalAttrs(`constraint`, data, func(term string, val json.RawMessage) (err error) {
+		switch term {
+		case `leftOperand`:
+			c.LeftOperand, err = unmarshalIRI(val)
+		case `operator`:
+			c.Operator, err = unmarshalIRI(val)
+		case `rightOperand`:
+			err = json.Unmarshal(val, &c.RightOperand)
+		case `rightOperandReference`:
+			c.RightOperandReference, err = unmarshalIRI(val)
+		case `unit`:
+			c.Unit, err = unmarshalIRI(val)
+		case `and`:
+			c.And, err = unmarshalConstraints(val)
+		case `or`:
+			c.Or, err = unmarshalConstraints(val)
+		case `xone`:
+			c.Xone, err = unmarshalConstraints(val)
+		}
+		return err
+	})
+}
+
+// unmarshalAttrs calls fn with each attribute of the object, where compact and expanded
+// ODRL terms are passed without the prefix or the namespace (e.g. 'action')
+func unmarshalAttrs(object string, data []byte, fn func(term string, val json.RawMessage) error) error {
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(data, &attrs); err != nil {
+		return err
+	}
+
+	for key, val := range attrs {
+		if err := fn(strings.TrimPrefix(Compact(key), `odrl:`), val); err != nil {
+			return invalidAttribute(object, key, err)
+		}
+	}
+	return nil
+}
+
+// unmarshalList accepts either an array or a single object, as a compact JSON-LD
+// document may contain a single value without an array
+func unmarshalList[T any](data []byte) ([]T, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte(`null`)) {
+		return nil, nil
+	}
+
+	if data[0] == '[' {
+		var list []T
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+
+	var val T
+	if err := json.Unmarshal(data, &val); err != nil {
+		return nil, err
+	}
+	return []T{val}, nil
+}
+
+// unmarshalType accepts a type as a string or a list of a single type as in the
+// expanded form
+func unmarshalType(data []byte) (string, error) {
+	types, err := unmarshalList[string](data)
+	if err != nil {
+		return ``, err
+	}
+
+	switch len(types) {
+	case 0:
+		return ``, nil
+	case 1:
+		return Compact(types[0]), nil
+	default:
+		return ``, multipleIRIs()
+	}
+}
+
+func (r RightOperand) MarshalJSON() ([]byte, error) {
+	switch {
+	case len(r.List) > 0:
+		return json.Marshal(r.List)
+	case r.ID != ``:
+		return json.Marshal(map[string]string{`@id`: r.ID})
+	case r.Type != ``:
+		return json.Marshal(map[string]string{`@value`: r.Value, `@type`: r.Type})
+	default:
+		return json.Marshal(r.Value)
+	}
+}
+
+// UnmarshalJSON accepts plain strings, native JSON values, value and node objects and
+// lists of them, where a list of a single value (as in the expanded form) is considered
+// to be the value itself
+func (r *RightOperand) UnmarshalJSON(data []byte) error {
+	*r = RightOperand{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte(`null`)) {
+		return nil
+	}
+
+	switch data[0] {
+	case '"':
+		return json.Unmarshal(data, &r.Value)
+	case '[':
+		var list []RightOperand
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+
+		if len(list) == 1 {
+			*r = list[0]
+			return nil
+		}
+		r.List = list
+		return nil
+	case '{':
+		var obj struct {
+			Value json.RawMessage `json:"@value"`
+			Type  string          `json:"@type"`
+			ID    string          `json:"@id"`
+			List  []RightOperand  `json:"@list"`
+		}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+
+		switch {
+		case obj.ID != ``:
+			r.ID = Compact(obj.ID)
+		case obj.List != nil:
+			r.List = obj.List
+		case obj.Value != nil:
+			if err := r.UnmarshalJSON(obj.Value); err != nil {
+				return err
+			}
+			if obj.Type != `` {
+				r.Type = Compact(obj.Type)
+			}
+		default:
+			return invalidObject()
+		}
+		return nil
+	default:
+		return r.unmarshalNative(data)
+	}
+}
+
+// unmarshalNative sets the datatype of native JSON values as defined by JSON-LD
+func (r *RightOperand) unmarshalNative(data []byte) error {
+	var val any
+	if err := json.Unmarshal(data, &val); err != nil {
+		return err
+	}
+
+	r.Value = string(data)
+	switch v := val.(type) {
+	case bool:
+		r.Type = DataTypeBoolean
+	case float64:
+		if v == float64(int64(v)) && !bytes.ContainsAny(data, `.eE`) {
+			r.Type = DataTypeInteger
+		} else {
+			r.Type = DataTypeDouble
+		}
+	default:
+		return unsupportedValue(string(data))
+	}
+	return nil
+}
+
+// unmarshalIRI accepts an IRI as a plain string, a node or a value object, or a list
+// of a single IRI as in the expanded form
+func unmarshalIRI(data []byte) (string, error) {
+	var val RightOperand
+	if err := json.Unmarshal(data, &val); err != nil {
+		return ``, err
+	}
+
+	if len(val.List) > 0 {
+		return ``, multipleIRIs()
+	}
+
+	if val.ID != `` {
+		return val.ID, nil
+	}
+	return Compact(val.Value), nil
+}
+
+// unmarshalConstraints accepts the operands of a logical constraint either as an array
+// or as a list object
+func unmarshalConstraints(data []byte) ([]Constraint, error) {
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		list = []json.RawMessage{data}
+	}
+
+	var constraints []Constraint
+	for _, item := range list {
+		var obj struct {
+			List []Constraint `json:"@list"`
+		}
+		if err := json.Unmarshal(item, &obj); err == nil && obj.List != nil {
+			constraints = append(constraints, obj.List...)
+			continue
+		}
+
+		var c Constraint
+		if err := json.Unmarshal(item, &c); err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, c)
+	}
+	return constraints, nil
+}
@@ -1,15 +1,57 @@
 package odrl
 
+import "strings"
+
 const (
 	TypeOffer     = `odrl:Offer`
 	TypeAgreement = `odrl:Agreement`
 )
 
+// datatypes of typed literals
+const (
+	DataTypeString   = `xsd:string`
+	DataTypeBoolean  = `xsd:boolean`
+	DataTypeInteger  = `xsd:integer`
+	DataTypeDecimal  = `xsd:decimal`
+	DataTypeDouble   = `xsd:double`
+	DataTypeDate     = `xsd:date`
+	DataTypeDateTime = `xsd:dateTime`
+)
+
 // action types
 const (
 	ActionUse = `odrl:use`
 )
 
+// left operands which are resolved by the connector at runtime
+const (
+	LeftOperandDateTime   = `odrl:dateTime`
+	LeftOperandCount      = `odrl:count`
+	LeftOperandRecipient  = `odrl:recipient`
+	LeftOperandFileFormat = `odrl:fileFormat`
+)
+
+// constraint operators
+const (
+	OpEq       = `odrl:eq`
+	OpNeq      = `odrl:neq`
+	OpLt       = `odrl:lt`
+	OpLteq     = `odrl:lteq`
+	OpGt       = `odrl:gt`
+	OpGteq     = `odrl:gteq`
+	OpIsAnyOf  = `odrl:isAnyOf`
+	OpIsPartOf = `odrl:isPartOf`
+)
+
+// Prefixed returns the term with the ODRL prefix if it is not already prefixed, since
+// terms such as operators and left operands can be provided in either form
+func Prefixed(term string) string {
+	if strings.Contains(term, `:`) {
+		return term
+	}
+	return `odrl:` + term
+}
+
 type Action string
 type Assigner string
 type Assignee string
@@ -26,31 +68,114 @@ type Offer struct {
 	Assignee     Assignee `json:"odrl:assignee"`
 	Permissions  []Rule   `json:"odrl:permission"`
 	Prohibitions []Rule   `json:"odrl:prohibition"`
-	// duties/obligations should be included
+	Obligations  []Duty   `json:"odrl:obligation,omitempty"`
 }
 
 // Agreement is a subclass of Policy that supports granting of Rules from assigner to assignee Parties
 type Agreement struct {
-	Id          string   `json:"@id"`
-	Type        string   `json:"@type" default:"odrl:Agreement"`
-	Target      Target   `json:"odrl:target"`
-	Assigner    Assigner `json:"odrl:assigner"`
-	Assignee    Assignee `json:"odrl:assignee"`
-	Timestamp   string   `json:"dspace:timestamp"` // due to this attribute may need to transfer agreement structure to dsp api
-	Permissions []Rule   `json:"odrl:permission"`
+	Id           string   `json:"@id"`
+	Type         string   `json:"@type" default:"odrl:Agreement"`
+	Target       Target   `json:"odrl:target"`
+	Assigner     Assigner `json:"odrl:assigner"`
+	Assignee     Assignee `json:"odrl:assignee"`
+	Timestamp    string   `json:"dspace:timestamp"` // due to this attribute may need to transfer agreement structure to dsp api
+	Permissions  []Rule   `json:"odrl:permission"`
+	Prohibitions []Rule   `json:"odrl:prohibition,omitempty"`
+	Obligations  []Duty   `json:"odrl:obligation,omitempty"`
 }
 
+// Rule is either a permission or a prohibition. Duties of a permission must be fulfilled
+// to exercise the permission, whereas remedies of a prohibition must be fulfilled if the
+// prohibition is violated.
 type Rule struct {
 	Action      Action       `json:"odrl:action"`
 	Constraints []Constraint `json:"odrl:constraint"`
+	Duties      []Duty       `json:"odrl:duty,omitempty"`
+	Remedies    []Duty       `json:"odrl:remedy,omitempty"`
 }
 
+// Duty is an obligation to exercise an action, which is either a duty of a permission,
+// a remedy of a prohibition or an obligation of the policy itself. Consequences are the
+// duties to be fulfilled if the duty is not fulfilled.
+type Duty struct {
+	Action       Action       `json:"odrl:action"`
+	Constraints  []Constraint `json:"odrl:constraint,omitempty"`
+	Consequences []Duty       `json:"odrl:consequence,omitempty"`
+}
+
+// Constraint is either an atomic constraint which compares the left operand with the
+// right operand, or a logical constraint which combines other constraints by one of
+// and, or and xone operands. The right operand may instead be referred by an IRI
+// which is resolved when the constraint is evaluated, and the unit applies to both
+// operands (e.g. the currency of a payment).
 type Constraint struct {
-	LeftOperand  string `json:"odrl:leftOperand"`
-	Operator     string `json:"odrl:operator"`
-	RightOperand string `json:"odrl:rightOperand"`
-	//RightOperand struct {					// support both strings and structs
-	//	Value string `json:"@value"`
-	//	Type  string `json:"@type"`
-	//} `json:"odrl:rightOperand"`
+	LeftOperand           string       `json:"odrl:leftOperand,omitempty"`
+	Operator              string       `json:"odrl:operator,omitempty"`
+	RightOperand          RightOperand `json:"odrl:rightOperand"`
+	RightOperandReference string       `json:"odrl:rightOperandReference,omitempty"`
+	Unit                  string       `json:"odrl:unit,omitempty"`
+	And                   []Constraint `json:"odrl:and,omitempty"`
+	Or                    []Constraint `json:"odrl:or,omitempty"`
+	Xone                  []Constraint `json:"odrl:xone,omitempty"`
+}
+
+// RightOperand is either a literal with an optional datatype, an IRI or a list of
+// right operands. An untyped literal is marshalled as a plain string, and values of a
+// literal which is not a string may also be separated by commas.
+type RightOperand struct {
+	Value string
+	Type  string // datatype of the literal (e.g. xsd:dateTime)
+	ID    string // IRI if the right operand is a reference to a resource
+	List  []RightOperand
+}
+
+func Literal(val string) RightOperand {
+	return RightOperand{Value: val}
+}
+
+func TypedLiteral(val, typ string) RightOperand {
+	return RightOperand{Value: val, Type: typ}
+}
+
+func IRI(id string) RightOperand {
+	return RightOperand{ID: id}
+}
+
+// Values returns the members of a list, or the values separated by commas in a literal
+func (r RightOperand) Values() []RightOperand {
+	switch {
+	case len(r.List) > 0:
+		return r.List
+	case r.ID != ``, r.Type == DataTypeString:
+		return []RightOperand{r}
+	}
+
+	var vals []RightOperand
+	for _, v := range strings.Split(r.Value, `,`) {
+		if v = strings.TrimSpace(v); v != `` {
+			vals = append(vals, RightOperand{Value: v, Type: r.Type})
+		}
+	}
+	return vals
+}
+
+// String returns the lexical form of the right operand, where members of a list are
+// separated by commas
+func (r RightOperand) String() string {
+	switch {
+	case len(r.List) > 0:
+		var vals []string
+		for _, v := range r.List {
+			vals = append(vals, v.String())
+		}
+		return strings.Join(vals, `,`)
+	case r.ID != ``:
+		return r.ID
+	default:
+		return r.Value
+	}
+}
+
+func (r RightOperand) IsZero() bool {
+	return r.Value == `` && r.Type == `` && r.ID == `` && len(r.List) == 0
 }
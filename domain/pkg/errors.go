@@ -0,0 +1,25 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"github.com/YasiruR/connector/domain/models/odrl"
+)
+
+var TypePolicyViolation = errors.New("policy violated")
+
+// PolicyViolation is returned by a PolicyEngine when a constraint is not satisfied
+type PolicyViolation struct {
+	Constraint odrl.Constraint
+	Reason     string
+}
+
+func (p PolicyViolation) Error() string {
+	return fmt.Sprintf("%s (left operand: %s, operator: %s, right operand: %s) - %s",
+		TypePolicyViolation, p.Constraint.LeftOperand, p.Constraint.Operator, p.Constraint.RightOperand,
+		p.Reason)
+}
+
+func (p PolicyViolation) Unwrap() error {
+	return TypePolicyViolation
+}
@@ -1,26 +1,67 @@
 package pkg
 
-import "context"
+import (
+	"context"
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"io"
+)
 
 const (
-	TypeDatabase = `Database`
-	TypeURN      = `URNService`
-	TypeClient   = `Client`
+	TypeDatabase   = `Database`
+	TypeURN        = `URNService`
+	TypeClient     = `Client`
+	TypePolicy     = `PolicyEngine`
+	TypeIAM        = `IAM`
+	TypeStorage    = `Storage`
+	TypeDataSource = `DataSource`
 )
 
+// IAM authenticates the participants of a data space. Tokens may be issued by the
+// connector itself or obtained from an identity provider (e.g. DAPS or DCP).
 type IAM interface {
-	Register()
-	Verify()
+	// Token returns a token which identifies the connector to the participant at the
+	// given address, which is the audience of the token
+	Token(audience string) (string, error)
+	// Verify validates a token received from a participant and returns the participant
+	// ID of its subject, which is used as the assigner or assignee in policies
+	Verify(token string) (participantId string, err error)
 }
 
+// PolicyEngine evaluates ODRL policies against the context of a request
 type PolicyEngine interface {
-	ValidateOffer()
+	// Evaluate returns nil if the constraints of the rule are satisfied by the
+	// context and a PolicyViolation otherwise
+	Evaluate(rule odrl.Rule, ctx PolicyContext) error
+	// ValidateOffer returns an error if the received offer weakens or alters the
+	// rules of the published offer. Received offers may only narrow the published
+	// constraints (e.g. a smaller upper bound) or add new constraints, whereas
+	// prohibitions, duties and obligations of the published offer must be retained. Target
+	// of the published offer must be set to the dataset which publishes it.
+	ValidateOffer(received, published odrl.Offer) error
 }
 
+// PolicyContext maps left operands to the values of the request being evaluated,
+// where a list of values is separated by commas
+type PolicyContext map[string]string
+
 // Database contains one or more Collection to support data storage required
-// by the connector
+// by the connector. A Collection is opened by a unique name and a sample value
+// (e.g. zero value) of the type stored in it, which can be used as a schema
+// hint by persistent databases. Opening an existing name returns the same
+// Collection.
 type Database interface {
-	NewCollection() Collection
+	NewCollection(name string, typ any) Collection
+	// Transaction executes fn as a single unit of work where the writes made through
+	// the collections of the Transaction are committed only if fn returns nil. Other
+	// writes are blocked until the transaction ends and therefore, collections must
+	// not be accessed directly within fn.
+	Transaction(fn func(tx Transaction) error) error
+}
+
+// Transaction provides access to the collections bound to a unit of work
+type Transaction interface {
+	// Collection returns a view of the named collection within the transaction
+	Collection(name string) Collection
 }
 
 // Collection provides an isolated storage for a single context. For example,
@@ -29,10 +70,71 @@ type Collection interface {
 	Get(key string) (interface{}, error)
 	GetAll() ([]any, error)
 	Set(key string, value interface{}) error
+	// Delete removes the value of the key and does not return an error if the
+	// key does not exist
+	Delete(key string) error
+	// CompareAndSwap sets the value of the key only if its current value is equal
+	// to old, where a nil old value requires that the key does not exist
+	CompareAndSwap(key string, old, new any) (swapped bool, err error)
+	// Scan returns values of the keys with the given prefix in ascending order of
+	// keys, starting after the cursor (exclusive). Number of values is limited by
+	// limit (0 for no limit) and next is the cursor for the following page, which
+	// is empty if there are no more values.
+	Scan(prefix, cursor string, limit int) (vals []any, next string, err error)
+	// Query returns all values which satisfy the given predicate
+	Query(filter Filter) ([]any, error)
+}
+
+// Filter is a predicate applied on the key and value of each entry of a Collection
+type Filter func(key string, val any) bool
+
+// Storage persists the data received by the data plane, where each content is
+// identified by a unique key (e.g. transfer process ID)
+type Storage interface {
+	// Write stores the content under the key by replacing any existing content and
+	// returns the number of bytes written. Partially written content is discarded
+	// if reading the content fails.
+	Write(key string, content io.Reader) (n int64, err error)
+	// WriteAt writes the content to the partial content of the key at the offset by
+	// discarding any partial content after the offset, so that content received in
+	// chunks can be resumed or rewritten from an earlier offset. Partial content is
+	// not available to Open until it is committed.
+	WriteAt(key string, offset int64, content io.Reader) (n int64, err error)
+	// Commit replaces the content of the key with its partial content
+	Commit(key string) error
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes both the content and the partial content of the key and does
+	// not return an error if the key does not exist
+	Delete(key string) error
+}
+
+// DataSource provides the content of datasets from a backend (e.g. file system, HTTP
+// server or object store), where each content is identified by its path within the
+// backend
+type DataSource interface {
+	// Open streams the content of the path starting from the offset along with its
+	// metadata, where the caller is responsible for closing the content
+	Open(ctx context.Context, path string, offset int64) (io.ReadCloser, ObjectInfo, error)
+	// Stat returns the metadata of the content without reading it
+	Stat(ctx context.Context, path string) (ObjectInfo, error)
+}
+
+// DataSources resolves the data sources configured for the connector by their IDs
+type DataSources interface {
+	DataSource(id string) (DataSource, error)
+}
+
+// ObjectInfo describes the content of a path in a DataSource
+type ObjectInfo struct {
+	Size        int64 // total size regardless of the offset, -1 if unknown
+	ContentType string
 }
 
+// Client sends messages to other participants. SendPage additionally returns the
+// destination of the next page of a paginated response, which is nil for the last page.
 type Client interface {
 	Send(data []byte, destination any) (response []byte, err error)
+	SendPage(data []byte, destination any) (response []byte, next any, err error)
 }
 
 type URNService interface {
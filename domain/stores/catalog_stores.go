@@ -2,8 +2,10 @@ package stores
 
 import (
 	"github.com/YasiruR/connector/domain/api/dsp/http/catalog"
+	"github.com/YasiruR/connector/domain/core/dataplane"
 	"github.com/YasiruR/connector/domain/models/dcat"
 	"github.com/YasiruR/connector/domain/models/odrl"
+	"github.com/YasiruR/connector/domain/pkg"
 )
 
 /*
@@ -12,18 +14,47 @@ import (
 */
 
 // ProviderCatalog stores Datasets as per the DCAT profile recommended by IDSA.
-// Current implementation supports only a single catalog per a provider.
+// Catalogs of a provider are nested within the root catalog of the connector, which
+// is referred by an empty catalog ID. Data sources of distributions are stored
+// separately since they are not shared with consumers.
 type ProviderCatalog interface {
-	Catalog() (dcat.Catalog, error)
-	AddDataset(id string, val dcat.Dataset)
+	// Catalog returns the catalog along with its datasets and nested catalogs
+	Catalog(id string) (dcat.Catalog, error)
+	RootID() string
+	// AddCatalog returns an error if a catalog with the same ID exists
+	AddCatalog(parentId string, meta dcat.CatalogMetadata) error
+	UpdateCatalog(meta dcat.CatalogMetadata) error
+	// DeleteCatalog removes the catalog only if it does not have any dataset or
+	// nested catalog
+	DeleteCatalog(id string) error
+	// AddDataset stores the dataset along with the data sources of its distributions
+	// by the distribution IDs
+	AddDataset(catalogId, id string, val dcat.Dataset, sources map[string]dataplane.Source) error
 	Dataset(id string) (dcat.Dataset, error)
+	// Datasets returns the datasets of all catalogs
+	Datasets() ([]dcat.Dataset, error)
+	UpdateDataset(id string, val dcat.Dataset) error
+	// DatasetsByOffer returns the IDs of the datasets which publish the offer
+	DatasetsByOffer(offerId string) ([]string, error)
+	// DeleteDataset removes the dataset along with the data sources of its distributions
+	// unless guard (if any) returns an error for the stored dataset, which is called
+	// within the same transaction
+	DeleteDataset(id string, guard func(tx pkg.Transaction, ds dcat.Dataset) error) error
+	SetSource(distributionId string, val dataplane.Source) error
+	Source(distributionId string) (dataplane.Source, error)
 }
 
 // ConsumerCatalog stores catalogs received by providers and therefore, it may
 // include multiple catalogs as opposed to ProviderCatalog.
 type ConsumerCatalog interface {
-	AddCatalog(res catalog.Response)
+	// AddCatalog stores the catalog received from the endpoint of the provider
+	AddCatalog(endpoint string, res catalog.Response) error
 	Catalog(providerId string) (catalog.Response, error)
-	Offer(offerId string) (ofr odrl.Offer, err error)
+	// Offer returns the offer in the stored catalog of the provider, where the target of the
+	// offer is set to the dataset publishing it
+	Offer(providerId, offerId string) (ofr odrl.Offer, err error)
+	// Provider returns the participant ID of the provider of which the catalog was received
+	// from the endpoint
+	Provider(endpoint string) (participantId string, err error)
 	AllCatalogs() ([]catalog.Response, error)
 }
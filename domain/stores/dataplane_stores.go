@@ -0,0 +1,12 @@
+package stores
+
+import "github.com/YasiruR/connector/domain/core/dataplane"
+
+// DataPlaneStore maintains the access tokens issued by the data plane and the progress
+// of the data exchanged for the transfer processes
+type DataPlaneStore interface {
+	SetToken(tpId string, val dataplane.Token) error
+	Token(tpId string) (dataplane.Token, error)
+	SetProgress(tpId string, val dataplane.Progress) error
+	Progress(tpId string) (dataplane.Progress, error)
+}
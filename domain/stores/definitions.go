@@ -7,4 +7,6 @@ const (
 	TypeOffer               = `offer`
 	TypeAgreement           = `agreement`
 	TypeTransfer            = `transfer`
+	TypeDataPlane           = `data-plane`
+	TypeFetch               = `fetch`
 )
@@ -14,3 +14,44 @@ func InvalidKey(val string) error {
 func QueryFailed(collection, query string, err error) error {
 	return fmt.Errorf("query failed (collection: %s, query: %s) - %s", collection, query, err)
 }
+
+var TypeKeyExists = errors.New("key already exists")
+
+func KeyExists(key string) error {
+	return fmt.Errorf("%w (%s)", TypeKeyExists, key)
+}
+
+var TypeInUse = errors.New("stored value is referred by other values")
+
+func InUse(key, reason string) error {
+	return fmt.Errorf("%w (key: %s, reason: %s)", TypeInUse, key, reason)
+}
+
+var TypeConflict = errors.New("stored value was modified concurrently")
+
+func Conflict(key string) error {
+	return fmt.Errorf("%w (%s)", TypeConflict, key)
+}
+
+var TypeInvalidTransition = errors.New("state transition is not allowed")
+
+// TransitionError is returned when a store rejects a state transition which is
+// not allowed by the protocol state machine
+type TransitionError struct {
+	Key       string
+	Current   string
+	Requested string
+}
+
+func (t TransitionError) Error() string {
+	return fmt.Sprintf("%s (key: %s, current: %s, requested: %s)", TypeInvalidTransition, t.Key,
+		t.Current, t.Requested)
+}
+
+func (t TransitionError) Unwrap() error {
+	return TypeInvalidTransition
+}
+
+func InvalidTransition(key, current, requested string) error {
+	return TransitionError{Key: key, Current: current, Requested: requested}
+}
@@ -1,6 +1,9 @@
 package stores
 
-import "github.com/YasiruR/connector/domain/models/odrl"
+import (
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"github.com/YasiruR/connector/domain/pkg"
+)
 
 /*
 	Data associated with ODRL policies are stored here.
@@ -11,14 +14,26 @@ import "github.com/YasiruR/connector/domain/models/odrl"
 type OfferStore interface {
 	AddOffer(id string, val odrl.Offer)
 	Offer(id string) (odrl.Offer, error)
+	Offers() ([]odrl.Offer, error)
+	// UpdateOffer and DeleteOffer return an InvalidKey error if the offer does not exist.
+	// The write is aborted if guard (if any) returns an error, which is called within
+	// the same transaction so that stored values can be validated atomically.
+	UpdateOffer(id string, val odrl.Offer, guard func(tx pkg.Transaction) error) error
+	DeleteOffer(id string, guard func(tx pkg.Transaction) error) error
 }
 
 // AgreementStore stores agreements resulted by successfully concluded contract
 // negotiation processes between a provider and a consumer.
 type AgreementStore interface {
-	// AddAgreement stores contract agreement with agreement ID as the key
-	AddAgreement(id string, val odrl.Agreement)
+	// AddAgreement stores contract agreement with agreement ID as the key and links
+	// it to the contract negotiation which concluded it
+	AddAgreement(cnId string, val odrl.Agreement) error
 	// Agreement retrieves contract agreement by agreement ID
 	Agreement(id string) (odrl.Agreement, error)
-	//AgreementByNegotiationID(cnId string) (odrl.Agreement, error)
+	AgreementByNegotiationID(cnId string) (odrl.Agreement, error)
+	// NegotiationID returns the ID of the contract negotiation which concluded the agreement
+	NegotiationID(agrId string) (string, error)
+	// WithTx returns the store bound to the transaction, so that its writes are committed
+	// along with the other writes of the transaction
+	WithTx(tx pkg.Transaction) AgreementStore
 }
@@ -3,30 +3,89 @@ package stores
 import (
 	"github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
 	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"github.com/YasiruR/connector/domain/core/consumer"
 	"github.com/YasiruR/connector/domain/models/odrl"
+	"github.com/YasiruR/connector/domain/pkg"
 )
 
 // ContractNegotiationStore includes get and set methods for attributes required
 // in Negotiation Protocol, such as process information, states and participants
 // as defined by IDSA standards ('cnId' refers to Contract Negotiation ID).
 type ContractNegotiationStore interface {
-	AddNegotiation(cnId string, val negotiation.Negotiation)
+	// AddNegotiation and SetNegotiation return a TransitionError if the state of the
+	// negotiation is not allowed to follow the stored state
+	AddNegotiation(cnId string, val negotiation.Negotiation) error
+	// SetNegotiation stores the negotiation along with its participants atomically
+	SetNegotiation(cnId string, val negotiation.Negotiation, callbackAddr string, assigner odrl.Assigner,
+		assignee odrl.Assignee) error
 	Negotiation(cnId string) (negotiation.Negotiation, error)
+	// UpdateState updates the state only if the negotiation was not modified concurrently
+	// and the transition is allowed by the negotiation state machine
 	UpdateState(cnId string, s negotiation.State) error
 	State(cnId string) (negotiation.State, error)
-	SetParticipants(cnId, callbackAddr string, assigner odrl.Assigner, assignee odrl.Assignee)
+	SetParticipants(cnId, callbackAddr string, assigner odrl.Assigner, assignee odrl.Assignee) error
 	Assignee(cnId string) (odrl.Assignee, error)
 	Assigner(cnId string) (odrl.Assigner, error)
 	CallbackAddr(cnId string) (string, error)
+	// SetCounterparty binds the negotiation to the participant ID of the other participant,
+	// so that the messages of the negotiation are only accepted if authenticated as it
+	SetCounterparty(cnId, participantId string) error
+	Counterparty(cnId string) (participantId string, err error)
+	// SetOffer links the negotiation to the offer of the provider which is being
+	// negotiated, so that the offer is not modified while it is in use
+	SetOffer(cnId, offerId string) error
+	NegotiationsByOffer(offerId string) ([]string, error)
+	// StatesByOffer returns the states of the negotiations linked to the offer by their
+	// IDs as read within the transaction, so that they can be validated atomically with
+	// a write of another store
+	StatesByOffer(tx pkg.Transaction, offerId string) (map[string]negotiation.State, error)
+	// AddRound records an offer proposed by either participant during the negotiation
+	AddRound(cnId string, r negotiation.Round) error
+	// Rounds returns the offers proposed during the negotiation in the order they were
+	// proposed
+	Rounds(cnId string) ([]negotiation.Round, error)
+	// WithTx returns the store bound to the transaction, so that its writes are committed
+	// along with the other writes of the transaction
+	WithTx(tx pkg.Transaction) ContractNegotiationStore
 }
 
 // TransferStore includes get and set methods for attributes required
 // in Transfer Protocol, such as process information, states and participants
 // as defined by IDSA standards ('cnId' refers to Contract Negotiation ID).
 type TransferStore interface {
-	AddProcess(tpId string, val transfer.Process)
+	// AddProcess and SetProcess return a TransitionError if the state of the process
+	// is not allowed to follow the stored state
+	AddProcess(tpId string, val transfer.Process, c transfer.Cause) error
+	// SetProcess stores the transfer process along with the received transfer request
+	// and its callback address atomically
+	SetProcess(tpId string, val transfer.Process, req transfer.Request, c transfer.Cause) error
 	Process(id string) (transfer.Process, error)
+	Request(tpId string) (transfer.Request, error)
+	// CountByAgreement returns the number of transfer requests received for the agreement
+	CountByAgreement(agreementId string) (int, error)
 	SetCallbackAddr(tpId, addr string)
 	CallbackAddr(tpId string) (string, error)
-	UpdateState(tpId string, s transfer.State) error
+	// SetCounterparty binds the process to the participant ID of the other participant,
+	// so that the messages of the process are only accepted if authenticated as it
+	SetCounterparty(tpId, participantId string) error
+	Counterparty(tpId string) (participantId string, err error)
+	// UpdateState updates the state only if the process was not modified concurrently
+	// and the transition is allowed by the transfer state machine. Each transition is
+	// recorded in the history of the process along with its cause.
+	UpdateState(tpId string, s transfer.State, c transfer.Cause) error
+	History(tpId string) ([]transfer.Transition, error)
+	// SetOffset records the number of bytes of the data acknowledged by the receiver
+	// so that a resumed transfer continues from the offset
+	SetOffset(tpId string, offset int64) error
+	// Offset returns zero if no data has been acknowledged for the process
+	Offset(tpId string) (int64, error)
+	// WithTx returns the store bound to the transaction, so that its writes are committed
+	// along with the other writes of the transaction
+	WithTx(tx pkg.Transaction) TransferStore
+}
+
+// FetchStore maintains the progress of the datasets fetched by the consumer
+type FetchStore interface {
+	SetFetch(id string, val consumer.Fetch) error
+	Fetch(id string) (consumer.Fetch, error)
 }
@@ -5,71 +5,131 @@ import (
 	"fmt"
 	"github.com/YasiruR/connector/domain/pkg"
 	"io"
-	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
 type Client struct {
-	hc *http.Client
+	hc  *http.Client
+	iam pkg.IAM
 }
 
-func NewClient(log pkg.Log) *Client {
+func NewClient(iam pkg.IAM, log pkg.Log) *Client {
 	log.Info("initialized an HTTP client")
-	return &Client{hc: http.DefaultClient}
+	return &Client{hc: http.DefaultClient, iam: iam}
 }
 
 func (c *Client) Send(data []byte, destination any) (res []byte, err error) {
+	res, _, err = c.exchange(data, destination)
+	return res, err
+}
+
+// SendPage returns the target of the 'next' link relation in the Link header of the
+// response (RFC 8288), resolved against the destination if it is a relative reference
+func (c *Client) SendPage(data []byte, destination any) (res []byte, next any, err error) {
+	res, header, err := c.exchange(data, destination)
+	if err != nil {
+		return res, nil, err
+	}
+
+	link, ok := nextLink(header)
+	if !ok {
+		return res, nil, nil
+	}
+
+	ref, err := url.Parse(link)
+	if err != nil {
+		return nil, nil, invalidLink(link, err)
+	}
+
+	base, err := url.Parse(destination.(string))
+	if err != nil {
+		return nil, nil, invalidLink(link, err)
+	}
+
+	return res, base.ResolveReference(ref).String(), nil
+}
+
+func (c *Client) exchange(data []byte, destination any) (res []byte, header http.Header, err error) {
 	addr, ok := destination.(string)
 	if !ok {
-		return nil, urlStringError(destination)
+		return nil, nil, urlStringError(destination)
 	}
 
-	var status int
-	if data == nil {
-		res, status, err = c.get(addr)
-		if err != nil {
-			return nil, sendFailed(addr, http.MethodGet, err)
-		}
-	} else {
-		res, status, err = c.post(addr, data)
-		if err != nil {
-			return nil, sendFailed(addr, http.MethodPost, err)
-		}
+	method, body := http.MethodGet, io.Reader(nil)
+	if data != nil {
+		method, body = http.MethodPost, bytes.NewBuffer(data)
+	}
+
+	res, header, status, err := c.send(method, addr, body)
+	if err != nil {
+		return nil, nil, sendFailed(addr, method, err)
 	}
 
 	if status != http.StatusOK && status != http.StatusCreated {
-		return res, invalidStatusCode(status)
+		return res, nil, invalidStatusCode(status)
 	}
 
-	return res, nil
+	return res, header, nil
 }
 
-func (c *Client) get(addr string) (response []byte, statusCode int, err error) {
-	res, err := c.hc.Get(addr)
+// send attaches the identity token of the connector so that the request can be
+// authenticated by the receiving participant
+func (c *Client) send(method, addr string, body io.Reader) (response []byte, header http.Header,
+	statusCode int, err error) {
+	req, err := http.NewRequest(method, addr, body)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to send HTTP GET request: %w", err)
+		return nil, nil, 0, fmt.Errorf("failed to create HTTP %s request: %w", method, err)
 	}
-	defer res.Body.Close()
 
-	resData, err := ioutil.ReadAll(res.Body)
+	token, err := c.iam.Token(addr)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to read HTTP response body: %w", err)
+		return nil, nil, 0, tokenFailed(err)
 	}
 
-	return resData, res.StatusCode, nil
-}
+	req.Header.Set(`Authorization`, `Bearer `+token)
+	if body != nil {
+		req.Header.Set(`Content-Type`, `application/json`)
+	}
 
-func (c *Client) post(url string, data []byte) (resData []byte, statusCode int, err error) {
-	res, err := c.hc.Post(url, `application/json`, bytes.NewBuffer(data))
+	res, err := c.hc.Do(req)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to send HTTP POST request: %w", err)
+		return nil, nil, 0, fmt.Errorf("failed to send HTTP %s request: %w", method, err)
 	}
 	defer res.Body.Close()
 
-	resData, err = io.ReadAll(res.Body)
+	resData, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to read HTTP response body: %w", err)
+		return nil, nil, 0, fmt.Errorf("failed to read HTTP response body: %w", err)
 	}
 
-	return resData, res.StatusCode, nil
+	return resData, res.Header, res.StatusCode, nil
+}
+
+// nextLink returns the target of a link-value of the form '<uri>; rel="next"', where
+// the relation type may be one of several space-separated types
+func nextLink(header http.Header) (string, bool) {
+	for _, val := range header.Values(`Link`) {
+		for _, link := range strings.Split(val, `,`) {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), `>`)
+			if !ok || !strings.HasPrefix(target, `<`) {
+				continue
+			}
+
+			for _, param := range strings.Split(params, `;`) {
+				key, rel, ok := strings.Cut(strings.TrimSpace(param), `=`)
+				if !ok || !strings.EqualFold(strings.TrimSpace(key), `rel`) {
+					continue
+				}
+
+				for _, typ := range strings.Fields(strings.Trim(strings.TrimSpace(rel), `"`)) {
+					if strings.EqualFold(typ, `next`) {
+						return strings.TrimPrefix(target, `<`), true
+					}
+				}
+			}
+		}
+	}
+	return ``, false
 }
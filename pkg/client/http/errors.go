@@ -15,3 +15,11 @@ func urlStringError(dest any) error {
 func sendFailed(endpoint, method string, err error) error {
 	return fmt.Errorf("sending message failed (endpoint: %s, method: %s) - %s", endpoint, method, err)
 }
+
+func tokenFailed(err error) error {
+	return fmt.Errorf("fetching identity token failed - %s", err)
+}
+
+func invalidLink(link string, err error) error {
+	return fmt.Errorf("invalid link to the next page (link: %s) - %s", link, err)
+}
@@ -0,0 +1,167 @@
+package database_test
+
+import (
+	defaultErr "errors"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/pkg/database"
+	"github.com/YasiruR/connector/pkg/database/memory"
+	"github.com/YasiruR/connector/pkg/database/sqlite"
+	"github.com/YasiruR/connector/pkg/log"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// databases returns each implementation of the database so that all of them are
+// tested against the same behaviour of pkg.Database
+func databases(t *testing.T) map[string]pkg.Database {
+	l := log.NewLogger()
+	return map[string]pkg.Database{
+		`memory`:    memory.NewStore(l),
+		sqlite.Type: sqlite.NewStore(filepath.Join(t.TempDir(), `test.db`), l),
+	}
+}
+
+func TestCollection_Scan(t *testing.T) {
+	for typ, db := range databases(t) {
+		coll := db.NewCollection(`scan`, ``)
+		for _, key := range []string{`b/3`, `a/1`, `b/1`, `b/2`, `c/1`} {
+			if err := coll.Set(key, key); err != nil {
+				t.Fatalf("%s: Set failed - %s", typ, err)
+			}
+		}
+
+		vals, next, err := coll.Scan(`b/`, ``, 0)
+		if err != nil || next != `` || !reflect.DeepEqual(vals, []any{`b/1`, `b/2`, `b/3`}) {
+			t.Errorf("%s: Scan returned %v (next: %s, error: %v)", typ, vals, next, err)
+		}
+
+		// pages are followed by the cursor until the last page
+		var pages [][]any
+		for cursor := ``; ; {
+			vals, next, err = coll.Scan(`b/`, cursor, 2)
+			if err != nil {
+				t.Fatalf("%s: Scan failed - %s", typ, err)
+			}
+
+			pages = append(pages, vals)
+			if next == `` {
+				break
+			}
+			cursor = next
+		}
+
+		if !reflect.DeepEqual(pages, [][]any{{`b/1`, `b/2`}, {`b/3`}}) {
+			t.Errorf("%s: Scan returned pages %v", typ, pages)
+		}
+
+		// pending writes of a transaction are included in the scan within the transaction
+		err = db.Transaction(func(tx pkg.Transaction) error {
+			view := tx.Collection(`scan`)
+			if err := view.Delete(`b/1`); err != nil {
+				return err
+			}
+
+			if err := view.Set(`b/4`, `b/4`); err != nil {
+				return err
+			}
+
+			vals, _, err := view.Scan(`b/`, `b/2`, 0)
+			if err != nil {
+				return err
+			}
+
+			if !reflect.DeepEqual(vals, []any{`b/3`, `b/4`}) {
+				t.Errorf("%s: Scan within the transaction returned %v", typ, vals)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: Transaction failed - %s", typ, err)
+		}
+	}
+}
+
+func TestCollection_CompareAndSwap(t *testing.T) {
+	for typ, db := range databases(t) {
+		coll := db.NewCollection(`cas`, ``)
+		tests := []struct {
+			name    string
+			old     any
+			new     string
+			swapped bool
+		}{
+			{name: `absent key`, old: nil, new: `v1`, swapped: true},
+			{name: `existing key with nil`, old: nil, new: `v2`, swapped: false},
+			{name: `outdated value`, old: `v0`, new: `v2`, swapped: false},
+			{name: `current value`, old: `v1`, new: `v2`, swapped: true},
+		}
+
+		for _, test := range tests {
+			swapped, err := coll.CompareAndSwap(`key`, test.old, test.new)
+			if err != nil {
+				t.Fatalf("%s: CompareAndSwap with %s failed - %s", typ, test.name, err)
+			}
+
+			if swapped != test.swapped {
+				t.Errorf("%s: CompareAndSwap with %s returned %t, want %t", typ, test.name, swapped, test.swapped)
+			}
+		}
+
+		if val, err := coll.Get(`key`); err != nil || val != `v2` {
+			t.Errorf("%s: value after CompareAndSwap is %v (error: %v), want v2", typ, val, err)
+		}
+	}
+}
+
+func TestDatabase_Transaction(t *testing.T) {
+	errFn := defaultErr.New(`fn failed`)
+	for typ, db := range databases(t) {
+		coll := db.NewCollection(`tx`, ``)
+		if err := coll.Set(`existing`, `original`); err != nil {
+			t.Fatalf("%s: Set failed - %s", typ, err)
+		}
+
+		err := db.Transaction(func(tx pkg.Transaction) error {
+			view := tx.Collection(`tx`)
+			if err := view.Set(`existing`, `updated`); err != nil {
+				return err
+			}
+
+			if err := view.Set(`new`, `new`); err != nil {
+				return err
+			}
+
+			// writes are visible within the transaction before they are committed
+			if val, err := view.Get(`new`); err != nil || val != `new` {
+				t.Errorf("%s: Get within the transaction returned %v (error: %v)", typ, val, err)
+			}
+			return errFn
+		})
+		if !defaultErr.Is(err, errFn) {
+			t.Fatalf("%s: Transaction returned %v, want %v", typ, err, errFn)
+		}
+
+		if val, err := coll.Get(`existing`); err != nil || val != `original` {
+			t.Errorf("%s: rolled back update is stored (value: %v, error: %v)", typ, val, err)
+		}
+
+		if val, err := coll.Get(`new`); err != nil || val != nil {
+			t.Errorf("%s: rolled back insert is stored (value: %v, error: %v)", typ, val, err)
+		}
+
+		// transactions of a bound database are committed along with the outer transaction
+		err = db.Transaction(func(tx pkg.Transaction) error {
+			return database.Bound(tx).Transaction(func(inner pkg.Transaction) error {
+				return inner.Collection(`tx`).Set(`existing`, `updated`)
+			})
+		})
+		if err != nil {
+			t.Fatalf("%s: Transaction failed - %s", typ, err)
+		}
+
+		if val, err := coll.Get(`existing`); err != nil || val != `updated` {
+			t.Errorf("%s: committed update is not stored (value: %v, error: %v)", typ, val, err)
+		}
+	}
+}
@@ -2,29 +2,67 @@ package memory
 
 import (
 	"github.com/YasiruR/connector/domain/pkg"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
 )
 
 type Store struct {
-	maps []pkg.Collection
+	maps   map[string]*Map
+	lock   sync.Mutex
+	txLock *sync.Mutex // serializes writes and transactions across all collections
 }
 
 func NewStore(log pkg.Log) *Store {
 	log.Info("initialized in-memory database with sync.Map as collections")
-	return &Store{maps: make([]pkg.Collection, 0)}
+	return &Store{maps: make(map[string]*Map), txLock: new(sync.Mutex)}
 }
 
-func (s *Store) NewCollection() pkg.Collection {
-	m := &Map{data: new(sync.Map)}
-	s.maps = append(s.maps, m)
+// NewCollection returns the collection for the given name. Type hint is not
+// required since values are stored as they are.
+func (s *Store) NewCollection(name string, _ any) pkg.Collection {
+	return s.collection(name)
+}
+
+func (s *Store) collection(name string) *Map {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if m, ok := s.maps[name]; ok {
+		return m
+	}
+
+	m := &Map{data: new(sync.Map), txLock: s.txLock}
+	s.maps[name] = m
 	return m
 }
 
+// Transaction buffers the writes made within fn and applies them to the
+// collections only if fn succeeds
+func (s *Store) Transaction(fn func(tx pkg.Transaction) error) error {
+	s.txLock.Lock()
+	defer s.txLock.Unlock()
+
+	tx := &Tx{store: s, views: make(map[string]*TxMap)}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	for _, v := range tx.views {
+		v.commit()
+	}
+	return nil
+}
+
 type Map struct {
-	data *sync.Map
+	data   *sync.Map
+	txLock *sync.Mutex
 }
 
 func (m *Map) Set(key string, value any) error {
+	m.txLock.Lock()
+	defer m.txLock.Unlock()
 	m.data.Store(key, value)
 	return nil
 }
@@ -46,3 +84,170 @@ func (m *Map) GetAll() ([]any, error) {
 	})
 	return data, nil
 }
+
+func (m *Map) Delete(key string) error {
+	m.txLock.Lock()
+	defer m.txLock.Unlock()
+	m.data.Delete(key)
+	return nil
+}
+
+func (m *Map) CompareAndSwap(key string, old, new any) (swapped bool, err error) {
+	m.txLock.Lock()
+	defer m.txLock.Unlock()
+
+	cur, _ := m.Get(key)
+	if !equal(cur, old) {
+		return false, nil
+	}
+
+	m.data.Store(key, new)
+	return true, nil
+}
+
+func (m *Map) Scan(prefix, cursor string, limit int) (vals []any, next string, err error) {
+	var keys []string
+	m.data.Range(func(key, _ any) bool {
+		k := key.(string)
+		if strings.HasPrefix(k, prefix) && k > cursor {
+			keys = append(keys, k)
+		}
+		return true
+	})
+	sort.Strings(keys)
+
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+		next = keys[limit-1]
+	}
+
+	vals = make([]any, 0, len(keys))
+	for _, k := range keys {
+		// skip the keys deleted after the iteration
+		if val, ok := m.data.Load(k); ok {
+			vals = append(vals, val)
+		}
+	}
+
+	return vals, next, nil
+}
+
+func (m *Map) Query(filter pkg.Filter) ([]any, error) {
+	data := make([]any, 0)
+	m.data.Range(func(key, val any) bool {
+		if filter(key.(string), val) {
+			data = append(data, val)
+		}
+		return true
+	})
+	return data, nil
+}
+
+type Tx struct {
+	store *Store
+	views map[string]*TxMap
+}
+
+func (t *Tx) Collection(name string) pkg.Collection {
+	if v, ok := t.views[name]; ok {
+		return v
+	}
+
+	v := &TxMap{m: t.store.collection(name), pending: make(map[string]pending)}
+	t.views[name] = v
+	return v
+}
+
+type pending struct {
+	val     any
+	deleted bool
+}
+
+// TxMap is a view of a Map within a transaction. Writes are kept in pending
+// until the transaction is committed while reads include the pending writes.
+type TxMap struct {
+	m       *Map
+	pending map[string]pending
+}
+
+func (t *TxMap) Set(key string, value any) error {
+	t.pending[key] = pending{val: value}
+	return nil
+}
+
+func (t *TxMap) Get(key string) (any, error) {
+	if p, ok := t.pending[key]; ok {
+		if p.deleted {
+			return nil, nil
+		}
+		return p.val, nil
+	}
+
+	return t.m.Get(key)
+}
+
+func (t *TxMap) GetAll() ([]any, error) {
+	return t.merged().GetAll()
+}
+
+func (t *TxMap) Delete(key string) error {
+	t.pending[key] = pending{deleted: true}
+	return nil
+}
+
+func (t *TxMap) CompareAndSwap(key string, old, new any) (swapped bool, err error) {
+	cur, _ := t.Get(key)
+	if !equal(cur, old) {
+		return false, nil
+	}
+
+	t.pending[key] = pending{val: new}
+	return true, nil
+}
+
+func (t *TxMap) Scan(prefix, cursor string, limit int) (vals []any, next string, err error) {
+	return t.merged().Scan(prefix, cursor, limit)
+}
+
+func (t *TxMap) Query(filter pkg.Filter) ([]any, error) {
+	return t.merged().Query(filter)
+}
+
+// merged returns a copy of the underlying Map with the pending writes applied
+func (t *TxMap) merged() *Map {
+	cp := &Map{data: new(sync.Map), txLock: new(sync.Mutex)}
+	t.m.data.Range(func(key, val any) bool {
+		cp.data.Store(key, val)
+		return true
+	})
+
+	for key, p := range t.pending {
+		if p.deleted {
+			cp.data.Delete(key)
+			continue
+		}
+		cp.data.Store(key, p.val)
+	}
+
+	return cp
+}
+
+// commit applies pending writes to the underlying Map and must only be called
+// while holding the transaction lock
+func (t *TxMap) commit() {
+	for key, p := range t.pending {
+		if p.deleted {
+			t.m.data.Delete(key)
+			continue
+		}
+		t.m.data.Store(key, p.val)
+	}
+}
+
+func equal(cur, old any) bool {
+	if old == nil {
+		return cur == nil
+	}
+
+	return reflect.DeepEqual(cur, old)
+}
@@ -0,0 +1,28 @@
+package sqlite
+
+import "fmt"
+
+func openFailed(path string, err error) error {
+	return fmt.Errorf("opening SQLite database failed (file: %s) - %s", path, err)
+}
+
+func queryFailed(table, query string, err error) error {
+	return fmt.Errorf("query failed (table: %s, query: %s) - %s", table, query, err)
+}
+
+func unregisteredType(name string) error {
+	return fmt.Errorf("type is not registered in the database (type: %s)", name)
+}
+
+func invalidType(table, expected, received string) error {
+	return fmt.Errorf("value type does not match with the collection (table: %s, expected: %s, received: %s)",
+		table, expected, received)
+}
+
+func encodeFailed(name string, err error) error {
+	return fmt.Errorf("encoding value failed (type: %s) - %s", name, err)
+}
+
+func decodeFailed(name string, err error) error {
+	return fmt.Errorf("decoding stored value failed (type: %s) - %s", name, err)
+}
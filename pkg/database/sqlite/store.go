@@ -0,0 +1,436 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/YasiruR/connector/domain/pkg"
+	_ "modernc.org/sqlite"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	Type       = `sqlite`
+	driverName = `sqlite`
+)
+
+// Store is a persistent implementation of pkg.Database backed by an embedded
+// SQLite database file. Each Collection is mapped to a separate table where values
+// are stored as JSON along with the name of their concrete type, so that they can
+// be decoded back into the same type when queried.
+type Store struct {
+	db     *sql.DB
+	types  *sync.Map // type name -> reflect.Type
+	tables map[string]*Table
+	lock   sync.Mutex
+	log    pkg.Log
+}
+
+// querier is implemented by both sql.DB and sql.Tx so that a Table can be used
+// within or outside a transaction
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func NewStore(path string, log pkg.Log) *Store {
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		log.Fatal(openFailed(path, err))
+	}
+
+	// SQLite allows a single writer at a time and therefore, connections are
+	// limited to avoid busy errors on concurrent writes. This also serializes
+	// transactions with any other query.
+	db.SetMaxOpenConns(1)
+	if err = db.Ping(); err != nil {
+		log.Fatal(openFailed(path, err))
+	}
+
+	log.Info("initialized SQLite database with tables as collections", "file: "+path)
+	return &Store{db: db, types: new(sync.Map), tables: make(map[string]*Table), log: log}
+}
+
+// NewCollection creates a table named after the collection if it does not exist.
+// Type of the given sample value is registered so that stored values can be
+// decoded into the same type. If the sample value is nil, values of any type
+// registered by other collections are accepted.
+func (s *Store) NewCollection(name string, typ any) pkg.Collection {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if t, ok := s.tables[name]; ok {
+		return t
+	}
+
+	t := &Table{name: name, store: s, q: s.db}
+	if typ != nil {
+		t.typ = reflect.TypeOf(typ)
+		s.types.Store(typeName(t.typ), t.typ)
+	}
+
+	// collections are created when the connector is initialized and therefore, the
+	// connector can not proceed without the table
+	if err := t.create(); err != nil {
+		s.log.Fatal(err)
+	}
+
+	s.tables[name] = t
+	return t
+}
+
+func (s *Store) Transaction(fn func(tx pkg.Transaction) error) error {
+	sqlTx, err := s.db.Begin()
+	if err != nil {
+		return queryFailed(``, `begin`, err)
+	}
+
+	tx := &Tx{store: s, tx: sqlTx, created: make(map[string]*Table)}
+	if err = fn(tx); err != nil {
+		_ = sqlTx.Rollback()
+		return err
+	}
+
+	if err = sqlTx.Commit(); err != nil {
+		return queryFailed(``, `commit`, err)
+	}
+
+	// tables created within the transaction exist only once it is committed
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for name, t := range tx.created {
+		if _, ok := s.tables[name]; !ok {
+			s.tables[name] = t
+		}
+	}
+	return nil
+}
+
+type Tx struct {
+	store   *Store
+	tx      *sql.Tx
+	created map[string]*Table // tables which did not exist before the transaction
+}
+
+// Collection creates the table within the transaction if it has not been created by
+// NewCollection, in which case the stored values are decoded by registered types
+func (t *Tx) Collection(name string) pkg.Collection {
+	t.store.lock.Lock()
+	stored, ok := t.store.tables[name]
+	t.store.lock.Unlock()
+
+	if !ok {
+		if stored, ok = t.created[name]; !ok {
+			stored = &Table{name: name, store: t.store, q: t.tx}
+			if err := stored.create(); err != nil {
+				return unavailable{err: err}
+			}
+			stored.q = t.store.db
+			t.created[name] = stored
+		}
+	}
+
+	tbl := *stored
+	tbl.q = t.tx
+	tbl.inTx = true
+	return &tbl
+}
+
+type Table struct {
+	name  string
+	typ   reflect.Type // nil if the collection is not typed
+	store *Store
+	q     querier
+	inTx  bool
+}
+
+func (t *Table) create() error {
+	_, err := t.q.Exec(`CREATE TABLE IF NOT EXISTS ` + t.ident() + ` (
+		key TEXT PRIMARY KEY,
+		type TEXT NOT NULL,
+		value TEXT NOT NULL
+	)`)
+	if err != nil {
+		return queryFailed(t.name, `create`, err)
+	}
+	return nil
+}
+
+func (t *Table) Set(key string, value any) error {
+	name, data, err := t.encode(value)
+	if err != nil {
+		return err
+	}
+
+	if _, err = t.q.Exec(`INSERT INTO `+t.ident()+` (key, type, value) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET type = excluded.type, value = excluded.value`,
+		key, name, data); err != nil {
+		return queryFailed(t.name, `set`, err)
+	}
+
+	return nil
+}
+
+func (t *Table) Get(key string) (any, error) {
+	name, data, err := t.raw(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == `` {
+		return nil, nil
+	}
+
+	return t.decode(name, data)
+}
+
+func (t *Table) GetAll() ([]any, error) {
+	rows, err := t.q.Query(`SELECT type, value FROM ` + t.ident() + ` ORDER BY rowid`)
+	if err != nil {
+		return nil, queryFailed(t.name, `get all`, err)
+	}
+	defer rows.Close()
+
+	vals := make([]any, 0)
+	for rows.Next() {
+		var name, data string
+		if err = rows.Scan(&name, &data); err != nil {
+			return nil, queryFailed(t.name, `get all`, err)
+		}
+
+		val, err := t.decode(name, data)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, val)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, queryFailed(t.name, `get all`, err)
+	}
+
+	return vals, nil
+}
+
+func (t *Table) Delete(key string) error {
+	if _, err := t.q.Exec(`DELETE FROM `+t.ident()+` WHERE key = ?`, key); err != nil {
+		return queryFailed(t.name, `delete`, err)
+	}
+	return nil
+}
+
+// CompareAndSwap compares the encoded values since values of the same type are
+// always encoded identically. It is executed in a separate transaction if the
+// Table is not already bound to one.
+func (t *Table) CompareAndSwap(key string, old, new any) (swapped bool, err error) {
+	if !t.inTx {
+		err = t.store.Transaction(func(tx pkg.Transaction) error {
+			swapped, err = tx.Collection(t.name).CompareAndSwap(key, old, new)
+			return err
+		})
+		return swapped, err
+	}
+
+	_, cur, err := t.raw(key)
+	if err != nil {
+		return false, err
+	}
+
+	if old == nil {
+		if cur != `` {
+			return false, nil
+		}
+	} else {
+		_, data, err := t.encode(old)
+		if err != nil {
+			return false, err
+		}
+
+		if cur != data {
+			return false, nil
+		}
+	}
+
+	if err = t.Set(key, new); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (t *Table) Scan(prefix, cursor string, limit int) (vals []any, next string, err error) {
+	query := `SELECT key, type, value FROM ` + t.ident() + ` WHERE substr(key, 1, length(?)) = ?
+		AND key > ? ORDER BY key`
+	args := []any{prefix, prefix, cursor}
+	if limit > 0 {
+		// an additional row is fetched to find if there are more pages
+		query += ` LIMIT ?`
+		args = append(args, limit+1)
+	}
+
+	rows, err := t.q.Query(query, args...)
+	if err != nil {
+		return nil, ``, queryFailed(t.name, `scan`, err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	vals = make([]any, 0)
+	for rows.Next() {
+		var key, name, data string
+		if err = rows.Scan(&key, &name, &data); err != nil {
+			return nil, ``, queryFailed(t.name, `scan`, err)
+		}
+
+		val, err := t.decode(name, data)
+		if err != nil {
+			return nil, ``, err
+		}
+
+		keys = append(keys, key)
+		vals = append(vals, val)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, ``, queryFailed(t.name, `scan`, err)
+	}
+
+	if limit > 0 && len(vals) > limit {
+		return vals[:limit], keys[limit-1], nil
+	}
+
+	return vals, ``, nil
+}
+
+// Query decodes each stored value and applies the filter since predicates can
+// not be translated into SQL
+func (t *Table) Query(filter pkg.Filter) ([]any, error) {
+	rows, err := t.q.Query(`SELECT key, type, value FROM ` + t.ident() + ` ORDER BY rowid`)
+	if err != nil {
+		return nil, queryFailed(t.name, `query`, err)
+	}
+	defer rows.Close()
+
+	vals := make([]any, 0)
+	for rows.Next() {
+		var key, name, data string
+		if err = rows.Scan(&key, &name, &data); err != nil {
+			return nil, queryFailed(t.name, `query`, err)
+		}
+
+		val, err := t.decode(name, data)
+		if err != nil {
+			return nil, err
+		}
+
+		if filter(key, val) {
+			vals = append(vals, val)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, queryFailed(t.name, `query`, err)
+	}
+
+	return vals, nil
+}
+
+// raw returns the type name and encoded value of the key, both of which are
+// empty if the key does not exist
+func (t *Table) raw(key string) (name, data string, err error) {
+	err = t.q.QueryRow(`SELECT type, value FROM `+t.ident()+` WHERE key = ?`, key).Scan(&name, &data)
+	if err == sql.ErrNoRows {
+		return ``, ``, nil
+	}
+
+	if err != nil {
+		return ``, ``, queryFailed(t.name, `get`, err)
+	}
+
+	return name, data, nil
+}
+
+// ident returns the quoted table name to be used in queries
+func (t *Table) ident() string {
+	return `"` + strings.ReplaceAll(t.name, `"`, `""`) + `"`
+}
+
+func (t *Table) encode(value any) (name, data string, err error) {
+	name = typeName(reflect.TypeOf(value))
+	if t.typ != nil && t.typ != reflect.TypeOf(value) {
+		return ``, ``, invalidType(t.name, typeName(t.typ), name)
+	}
+
+	if _, ok := t.store.types.Load(name); !ok {
+		return ``, ``, unregisteredType(name)
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return ``, ``, encodeFailed(name, err)
+	}
+
+	return name, string(encoded), nil
+}
+
+// decode unmarshalls stored data into a new value of its registered concrete type
+func (t *Table) decode(name, data string) (any, error) {
+	typ, ok := t.store.types.Load(name)
+	if !ok {
+		return nil, unregisteredType(name)
+	}
+
+	ptr := reflect.New(typ.(reflect.Type))
+	if err := json.Unmarshal([]byte(data), ptr.Interface()); err != nil {
+		return nil, decodeFailed(name, err)
+	}
+
+	return ptr.Elem().Interface(), nil
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return `nil`
+	}
+
+	if t.PkgPath() == `` {
+		return t.String()
+	}
+
+	return t.PkgPath() + `.` + t.Name()
+}
+
+// unavailable is returned for a collection of which the table could not be created
+// so that the error is returned by each query
+type unavailable struct {
+	err error
+}
+
+func (u unavailable) Get(string) (any, error) {
+	return nil, u.err
+}
+
+func (u unavailable) GetAll() ([]any, error) {
+	return nil, u.err
+}
+
+func (u unavailable) Set(string, any) error {
+	return u.err
+}
+
+func (u unavailable) Delete(string) error {
+	return u.err
+}
+
+func (u unavailable) CompareAndSwap(string, any, any) (bool, error) {
+	return false, u.err
+}
+
+func (u unavailable) Scan(string, string, int) ([]any, string, error) {
+	return nil, ``, u.err
+}
+
+func (u unavailable) Query(pkg.Filter) ([]any, error) {
+	return nil, u.err
+}
@@ -0,0 +1,157 @@
+package sqlite
+
+import (
+	defaultErr "errors"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/pkg/log"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type record struct {
+	ID     string
+	Values map[string]int
+	Nested *record
+}
+
+func TestStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `test.db`)
+	l := log.NewLogger()
+	rec := record{ID: `record`, Values: map[string]int{`a`: 1}, Nested: &record{ID: `nested`}}
+
+	s := NewStore(path, l)
+	if err := s.NewCollection(`records`, record{}).Set(rec.ID, rec); err != nil {
+		t.Fatalf("Set failed - %s", err)
+	}
+
+	err := s.Transaction(func(tx pkg.Transaction) error {
+		return tx.Collection(`records`).Set(`committed`, record{ID: `committed`})
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed - %s", err)
+	}
+
+	if err = s.db.Close(); err != nil {
+		t.Fatalf("closing the database failed - %s", err)
+	}
+
+	// stored values are decoded into their concrete types once the collection is created again
+	reopened := NewStore(path, l)
+	coll := reopened.NewCollection(`records`, record{})
+	val, err := coll.Get(rec.ID)
+	if err != nil {
+		t.Fatalf("Get failed - %s", err)
+	}
+
+	if !reflect.DeepEqual(val, rec) {
+		t.Errorf("reopened database returned %#v, want %#v", val, rec)
+	}
+
+	if val, err = coll.Get(`committed`); err != nil || val == nil {
+		t.Errorf("value committed in a transaction was not persisted (value: %v, error: %v)", val, err)
+	}
+}
+
+func TestStore_Transaction(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), `test.db`), log.NewLogger())
+	coll := s.NewCollection(`counter`, 0)
+	if err := coll.Set(`counter`, 0); err != nil {
+		t.Fatalf("Set failed - %s", err)
+	}
+
+	// read-modify-write transactions are serialized and therefore, no increment is lost
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.Transaction(func(tx pkg.Transaction) error {
+				view := tx.Collection(`counter`)
+				val, err := view.Get(`counter`)
+				if err != nil {
+					return err
+				}
+				return view.Set(`counter`, val.(int)+1)
+			})
+			if err != nil {
+				t.Errorf("Transaction failed - %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if val, err := coll.Get(`counter`); err != nil || val != n {
+		t.Errorf("counter is %v (error: %v), want %d", val, err, n)
+	}
+
+	// table created within a rolled back transaction does not exist and is created again
+	errFn := defaultErr.New(`fn failed`)
+	err := s.Transaction(func(tx pkg.Transaction) error {
+		if err := tx.Collection(`rolled-back`).Set(`key`, 1); err != nil {
+			return err
+		}
+		return errFn
+	})
+	if !defaultErr.Is(err, errFn) {
+		t.Fatalf("Transaction returned %v, want %v", err, errFn)
+	}
+
+	if val, err := s.NewCollection(`rolled-back`, 0).Get(`key`); err != nil || val != nil {
+		t.Errorf("table of a rolled back transaction returned %v (error: %v)", val, err)
+	}
+}
+
+func TestTable_CompareAndSwap(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), `test.db`), log.NewLogger())
+	coll := s.NewCollection(`cas`, ``)
+
+	// only one of the concurrent inserts of the same key succeeds
+	const n = 10
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var winners []string
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(val string) {
+			defer wg.Done()
+			swapped, err := coll.CompareAndSwap(`key`, nil, val)
+			if err != nil {
+				t.Errorf("CompareAndSwap failed - %s", err)
+				return
+			}
+
+			if swapped {
+				lock.Lock()
+				winners = append(winners, val)
+				lock.Unlock()
+			}
+		}(string(rune('a' + i)))
+	}
+	wg.Wait()
+
+	if len(winners) != 1 {
+		t.Fatalf("%d concurrent inserts succeeded (%v), want 1", len(winners), winners)
+	}
+
+	// swap within a transaction fails if the value is modified before the swap
+	var swapped bool
+	err := s.Transaction(func(tx pkg.Transaction) (err error) {
+		view := tx.Collection(`cas`)
+		if err = view.Set(`key`, `modified`); err != nil {
+			return err
+		}
+
+		swapped, err = view.CompareAndSwap(`key`, winners[0], `swapped`)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed - %s", err)
+	}
+
+	if val, err := coll.Get(`key`); swapped || err != nil || val != `modified` {
+		t.Errorf("CompareAndSwap of an outdated value returned %t (value: %v, error: %v)", swapped, val, err)
+	}
+}
@@ -0,0 +1,23 @@
+package database
+
+import "github.com/YasiruR/connector/domain/pkg"
+
+// Bound returns a Database of which the collections are the views of the transaction, so
+// that a store initialized with it reads and writes within the transaction. Transactions
+// of the returned Database are executed as a part of the given transaction and are thus
+// committed or discarded along with it.
+func Bound(tx pkg.Transaction) pkg.Database {
+	return bound{tx: tx}
+}
+
+type bound struct {
+	tx pkg.Transaction
+}
+
+func (b bound) NewCollection(name string, _ any) pkg.Collection {
+	return b.tx.Collection(name)
+}
+
+func (b bound) Transaction(fn func(tx pkg.Transaction) error) error {
+	return fn(b.tx)
+}
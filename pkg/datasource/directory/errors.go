@@ -0,0 +1,23 @@
+package directory
+
+import "fmt"
+
+func invalidDir(dir string, err error) error {
+	return fmt.Errorf("data source does not refer to a directory (directory: %s) - %v", dir, err)
+}
+
+func invalidPath(path string) error {
+	return fmt.Errorf("path must be relative to the directory without referring to its parents (path: %s)", path)
+}
+
+func openFailed(path string, err error) error {
+	return fmt.Errorf("opening file of the directory failed (path: %s) - %s", path, err)
+}
+
+func notRegularFile(path string) error {
+	return fmt.Errorf("path does not refer to a regular file (path: %s)", path)
+}
+
+func invalidOffset(path string, offset, size int64) error {
+	return fmt.Errorf("offset exceeds the size of the file (path: %s, offset: %d, size: %d)", path, offset, size)
+}
@@ -0,0 +1,71 @@
+package directory
+
+import (
+	"context"
+	"github.com/YasiruR/connector/domain/pkg"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+const Type = `directory`
+
+// Source is an implementation of pkg.DataSource which serves the files of a local
+// directory, where paths are relative to the directory and can not refer to files
+// outside of it
+type Source struct {
+	dir string
+}
+
+func NewSource(dir string, log pkg.Log) *Source {
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		log.Fatal(invalidDir(dir, err))
+	}
+
+	log.Info("initialized directory data source", "directory: "+dir)
+	return &Source{dir: dir}
+}
+
+func (s *Source) Open(ctx context.Context, path string, offset int64) (io.ReadCloser, pkg.ObjectInfo, error) {
+	info, err := s.Stat(ctx, path)
+	if err != nil {
+		return nil, pkg.ObjectInfo{}, err
+	}
+
+	if offset > info.Size {
+		return nil, pkg.ObjectInfo{}, invalidOffset(path, offset, info.Size)
+	}
+
+	f, err := os.Open(s.file(path))
+	if err != nil {
+		return nil, pkg.ObjectInfo{}, openFailed(path, err)
+	}
+
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, pkg.ObjectInfo{}, openFailed(path, err)
+	}
+	return f, info, nil
+}
+
+func (s *Source) Stat(_ context.Context, path string) (pkg.ObjectInfo, error) {
+	if !filepath.IsLocal(filepath.FromSlash(path)) {
+		return pkg.ObjectInfo{}, invalidPath(path)
+	}
+
+	fi, err := os.Stat(s.file(path))
+	if err != nil {
+		return pkg.ObjectInfo{}, openFailed(path, err)
+	}
+
+	if !fi.Mode().IsRegular() {
+		return pkg.ObjectInfo{}, notRegularFile(path)
+	}
+
+	return pkg.ObjectInfo{Size: fi.Size(), ContentType: mime.TypeByExtension(filepath.Ext(path))}, nil
+}
+
+func (s *Source) file(path string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(path))
+}
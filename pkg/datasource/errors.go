@@ -0,0 +1,23 @@
+package datasource
+
+import "fmt"
+
+func missingId() error {
+	return fmt.Errorf("ID of the data source is not provided")
+}
+
+func duplicateId(id string) error {
+	return fmt.Errorf("data source ID is already registered (id: %s)", id)
+}
+
+func notFound(id string) error {
+	return fmt.Errorf("data source is not configured (id: %s)", id)
+}
+
+func offsetUnavailable(offset int64, err error) error {
+	return fmt.Errorf("content is not available from the offset (offset: %d) - %v", offset, err)
+}
+
+func invalidContentRange(val string) error {
+	return fmt.Errorf("invalid Content-Range header (value: %s)", val)
+}
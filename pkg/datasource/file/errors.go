@@ -0,0 +1,19 @@
+package file
+
+import "fmt"
+
+func pathNotSupported(path string) error {
+	return fmt.Errorf("file data source does not support paths (path: %s)", path)
+}
+
+func notRegularFile(file string) error {
+	return fmt.Errorf("data source does not refer to a regular file (file: %s)", file)
+}
+
+func openFailed(file string, err error) error {
+	return fmt.Errorf("opening file failed (file: %s) - %s", file, err)
+}
+
+func invalidOffset(offset, size int64) error {
+	return fmt.Errorf("offset exceeds the size of the file (offset: %d, size: %d)", offset, size)
+}
@@ -0,0 +1,62 @@
+package file
+
+import (
+	"context"
+	"github.com/YasiruR/connector/domain/pkg"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+const Type = `file`
+
+// Source is an implementation of pkg.DataSource which serves a single local file
+// and therefore, only accepts an empty path
+type Source struct {
+	file string
+}
+
+func NewSource(file string, log pkg.Log) *Source {
+	log.Info("initialized file data source", "file: "+file)
+	return &Source{file: file}
+}
+
+func (s *Source) Open(ctx context.Context, path string, offset int64) (io.ReadCloser, pkg.ObjectInfo, error) {
+	info, err := s.Stat(ctx, path)
+	if err != nil {
+		return nil, pkg.ObjectInfo{}, err
+	}
+
+	if offset > info.Size {
+		return nil, pkg.ObjectInfo{}, invalidOffset(offset, info.Size)
+	}
+
+	f, err := os.Open(s.file)
+	if err != nil {
+		return nil, pkg.ObjectInfo{}, openFailed(s.file, err)
+	}
+
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, pkg.ObjectInfo{}, openFailed(s.file, err)
+	}
+	return f, info, nil
+}
+
+func (s *Source) Stat(_ context.Context, path string) (pkg.ObjectInfo, error) {
+	if path != `` {
+		return pkg.ObjectInfo{}, pathNotSupported(path)
+	}
+
+	fi, err := os.Stat(s.file)
+	if err != nil {
+		return pkg.ObjectInfo{}, openFailed(s.file, err)
+	}
+
+	if fi.IsDir() {
+		return pkg.ObjectInfo{}, notRegularFile(s.file)
+	}
+
+	return pkg.ObjectInfo{Size: fi.Size(), ContentType: mime.TypeByExtension(filepath.Ext(s.file))}, nil
+}
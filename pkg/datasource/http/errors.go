@@ -0,0 +1,7 @@
+package http
+
+import "fmt"
+
+func requestFailed(url string, err error) error {
+	return fmt.Errorf("requesting content from the upstream server failed (url: %s) - %s", url, err)
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/pkg/datasource"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const Type = `http`
+
+// Source is an implementation of pkg.DataSource which streams the content from an
+// upstream HTTP server, where paths are appended to the base URL
+type Source struct {
+	baseURL string
+	hc      *http.Client
+}
+
+func NewSource(baseURL string, log pkg.Log) *Source {
+	log.Info("initialized HTTP data source", "base URL: "+baseURL)
+	return &Source{baseURL: baseURL, hc: http.DefaultClient}
+}
+
+func (s *Source) Open(ctx context.Context, path string, offset int64) (io.ReadCloser, pkg.ObjectInfo, error) {
+	url := s.url(path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, pkg.ObjectInfo{}, requestFailed(url, err)
+	}
+	datasource.SetRange(req, offset)
+
+	res, err := s.hc.Do(req)
+	if err != nil {
+		return nil, pkg.ObjectInfo{}, requestFailed(url, err)
+	}
+
+	content, info, err := datasource.Ranged(res, offset)
+	if err != nil {
+		return nil, pkg.ObjectInfo{}, requestFailed(url, err)
+	}
+	return content, info, nil
+}
+
+func (s *Source) Stat(ctx context.Context, path string) (pkg.ObjectInfo, error) {
+	url := s.url(path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return pkg.ObjectInfo{}, requestFailed(url, err)
+	}
+
+	res, err := s.hc.Do(req)
+	if err != nil {
+		return pkg.ObjectInfo{}, requestFailed(url, err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return pkg.ObjectInfo{}, requestFailed(url, fmt.Errorf("received status code %d", res.StatusCode))
+	}
+	return pkg.ObjectInfo{Size: res.ContentLength, ContentType: res.Header.Get(`Content-Type`)}, nil
+}
+
+// url returns the base URL itself if the path is empty so that a single resource
+// can be configured as the source
+func (s *Source) url(path string) string {
+	if path == `` {
+		return s.baseURL
+	}
+	return strings.TrimSuffix(s.baseURL, `/`) + `/` + strings.TrimPrefix(path, `/`)
+}
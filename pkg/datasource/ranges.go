@@ -0,0 +1,84 @@
+package datasource
+
+import (
+	"fmt"
+	"github.com/YasiruR/connector/domain/pkg"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SetRange requests the content starting from the offset so that an interrupted
+// transfer does not fetch the content which has already been sent
+func SetRange(req *http.Request, offset int64) {
+	if offset > 0 {
+		req.Header.Set(`Range`, `bytes=`+strconv.FormatInt(offset, 10)+`-`)
+	}
+}
+
+// Ranged returns the content of the response to a request with SetRange starting
+// from the offset, along with the total size of the resource. Servers which do not
+// support ranges respond with the complete content, of which the bytes before the
+// offset are discarded.
+func Ranged(res *http.Response, offset int64) (io.ReadCloser, pkg.ObjectInfo, error) {
+	info := pkg.ObjectInfo{Size: res.ContentLength, ContentType: res.Header.Get(`Content-Type`)}
+	switch res.StatusCode {
+	case http.StatusOK:
+		if offset > 0 {
+			if _, err := io.CopyN(io.Discard, res.Body, offset); err != nil {
+				res.Body.Close()
+				return nil, pkg.ObjectInfo{}, offsetUnavailable(offset, err)
+			}
+		}
+		return res.Body, info, nil
+	case http.StatusPartialContent:
+		start, total, err := contentRange(res.Header.Get(`Content-Range`))
+		if err != nil || start != offset {
+			res.Body.Close()
+			return nil, pkg.ObjectInfo{}, offsetUnavailable(offset, err)
+		}
+
+		info.Size = total
+		return res.Body, info, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		// offset is the end of the content if it has been completely sent already
+		res.Body.Close()
+		_, total, err := contentRange(res.Header.Get(`Content-Range`))
+		if err != nil || total != offset {
+			return nil, pkg.ObjectInfo{}, offsetUnavailable(offset, err)
+		}
+
+		info.Size = total
+		return io.NopCloser(strings.NewReader(``)), info, nil
+	default:
+		res.Body.Close()
+		return nil, pkg.ObjectInfo{}, fmt.Errorf("received status code %d", res.StatusCode)
+	}
+}
+
+// contentRange parses the start and the total size (-1 if unknown) of a Content-Range
+// header (e.g. bytes 100-199/1000 or bytes */1000)
+func contentRange(val string) (start, total int64, err error) {
+	rng, size, ok := strings.Cut(strings.TrimPrefix(val, `bytes `), `/`)
+	if !ok {
+		return 0, 0, invalidContentRange(val)
+	}
+
+	total = -1
+	if size != `*` {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, invalidContentRange(val)
+		}
+	}
+
+	if rng == `*` {
+		return 0, total, nil
+	}
+
+	first, _, _ := strings.Cut(rng, `-`)
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, invalidContentRange(val)
+	}
+	return start, total, nil
+}
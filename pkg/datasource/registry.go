@@ -0,0 +1,38 @@
+package datasource
+
+import (
+	"github.com/YasiruR/connector/domain/pkg"
+)
+
+// Registry is an implementation of pkg.DataSources which maps the IDs of the
+// configured data sources to their backends
+type Registry struct {
+	sources map[string]pkg.DataSource
+}
+
+func NewRegistry() *Registry {
+	return &Registry{sources: make(map[string]pkg.DataSource)}
+}
+
+// Register adds a data source under the given ID and returns an error if the ID is
+// already used by another data source
+func (r *Registry) Register(id string, src pkg.DataSource) error {
+	if id == `` {
+		return missingId()
+	}
+
+	if _, ok := r.sources[id]; ok {
+		return duplicateId(id)
+	}
+
+	r.sources[id] = src
+	return nil
+}
+
+func (r *Registry) DataSource(id string) (pkg.DataSource, error) {
+	src, ok := r.sources[id]
+	if !ok {
+		return nil, notFound(id)
+	}
+	return src, nil
+}
@@ -0,0 +1,19 @@
+package s3
+
+import "fmt"
+
+func invalidEndpoint(endpoint string, err error) error {
+	return fmt.Errorf("invalid endpoint for the S3 data source (endpoint: %s) - %v", endpoint, err)
+}
+
+func missingBucket(endpoint string) error {
+	return fmt.Errorf("bucket is not configured for the S3 data source (endpoint: %s)", endpoint)
+}
+
+func missingKey(bucket string) error {
+	return fmt.Errorf("object key is not provided (bucket: %s)", bucket)
+}
+
+func requestFailed(bucket, key string, err error) error {
+	return fmt.Errorf("requesting object failed (bucket: %s, key: %s) - %s", bucket, key, err)
+}
@@ -0,0 +1,81 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Signing of requests as per AWS Signature Version 4
+// (https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html)
+
+const (
+	algorithm     = `AWS4-HMAC-SHA256`
+	service       = `s3`
+	scopeSuffix   = `aws4_request`
+	headerDate    = `X-Amz-Date`
+	headerPayload = `X-Amz-Content-Sha256`
+	// requests of the source do not have a body
+	emptyPayloadHash = `e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855`
+	dateLayout       = `20060102`
+	timeLayout       = `20060102T150405Z`
+)
+
+func (s *Source) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format(timeLayout)
+	req.Header.Set(headerDate, amzDate)
+	req.Header.Set(headerPayload, emptyPayloadHash)
+
+	signedHeaders := `host;x-amz-content-sha256;x-amz-date`
+	canonicalReq := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		`host:` + req.URL.Host + "\n" +
+			`x-amz-content-sha256:` + emptyPayloadHash + "\n" +
+			`x-amz-date:` + amzDate + "\n",
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(dateLayout), s.region, service, scopeSuffix}, `/`)
+	strToSign := strings.Join([]string{algorithm, amzDate, scope, hashHex([]byte(canonicalReq))}, "\n")
+
+	key := hmacSHA256([]byte(`AWS4`+s.secretKey), now.Format(dateLayout))
+	for _, part := range []string{s.region, service, scopeSuffix} {
+		key = hmacSHA256(key, part)
+	}
+
+	req.Header.Set(`Authorization`, algorithm+` Credential=`+s.accessKey+`/`+scope+
+		`, SignedHeaders=`+signedHeaders+`, Signature=`+hex.EncodeToString(hmacSHA256(key, strToSign)))
+}
+
+// escapePath encodes each byte of the path except unreserved characters and slashes
+// as required by the canonical request
+func escapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteString(`%` + strings.ToUpper(hex.EncodeToString([]byte{c})))
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
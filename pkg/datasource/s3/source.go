@@ -0,0 +1,112 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/pkg/datasource"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	Type          = `s3`
+	defaultRegion = `us-east-1`
+)
+
+// Source is an implementation of pkg.DataSource which reads objects of a bucket in
+// an S3-compatible object store (e.g. MinIO) using path-style URLs, where paths are
+// the keys of the objects. Requests are signed with AWS Signature Version 4 if an
+// access key is configured and sent anonymously otherwise.
+type Source struct {
+	endpoint  *url.URL
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	hc        *http.Client
+}
+
+func NewSource(endpoint, bucket, region, accessKey, secretKey string, log pkg.Log) *Source {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == `` {
+		log.Fatal(invalidEndpoint(endpoint, err))
+	}
+
+	if bucket == `` {
+		log.Fatal(missingBucket(endpoint))
+	}
+
+	if region == `` {
+		region = defaultRegion
+	}
+
+	log.Info("initialized S3 data source", "endpoint: "+endpoint, "bucket: "+bucket)
+	return &Source{
+		endpoint:  u,
+		bucket:    bucket,
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		hc:        http.DefaultClient,
+	}
+}
+
+func (s *Source) Open(ctx context.Context, path string, offset int64) (io.ReadCloser, pkg.ObjectInfo, error) {
+	res, err := s.do(ctx, http.MethodGet, path, offset)
+	if err != nil {
+		return nil, pkg.ObjectInfo{}, err
+	}
+
+	content, info, err := datasource.Ranged(res, offset)
+	if err != nil {
+		return nil, pkg.ObjectInfo{}, requestFailed(s.bucket, path, err)
+	}
+	return content, info, nil
+}
+
+func (s *Source) Stat(ctx context.Context, path string) (pkg.ObjectInfo, error) {
+	res, err := s.do(ctx, http.MethodHead, path, 0)
+	if err != nil {
+		return pkg.ObjectInfo{}, err
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return pkg.ObjectInfo{}, requestFailed(s.bucket, path, fmt.Errorf("received status code %d",
+			res.StatusCode))
+	}
+	return pkg.ObjectInfo{Size: res.ContentLength, ContentType: res.Header.Get(`Content-Type`)}, nil
+}
+
+// do sends the request for the object and returns the response regardless of its
+// status code since ranged requests may result in different status codes
+func (s *Source) do(ctx context.Context, method, key string, offset int64) (*http.Response, error) {
+	key = strings.TrimPrefix(key, `/`)
+	if key == `` {
+		return nil, missingKey(s.bucket)
+	}
+
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, `/`) + `/` + s.bucket + `/` + key
+	u.RawPath = escapePath(u.Path)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, requestFailed(s.bucket, key, err)
+	}
+
+	datasource.SetRange(req, offset)
+	if s.accessKey != `` {
+		s.sign(req, time.Now().UTC())
+	}
+
+	res, err := s.hc.Do(req)
+	if err != nil {
+		return nil, requestFailed(s.bucket, key, err)
+	}
+	return res, nil
+}
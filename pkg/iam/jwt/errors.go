@@ -0,0 +1,58 @@
+package jwt
+
+import (
+	"fmt"
+	"time"
+)
+
+func emptyKey() error {
+	return fmt.Errorf("private key of the participant is required to sign tokens")
+}
+
+func loadKeyFailed(file string, err error) error {
+	return fmt.Errorf("loading key failed (file: %s) - %s", file, err)
+}
+
+func unsupportedKey(key any) error {
+	return fmt.Errorf("only Ed25519 keys are supported (type: %T)", key)
+}
+
+func invalidPEM() error {
+	return fmt.Errorf("file does not contain a PEM block")
+}
+
+func encodeFailed(segment string, err error) error {
+	return fmt.Errorf("encoding token %s failed - %s", segment, err)
+}
+
+func malformedToken(reason string) error {
+	return fmt.Errorf("malformed token (reason: %s)", reason)
+}
+
+func invalidSignature() error {
+	return fmt.Errorf("token signature is invalid")
+}
+
+func untrustedIssuer(issuer string) error {
+	return fmt.Errorf("token issuer is not trusted (issuer: %s)", issuer)
+}
+
+func invalidSubject(issuer, subject string) error {
+	return fmt.Errorf("token subject must be its issuer (issuer: %s, subject: %s)", issuer, subject)
+}
+
+func invalidAudience(issuer string, aud []string) error {
+	return fmt.Errorf("token is not issued for this connector (issuer: %s, audience: %v)", issuer, aud)
+}
+
+func unsupportedAlgorithm(alg string) error {
+	return fmt.Errorf("token signing algorithm is not supported (algorithm: %s)", alg)
+}
+
+func tokenExpired(subject string, exp time.Time) error {
+	return fmt.Errorf("token has expired (subject: %s, expired at: %s)", subject, exp.Format(time.RFC3339))
+}
+
+func tokenNotYetValid(subject string, iat time.Time) error {
+	return fmt.Errorf("token is not valid yet (subject: %s, issued at: %s)", subject, iat.Format(time.RFC3339))
+}
@@ -0,0 +1,210 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/YasiruR/connector/domain/pkg"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	Type       = `jwt`
+	algorithm  = `EdDSA`
+	defaultTTL = 5 * time.Minute
+	clockSkew  = 30 * time.Second
+)
+
+var encoding = base64.RawURLEncoding
+
+// Issuer is an implementation of pkg.IAM which issues self-signed JSON Web Tokens with
+// the participant ID as the issuer and the subject. Each participant signs its tokens
+// with its own Ed25519 key, and received tokens are only accepted from the participants
+// of the trust list, verified with their public keys. The audience of a token is the
+// address of the receiving connector so that it can not be replayed to other participants.
+type Issuer struct {
+	participantId string
+	key           ed25519.PrivateKey
+	trusted       map[string]ed25519.PublicKey // public keys by participant ID
+	audiences     map[string]bool              // addresses of this connector
+	ttl           time.Duration
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+type claims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud"`
+	IssuedAt  int64    `json:"iat"`
+	ExpiresAt int64    `json:"exp"`
+}
+
+// audience is either a single value or an array of values (RFC 7519, section 4.1.3)
+type audience []string
+
+// NewIssuer loads the private key of the participant and the public keys of the trusted
+// participants from PEM files, where trusted maps participant IDs to the files. Audiences
+// are the addresses of the connector which are accepted in received tokens.
+func NewIssuer(participantId, keyFile string, trusted map[string]string, audiences []string,
+	ttl time.Duration, log pkg.Log) *Issuer {
+	if keyFile == `` {
+		log.Fatal(emptyKey())
+	}
+
+	key, err := privateKey(keyFile)
+	if err != nil {
+		log.Fatal(loadKeyFailed(keyFile, err))
+	}
+
+	i := &Issuer{participantId: participantId, key: key, trusted: make(map[string]ed25519.PublicKey),
+		audiences: make(map[string]bool), ttl: ttl}
+	for id, file := range trusted {
+		if i.trusted[id], err = publicKey(file); err != nil {
+			log.Fatal(loadKeyFailed(file, err))
+		}
+	}
+
+	for _, aud := range audiences {
+		i.audiences[origin(aud)] = true
+	}
+
+	if len(i.trusted) == 0 {
+		log.Warn("trust list of the JWT issuer is empty and therefore, tokens of other participants are rejected")
+	}
+
+	if i.ttl <= 0 {
+		i.ttl = defaultTTL
+	}
+
+	log.Info("initialized self-signed JWT issuer", "subject: "+participantId)
+	return i
+}
+
+func (i *Issuer) Token(aud string) (string, error) {
+	now := time.Now()
+	h, err := json.Marshal(header{Alg: algorithm, Typ: `JWT`})
+	if err != nil {
+		return ``, encodeFailed(`header`, err)
+	}
+
+	c, err := json.Marshal(claims{
+		Issuer:    i.participantId,
+		Subject:   i.participantId,
+		Audience:  audience{origin(aud)},
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(i.ttl).Unix(),
+	})
+	if err != nil {
+		return ``, encodeFailed(`claims`, err)
+	}
+
+	unsigned := encoding.EncodeToString(h) + `.` + encoding.EncodeToString(c)
+	return unsigned + `.` + encoding.EncodeToString(ed25519.Sign(i.key, []byte(unsigned))), nil
+}
+
+func (i *Issuer) Verify(token string) (participantId string, err error) {
+	parts := strings.Split(token, `.`)
+	if len(parts) != 3 {
+		return ``, malformedToken(`token must contain a header, claims and a signature`)
+	}
+
+	var h header
+	if err = decode(parts[0], &h); err != nil {
+		return ``, malformedToken(err.Error())
+	}
+
+	if h.Alg != algorithm {
+		return ``, unsupportedAlgorithm(h.Alg)
+	}
+
+	// claims are decoded before the signature is verified since the key is selected
+	// by the issuer, but none of the claims is trusted until then
+	var c claims
+	if err = decode(parts[1], &c); err != nil {
+		return ``, malformedToken(err.Error())
+	}
+
+	key, ok := i.trusted[c.Issuer]
+	if !ok {
+		return ``, untrustedIssuer(c.Issuer)
+	}
+
+	sig, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return ``, malformedToken(`signature is not base64url encoded`)
+	}
+
+	if !ed25519.Verify(key, []byte(parts[0]+`.`+parts[1]), sig) {
+		return ``, invalidSignature()
+	}
+
+	// tokens are self-signed and therefore, a participant can only identify itself
+	if c.Subject != c.Issuer {
+		return ``, invalidSubject(c.Issuer, c.Subject)
+	}
+
+	if !i.intended(c.Audience) {
+		return ``, invalidAudience(c.Issuer, c.Audience)
+	}
+
+	now := time.Now()
+	if now.After(time.Unix(c.ExpiresAt, 0).Add(clockSkew)) {
+		return ``, tokenExpired(c.Subject, time.Unix(c.ExpiresAt, 0))
+	}
+
+	if now.Add(clockSkew).Before(time.Unix(c.IssuedAt, 0)) {
+		return ``, tokenNotYetValid(c.Subject, time.Unix(c.IssuedAt, 0))
+	}
+
+	return c.Subject, nil
+}
+
+// intended returns true if any of the audiences is an address of this connector
+func (i *Issuer) intended(aud audience) bool {
+	for _, a := range aud {
+		if i.audiences[origin(a)] {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	*a = list
+	return nil
+}
+
+// origin returns the scheme and the host of an address (e.g. 'http://localhost:9080'), so
+// that tokens are bound to the receiving connector irrespective of the endpoint
+func origin(addr string) string {
+	u, err := url.Parse(addr)
+	if err != nil || u.Scheme == `` || u.Host == `` {
+		return strings.TrimSuffix(addr, `/`)
+	}
+	return strings.ToLower(u.Scheme + `://` + u.Host)
+}
+
+func decode(segment string, val any) error {
+	data, err := encoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, val)
+}
@@ -0,0 +1,95 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/YasiruR/connector/pkg/log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// keyFiles writes a new Ed25519 key pair of the participant in PEM files and returns
+// the paths of the private and public keys
+func keyFiles(t *testing.T, participantId string) (private, public string) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey failed - %s", err)
+	}
+
+	privDer, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey failed - %s", err)
+	}
+
+	pubDer, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey failed - %s", err)
+	}
+
+	private = filepath.Join(t.TempDir(), participantId+`.pem`)
+	public = filepath.Join(t.TempDir(), participantId+`.pub.pem`)
+	for file, block := range map[string]*pem.Block{private: {Type: `PRIVATE KEY`, Bytes: privDer},
+		public: {Type: `PUBLIC KEY`, Bytes: pubDer}} {
+		if err = os.WriteFile(file, pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatalf("WriteFile failed - %s", err)
+		}
+	}
+	return private, public
+}
+
+func TestIssuer_Verify(t *testing.T) {
+	l := log.NewLogger()
+	provPriv, provPub := keyFiles(t, `provider`)
+	consPriv, consPub := keyFiles(t, `consumer`)
+	otherPriv, _ := keyFiles(t, `other`)
+	provAddr := `http://localhost:9080`
+
+	provider := NewIssuer(`provider`, provPriv, map[string]string{`consumer`: consPub}, []string{provAddr}, 0, l)
+	consumer := NewIssuer(`consumer`, consPriv, map[string]string{`provider`: provPub}, nil, 0, l)
+	// other signs its tokens with its own key while claiming to be the consumer
+	impostor := NewIssuer(`consumer`, otherPriv, nil, nil, 0, l)
+	untrusted := NewIssuer(`other`, otherPriv, nil, nil, 0, l)
+
+	token := func(i *Issuer, aud string) string {
+		tkn, err := i.Token(aud)
+		if err != nil {
+			t.Fatalf("Token failed - %s", err)
+		}
+		return tkn
+	}
+
+	valid := token(consumer, provAddr+`/negotiations/request`)
+	parts := strings.Split(valid, `.`)
+	expired := &Issuer{participantId: `consumer`, key: consumer.key, ttl: -time.Hour}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{`trusted participant`, valid, true},
+		{`untrusted participant`, token(untrusted, provAddr), false},
+		{`key of another participant`, token(impostor, provAddr), false},
+		{`another audience`, token(consumer, `http://localhost:7080`), false},
+		{`expired token`, token(expired, provAddr), false},
+		{`altered claims`, parts[0] + `.` + encoding.EncodeToString([]byte(`{"iss":"consumer","sub":"provider",`+
+			`"aud":"http://localhost:9080","exp":9999999999}`)) + `.` + parts[2], false},
+		{`missing signature`, parts[0] + `.` + parts[1] + `.`, false},
+		{`malformed token`, `token`, false},
+	}
+
+	for _, test := range tests {
+		id, err := provider.Verify(test.token)
+		if test.valid && (err != nil || id != `consumer`) {
+			t.Errorf("%s: Verify returned (%s, %v), want the consumer", test.name, id, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("%s: Verify accepted the token of %s", test.name, id)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+)
+
+// privateKey reads an Ed25519 private key in a PKCS #8 PEM file
+// (e.g. generated with 'openssl genpkey -algorithm ed25519')
+func privateKey(file string) (ed25519.PrivateKey, error) {
+	block, err := pemBlock(file)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, unsupportedKey(key)
+	}
+	return edKey, nil
+}
+
+// publicKey reads an Ed25519 public key in a PKIX PEM file
+// (e.g. generated with 'openssl pkey -pubout')
+func publicKey(file string) (ed25519.PublicKey, error) {
+	block, err := pemBlock(file)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, unsupportedKey(key)
+	}
+	return edKey, nil
+}
+
+func pemBlock(file string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, invalidPEM()
+	}
+	return block, nil
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"github.com/YasiruR/connector/domain/errors"
+	"github.com/YasiruR/connector/domain/pkg"
+	"net/http"
+	"strings"
+)
+
+type ctxKey string
+
+const ctxKeyParticipant ctxKey = `participant`
+
+// Authenticate rejects requests without a valid identity token in the Authorization
+// header and passes the authenticated participant ID to the next handler
+func Authenticate(iam pkg.IAM) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get(`Authorization`), `Bearer `)
+			if !ok || token == `` {
+				WriteError(w, errors.Client(errors.Unauthorized(missingToken())), http.StatusUnauthorized)
+				return
+			}
+
+			participantId, err := iam.Verify(token)
+			if err != nil {
+				WriteError(w, errors.Client(errors.Unauthorized(err)), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKeyParticipant, participantId)))
+		})
+	}
+}
+
+// Participant returns the ID of the participant authenticated for the request
+func Participant(r *http.Request) string {
+	id, _ := r.Context().Value(ctxKeyParticipant).(string)
+	return id
+}
@@ -19,3 +19,11 @@ func writeAckFailed(err error) error {
 func readBodyFailed(err error) error {
 	return fmt.Errorf("reading body failed - %s", err)
 }
+
+/*
+	Authentication errors
+*/
+
+func missingToken() error {
+	return fmt.Errorf("bearer token is not found in the Authorization header")
+}
@@ -0,0 +1,499 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"github.com/YasiruR/connector/domain/pkg"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Engine is an implementation of pkg.PolicyEngine which evaluates ODRL constraints
+// by comparing the operands as numbers, timestamps or strings in that order of
+// precedence
+type Engine struct {
+	log pkg.Log
+}
+
+func NewEngine(log pkg.Log) *Engine {
+	log.Info("initialized ODRL policy engine")
+	return &Engine{log: log}
+}
+
+func (e *Engine) Evaluate(rule odrl.Rule, ctx pkg.PolicyContext) error {
+	for _, c := range rule.Constraints {
+		if err := e.evaluate(c, ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Engine) ValidateOffer(received, published odrl.Offer) error {
+	if received.Assigner != published.Assigner {
+		return offerAltered(`assigner`, string(published.Assigner), string(received.Assigner))
+	}
+
+	// target of the published offer is the dataset which publishes it
+	if received.Target != published.Target {
+		return offerAltered(`target`, string(published.Target), string(received.Target))
+	}
+
+	// each received permission must narrow a published permission of the same action
+	// since any other permission grants more than what was offered
+	for _, rcvPerm := range received.Permissions {
+		var err error = ruleNotOffered(rcvPerm.Action)
+		for _, pubPerm := range published.Permissions {
+			if !sameAction(pubPerm.Action, rcvPerm.Action) {
+				continue
+			}
+
+			if err = e.narrowsRule(rcvPerm, pubPerm); err == nil {
+				break
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// published prohibitions can not be removed or altered but new prohibitions
+	// can be added since they only restrict the offer further
+	for _, pubProh := range published.Prohibitions {
+		if !containsRule(received.Prohibitions, pubProh) {
+			return prohibitionRemoved(pubProh.Action)
+		}
+	}
+
+	// similarly, obligations of the published offer must be retained
+	for _, pubObl := range published.Obligations {
+		if !containsDuty(received.Obligations, pubObl) {
+			return dutyRemoved(pubObl.Action)
+		}
+	}
+
+	return nil
+}
+
+// evaluate returns a PolicyViolation if the constraint is not satisfied by the context
+// and any other error if the constraint can not be evaluated
+func (e *Engine) evaluate(c odrl.Constraint, ctx pkg.PolicyContext) error {
+	switch {
+	case len(c.And) > 0:
+		for _, sub := range c.And {
+			if err := e.evaluate(sub, ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	case len(c.Or) > 0:
+		satisfied, err := e.countSatisfied(c.Or, ctx)
+		if err != nil {
+			return err
+		}
+
+		if satisfied == 0 {
+			return pkg.PolicyViolation{Constraint: c, Reason: `none of the constraints of odrl:or is satisfied`}
+		}
+		return nil
+	case len(c.Xone) > 0:
+		satisfied, err := e.countSatisfied(c.Xone, ctx)
+		if err != nil {
+			return err
+		}
+
+		if satisfied != 1 {
+			return pkg.PolicyViolation{Constraint: c, Reason: fmt.Sprintf(
+				"%d constraints of odrl:xone are satisfied instead of exactly one", satisfied)}
+		}
+		return nil
+	}
+
+	val, ok := lookup(ctx, c.LeftOperand)
+	if !ok {
+		return pkg.PolicyViolation{Constraint: c, Reason: `value of the left operand is not provided`}
+	}
+
+	right := c.RightOperand
+	if c.RightOperandReference != `` {
+		ref, ok := lookup(ctx, c.RightOperandReference)
+		if !ok {
+			return unresolvedReference(c.RightOperandReference)
+		}
+		right = odrl.TypedLiteral(ref, right.Type)
+	}
+
+	satisfied, err := compare(c.Operator, val, right)
+	if err != nil {
+		return err
+	}
+
+	if !satisfied {
+		return pkg.PolicyViolation{Constraint: c, Reason: fmt.Sprintf("received value (%s) does not satisfy the "+
+			"constraint", val)}
+	}
+	return nil
+}
+
+// countSatisfied returns the number of constraints satisfied by the context
+func (e *Engine) countSatisfied(constraints []odrl.Constraint, ctx pkg.PolicyContext) (int, error) {
+	var count int
+	for _, sub := range constraints {
+		err := e.evaluate(sub, ctx)
+		if err == nil {
+			count++
+			continue
+		}
+
+		if !errors.Is(err, pkg.TypePolicyViolation) {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// narrowsRule checks if each constraint of the published rule is preserved or
+// narrowed by a constraint of the received rule, and if each duty of the published
+// rule is retained by the received rule
+func (e *Engine) narrowsRule(received, published odrl.Rule) error {
+	for _, duty := range published.Duties {
+		if !containsDuty(received.Duties, duty) {
+			return dutyRemoved(duty.Action)
+		}
+	}
+
+	for _, pub := range published.Constraints {
+		var err error = pkg.PolicyViolation{Constraint: pub, Reason: `constraint is not included in the offer`}
+		for _, rcv := range received.Constraints {
+			if !sameTerm(rcv.LeftOperand, pub.LeftOperand) {
+				continue
+			}
+
+			if err = narrows(rcv, pub); err == nil {
+				break
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// narrows returns nil if every context which satisfies the received constraint also
+// satisfies the published constraint
+func narrows(rcv, pub odrl.Constraint) error {
+	// narrowing an operand of odrl:and or odrl:or narrows the constraint, but narrowing an
+	// operand of odrl:xone may satisfy it by a context which satisfied more than one operand
+	// (e.g. overlapping ranges), and therefore, operands of odrl:xone must be equivalent
+	if !equivalent(rcv.Xone, pub.Xone) {
+		return pkg.PolicyViolation{Constraint: pub, Reason: `operands of odrl:xone are altered`}
+	}
+
+	for _, lc := range [][2][]odrl.Constraint{{rcv.And, pub.And}, {rcv.Or, pub.Or}} {
+		if len(lc[0]) != len(lc[1]) {
+			return pkg.PolicyViolation{Constraint: pub, Reason: `logical constraint is altered`}
+		}
+
+		for i := range lc[1] {
+			if err := narrows(lc[0][i], lc[1][i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !sameTerm(rcv.LeftOperand, pub.LeftOperand) || operator(rcv.Operator) != operator(pub.Operator) {
+		return pkg.PolicyViolation{Constraint: pub, Reason: `left operand or operator is altered`}
+	}
+
+	if pub.LeftOperand == `` {
+		return nil
+	}
+
+	if !sameTerm(rcv.Unit, pub.Unit) || rcv.RightOperandReference != pub.RightOperandReference {
+		return pkg.PolicyViolation{Constraint: pub, Reason: `unit or right operand reference is altered`}
+	}
+
+	// referred right operands are resolved only when the constraint is evaluated
+	if pub.RightOperandReference != `` {
+		return nil
+	}
+
+	typ := pub.RightOperand.Type
+	if rcv.RightOperand.Type != `` && typ != `` && !sameTerm(rcv.RightOperand.Type, typ) {
+		return pkg.PolicyViolation{Constraint: pub, Reason: `datatype of the right operand is altered`}
+	}
+
+	var narrowed bool
+	switch operator(pub.Operator) {
+	case odrl.OpEq, odrl.OpNeq:
+		narrowed = equal(rcv.RightOperand.String(), pub.RightOperand.String(), typ)
+	case odrl.OpLt, odrl.OpLteq:
+		diff, err := order(rcv.RightOperand.String(), pub.RightOperand.String(), typ)
+		if err != nil {
+			return err
+		}
+		narrowed = diff <= 0
+	case odrl.OpGt, odrl.OpGteq:
+		diff, err := order(rcv.RightOperand.String(), pub.RightOperand.String(), typ)
+		if err != nil {
+			return err
+		}
+		narrowed = diff >= 0
+	case odrl.OpIsAnyOf, odrl.OpIsPartOf:
+		narrowed = subset(values(rcv.RightOperand), values(pub.RightOperand), typ)
+	default:
+		return unsupportedOperator(pub.Operator)
+	}
+
+	if !narrowed {
+		return pkg.PolicyViolation{Constraint: pub, Reason: fmt.Sprintf("received right operand (%s) "+
+			"weakens the constraint", rcv.RightOperand)}
+	}
+	return nil
+}
+
+// compare evaluates the operator on the value of the left operand and the right operand,
+// where the datatype of the right operand determines how the operands are compared
+func compare(op, left string, right odrl.RightOperand) (bool, error) {
+	typ := right.Type
+	switch operator(op) {
+	case odrl.OpEq:
+		return equal(left, right.String(), typ), nil
+	case odrl.OpNeq:
+		return !equal(left, right.String(), typ), nil
+	case odrl.OpLt, odrl.OpLteq, odrl.OpGt, odrl.OpGteq:
+		diff, err := order(left, right.String(), typ)
+		if err != nil {
+			return false, err
+		}
+
+		switch operator(op) {
+		case odrl.OpLt:
+			return diff < 0, nil
+		case odrl.OpLteq:
+			return diff <= 0, nil
+		case odrl.OpGt:
+			return diff > 0, nil
+		default:
+			return diff >= 0, nil
+		}
+	case odrl.OpIsAnyOf:
+		for _, v := range list(left) {
+			if subset([]string{v}, values(right), typ) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case odrl.OpIsPartOf:
+		return subset(list(left), values(right), typ), nil
+	default:
+		return false, unsupportedOperator(op)
+	}
+}
+
+// operator returns the prefixed form of the operator since both 'eq' and 'odrl:eq'
+// are accepted
+func operator(op string) string {
+	return odrl.Prefixed(op)
+}
+
+// sameTerm compares ODRL terms (e.g. left operands) in either prefixed, plain or
+// expanded form
+func sameTerm(left, right string) bool {
+	if left == `` || right == `` {
+		return left == right
+	}
+	return odrl.Prefixed(odrl.Compact(left)) == odrl.Prefixed(odrl.Compact(right))
+}
+
+// sameAction compares actions (e.g. 'use' and 'odrl:use') as ODRL terms
+func sameAction(left, right odrl.Action) bool {
+	return sameTerm(string(left), string(right))
+}
+
+// lookup returns the value of the term from the context in either plain or prefixed form
+func lookup(ctx pkg.PolicyContext, term string) (string, bool) {
+	if val, ok := ctx[term]; ok {
+		return val, true
+	}
+	val, ok := ctx[odrl.Prefixed(term)]
+	return val, ok
+}
+
+func equal(left, right, typ string) bool {
+	if diff, err := order(left, right, typ); err == nil {
+		return diff == 0
+	}
+	return strings.TrimSpace(left) == strings.TrimSpace(right)
+}
+
+// order compares the operands as numbers or timestamps and returns a negative
+// value if left is smaller, zero if both are equal and a positive value otherwise.
+// Operands of a numeric or temporal datatype must be valid values of the datatype,
+// whereas both forms are attempted in that order if the datatype is not provided.
+func order(left, right, typ string) (int, error) {
+	left, right = strings.TrimSpace(left), strings.TrimSpace(right)
+	switch odrl.Compact(typ) {
+	case odrl.DataTypeInteger, odrl.DataTypeDecimal, odrl.DataTypeDouble:
+		if diff, ok := orderNumbers(left, right); ok {
+			return diff, nil
+		}
+		return 0, invalidLiteral(left, right, typ)
+	case odrl.DataTypeDate, odrl.DataTypeDateTime:
+		if diff, ok := orderTimes(left, right); ok {
+			return diff, nil
+		}
+		return 0, invalidLiteral(left, right, typ)
+	case ``:
+		if diff, ok := orderNumbers(left, right); ok {
+			return diff, nil
+		}
+
+		if diff, ok := orderTimes(left, right); ok {
+			return diff, nil
+		}
+	}
+
+	return 0, notComparable(left, right)
+}
+
+func orderNumbers(left, right string) (int, bool) {
+	l, err := strconv.ParseFloat(left, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	r, err := strconv.ParseFloat(right, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	switch {
+	case l < r:
+		return -1, true
+	case l > r:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+func orderTimes(left, right string) (int, bool) {
+	l, ok := parseTime(left)
+	if !ok {
+		return 0, false
+	}
+
+	r, ok := parseTime(right)
+	if !ok {
+		return 0, false
+	}
+
+	return l.Compare(r), true
+}
+
+func parseTime(val string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, val); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// list returns the values of the context separated by commas
+func list(val string) []string {
+	var vals []string
+	for _, v := range strings.Split(val, `,`) {
+		if v = strings.TrimSpace(v); v != `` {
+			vals = append(vals, v)
+		}
+	}
+	return vals
+}
+
+// values returns the lexical forms of the members of the right operand
+func values(right odrl.RightOperand) []string {
+	var vals []string
+	for _, v := range right.Values() {
+		vals = append(vals, v.String())
+	}
+	return vals
+}
+
+// subset returns true if all values of sub are included in set
+func subset(sub, set []string, typ string) bool {
+	for _, s := range sub {
+		var found bool
+		for _, v := range set {
+			if equal(s, v, typ) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// containsRule returns true if the rules include an equivalent rule which retains
+// the remedies of the given rule
+func containsRule(rules []odrl.Rule, rule odrl.Rule) bool {
+	for _, r := range rules {
+		if !sameAction(r.Action, rule.Action) || !equivalent(r.Constraints, rule.Constraints) {
+			continue
+		}
+
+		if containsDuties(r.Remedies, rule.Remedies) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsDuty returns true if the duties include an equivalent duty which retains
+// the consequences of the given duty
+func containsDuty(duties []odrl.Duty, duty odrl.Duty) bool {
+	for _, d := range duties {
+		if !sameAction(d.Action, duty.Action) || !equivalent(d.Constraints, duty.Constraints) {
+			continue
+		}
+
+		if containsDuties(d.Consequences, duty.Consequences) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsDuties(duties, sub []odrl.Duty) bool {
+	for _, d := range sub {
+		if !containsDuty(duties, d) {
+			return false
+		}
+	}
+	return true
+}
+
+// equivalent returns true if each constraint narrows the corresponding constraint
+// in both directions
+func equivalent(left, right []odrl.Constraint) bool {
+	if len(left) != len(right) {
+		return false
+	}
+
+	for i := range left {
+		if narrows(left[i], right[i]) != nil || narrows(right[i], left[i]) != nil {
+			return false
+		}
+	}
+	return true
+}
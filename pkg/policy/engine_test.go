@@ -0,0 +1,189 @@
+package policy
+
+import (
+	"errors"
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/pkg/log"
+	"testing"
+)
+
+func constraint(left, op string, right odrl.RightOperand) odrl.Constraint {
+	return odrl.Constraint{LeftOperand: left, Operator: op, RightOperand: right}
+}
+
+func offer(perms []odrl.Rule) odrl.Offer {
+	return odrl.Offer{Id: `offer`, Target: `dataset`, Assigner: `provider`, Permissions: perms}
+}
+
+func TestEngine_ValidateOffer(t *testing.T) {
+	count10 := constraint(`odrl:count`, odrl.OpLteq, odrl.TypedLiteral(`10`, odrl.DataTypeInteger))
+	region := constraint(`region`, `eq`, odrl.Literal(`eu`))
+	purpose := constraint(`odrl:purpose`, odrl.OpIsAnyOf, odrl.Literal(`research,education`))
+	published := offer([]odrl.Rule{{Action: `odrl:use`, Constraints: []odrl.Constraint{count10, region, purpose},
+		Duties: []odrl.Duty{{Action: `odrl:attribute`}}}})
+	published.Prohibitions = []odrl.Rule{{Action: `odrl:distribute`}}
+	published.Obligations = []odrl.Duty{{Action: `odrl:compensate`}}
+
+	// received returns a copy of the published offer with the given permission
+	received := func(action odrl.Action, constraints []odrl.Constraint, duties []odrl.Duty) odrl.Offer {
+		ofr := published
+		ofr.Assignee = `consumer`
+		ofr.Permissions = []odrl.Rule{{Action: action, Constraints: constraints, Duties: duties}}
+		return ofr
+	}
+	duties := published.Permissions[0].Duties
+
+	tests := []struct {
+		name     string
+		received odrl.Offer
+		valid    bool
+	}{
+		{`published offer`, received(`odrl:use`, []odrl.Constraint{count10, region, purpose}, duties), true},
+		{`plain action`, received(`use`, []odrl.Constraint{count10, region, purpose}, duties), true},
+		{`expanded action`, received(`http://www.w3.org/ns/odrl/2/use`,
+			[]odrl.Constraint{count10, region, purpose}, duties), true},
+		{`narrowed count`, received(`use`, []odrl.Constraint{constraint(`count`, `lteq`,
+			odrl.TypedLiteral(`5`, odrl.DataTypeInteger)), region, purpose}, duties), true},
+		{`weakened count`, received(`use`, []odrl.Constraint{constraint(`count`, `lteq`,
+			odrl.TypedLiteral(`20`, odrl.DataTypeInteger)), region, purpose}, duties), false},
+		{`altered operator`, received(`use`, []odrl.Constraint{constraint(`count`, `gteq`,
+			odrl.TypedLiteral(`10`, odrl.DataTypeInteger)), region, purpose}, duties), false},
+		{`altered equality`, received(`use`, []odrl.Constraint{count10, constraint(`region`, `eq`,
+			odrl.Literal(`us`)), purpose}, duties), false},
+		{`narrowed set`, received(`use`, []odrl.Constraint{count10, region, constraint(`purpose`,
+			`isAnyOf`, odrl.Literal(`research`))}, duties), true},
+		{`extended set`, received(`use`, []odrl.Constraint{count10, region, constraint(`purpose`,
+			`isAnyOf`, odrl.Literal(`research,marketing`))}, duties), false},
+		{`removed constraint`, received(`use`, []odrl.Constraint{count10, region}, duties), false},
+		{`removed duty`, received(`use`, []odrl.Constraint{count10, region, purpose}, nil), false},
+		{`action not offered`, received(`odrl:modify`, []odrl.Constraint{count10, region, purpose},
+			duties), false},
+	}
+
+	e := NewEngine(log.NewLogger())
+	for _, test := range tests {
+		if err := e.ValidateOffer(test.received, published); (err == nil) != test.valid {
+			t.Errorf("%s: ValidateOffer returned %v, want valid: %t", test.name, err, test.valid)
+		}
+	}
+
+	withoutProhibition := received(`use`, []odrl.Constraint{count10, region, purpose}, duties)
+	withoutProhibition.Prohibitions = nil
+	if err := e.ValidateOffer(withoutProhibition, published); err == nil {
+		t.Errorf("ValidateOffer accepted an offer without the published prohibition")
+	}
+
+	plainProhibition := received(`use`, []odrl.Constraint{count10, region, purpose}, duties)
+	plainProhibition.Prohibitions = []odrl.Rule{{Action: `distribute`}}
+	if err := e.ValidateOffer(plainProhibition, published); err != nil {
+		t.Errorf("ValidateOffer rejected an offer with the published prohibition in plain form - %s", err)
+	}
+
+	withoutObligation := received(`use`, []odrl.Constraint{count10, region, purpose}, duties)
+	withoutObligation.Obligations = nil
+	if err := e.ValidateOffer(withoutObligation, published); err == nil {
+		t.Errorf("ValidateOffer accepted an offer without the published obligation")
+	}
+
+	alteredAssigner := received(`use`, []odrl.Constraint{count10, region, purpose}, duties)
+	alteredAssigner.Assigner = `other-provider`
+	if err := e.ValidateOffer(alteredAssigner, published); err == nil {
+		t.Errorf("ValidateOffer accepted an offer with an altered assigner")
+	}
+
+	for _, target := range []odrl.Target{`other-dataset`, ``} {
+		alteredTarget := received(`use`, []odrl.Constraint{count10, region, purpose}, duties)
+		alteredTarget.Target = target
+		if err := e.ValidateOffer(alteredTarget, published); err == nil {
+			t.Errorf("ValidateOffer accepted an offer with the target '%s'", target)
+		}
+	}
+}
+
+func TestEngine_ValidateOffer_LogicalConstraints(t *testing.T) {
+	count := func(val string) odrl.Constraint {
+		return constraint(`count`, `lteq`, odrl.TypedLiteral(val, odrl.DataTypeInteger))
+	}
+	region := func(val string) odrl.Constraint {
+		return constraint(`region`, `eq`, odrl.Literal(val))
+	}
+	published := offer([]odrl.Rule{{Action: `use`, Constraints: []odrl.Constraint{
+		{Or: []odrl.Constraint{count(`10`), region(`eu`)}}}}})
+
+	tests := []struct {
+		name    string
+		logical odrl.Constraint
+		valid   bool
+	}{
+		{`published`, odrl.Constraint{Or: []odrl.Constraint{count(`10`), region(`eu`)}}, true},
+		{`narrowed operand`, odrl.Constraint{Or: []odrl.Constraint{count(`5`), region(`eu`)}}, true},
+		{`weakened operand`, odrl.Constraint{Or: []odrl.Constraint{count(`20`), region(`eu`)}}, false},
+		{`removed operand`, odrl.Constraint{Or: []odrl.Constraint{count(`10`)}}, false},
+		{`altered operator`, odrl.Constraint{And: []odrl.Constraint{count(`10`), region(`eu`)}}, false},
+	}
+
+	e := NewEngine(log.NewLogger())
+	for _, test := range tests {
+		rcv := offer([]odrl.Rule{{Action: `use`, Constraints: []odrl.Constraint{test.logical}}})
+		if err := e.ValidateOffer(rcv, published); (err == nil) != test.valid {
+			t.Errorf("%s: ValidateOffer returned %v, want valid: %t", test.name, err, test.valid)
+		}
+	}
+
+	// narrowing an operand of overlapping ranges permits the values within the overlap
+	// (e.g. count of 9 with the received offer)
+	atLeast8 := constraint(`count`, `gteq`, odrl.TypedLiteral(`8`, odrl.DataTypeInteger))
+	publishedXone := offer([]odrl.Rule{{Action: `use`, Constraints: []odrl.Constraint{
+		{Xone: []odrl.Constraint{count(`10`), atLeast8}}}}})
+	for _, test := range []struct {
+		name  string
+		xone  odrl.Constraint
+		valid bool
+	}{
+		{`published xone`, odrl.Constraint{Xone: []odrl.Constraint{count(`10`), atLeast8}}, true},
+		{`narrowed xone operand`, odrl.Constraint{Xone: []odrl.Constraint{count(`5`), atLeast8}}, false},
+	} {
+		rcv := offer([]odrl.Rule{{Action: `use`, Constraints: []odrl.Constraint{test.xone}}})
+		if err := e.ValidateOffer(rcv, publishedXone); (err == nil) != test.valid {
+			t.Errorf("%s: ValidateOffer returned %v, want valid: %t", test.name, err, test.valid)
+		}
+	}
+}
+
+func TestEngine_Evaluate_LogicalConstraints(t *testing.T) {
+	count := constraint(`count`, `lteq`, odrl.TypedLiteral(`10`, odrl.DataTypeInteger))
+	region := constraint(`region`, `eq`, odrl.Literal(`eu`))
+	ctx := func(count, region string) pkg.PolicyContext {
+		return pkg.PolicyContext{`odrl:count`: count, `region`: region}
+	}
+
+	tests := []struct {
+		name      string
+		logical   odrl.Constraint
+		ctx       pkg.PolicyContext
+		satisfied bool
+	}{
+		{`and with both`, odrl.Constraint{And: []odrl.Constraint{count, region}}, ctx(`5`, `eu`), true},
+		{`and with one`, odrl.Constraint{And: []odrl.Constraint{count, region}}, ctx(`5`, `us`), false},
+		{`or with one`, odrl.Constraint{Or: []odrl.Constraint{count, region}}, ctx(`20`, `eu`), true},
+		{`or with none`, odrl.Constraint{Or: []odrl.Constraint{count, region}}, ctx(`20`, `us`), false},
+		{`xone with one`, odrl.Constraint{Xone: []odrl.Constraint{count, region}}, ctx(`5`, `us`), true},
+		{`xone with both`, odrl.Constraint{Xone: []odrl.Constraint{count, region}}, ctx(`5`, `eu`), false},
+		{`xone with none`, odrl.Constraint{Xone: []odrl.Constraint{count, region}}, ctx(`20`, `us`), false},
+		{`nested`, odrl.Constraint{And: []odrl.Constraint{count, {Or: []odrl.Constraint{region,
+			constraint(`region`, `eq`, odrl.Literal(`uk`))}}}}, ctx(`5`, `uk`), true},
+	}
+
+	e := NewEngine(log.NewLogger())
+	for _, test := range tests {
+		err := e.Evaluate(odrl.Rule{Action: `use`, Constraints: []odrl.Constraint{test.logical}}, test.ctx)
+		if test.satisfied && err != nil {
+			t.Errorf("%s: Evaluate returned %s, want satisfied", test.name, err)
+		}
+
+		if !test.satisfied && !errors.Is(err, pkg.TypePolicyViolation) {
+			t.Errorf("%s: Evaluate returned %v, want a policy violation", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package policy
+
+import (
+	"fmt"
+	"github.com/YasiruR/connector/domain/models/odrl"
+)
+
+func offerAltered(attr, published, received string) error {
+	return fmt.Errorf("offer is altered (attribute: %s, published: %s, received: %s)", attr, published, received)
+}
+
+func ruleNotOffered(action odrl.Action) error {
+	return fmt.Errorf("permission is not included in the published offer (action: %s)", action)
+}
+
+func prohibitionRemoved(action odrl.Action) error {
+	return fmt.Errorf("prohibition of the published offer is removed or altered (action: %s)", action)
+}
+
+func dutyRemoved(action odrl.Action) error {
+	return fmt.Errorf("duty of the published offer is removed or altered (action: %s)", action)
+}
+
+func unsupportedOperator(op string) error {
+	return fmt.Errorf("constraint operator is not supported (operator: %s)", op)
+}
+
+func notComparable(left, right string) error {
+	return fmt.Errorf("operands are neither numbers nor timestamps (left: %s, right: %s)", left, right)
+}
+
+func invalidLiteral(left, right, typ string) error {
+	return fmt.Errorf("operands are not valid values of the datatype (left: %s, right: %s, datatype: %s)",
+		left, right, typ)
+}
+
+func unresolvedReference(ref string) error {
+	return fmt.Errorf("right operand reference can not be resolved (reference: %s)", ref)
+}
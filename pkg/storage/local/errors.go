@@ -0,0 +1,28 @@
+package local
+
+import "fmt"
+
+func createDirFailed(dir string, err error) error {
+	return fmt.Errorf("creating storage directory failed (directory: %s) - %s", dir, err)
+}
+
+func writeFailed(key string, err error) error {
+	return fmt.Errorf("writing content failed (key: %s) - %s", key, err)
+}
+
+func openFailed(key string, err error) error {
+	return fmt.Errorf("opening content failed (key: %s) - %s", key, err)
+}
+
+func deleteFailed(key string, err error) error {
+	return fmt.Errorf("deleting content failed (key: %s) - %s", key, err)
+}
+
+func offsetExceeded(key string, offset, size int64) error {
+	return fmt.Errorf("offset exceeds the size of the partial content (key: %s, offset: %d, size: %d)",
+		key, offset, size)
+}
+
+func commitFailed(key string, err error) error {
+	return fmt.Errorf("committing partial content failed (key: %s) - %s", key, err)
+}
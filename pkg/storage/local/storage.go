@@ -0,0 +1,149 @@
+package local
+
+import (
+	"github.com/YasiruR/connector/domain/pkg"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	Type     = `local`
+	dirPerm  = 0o750
+	filePerm = 0o640
+	tmpExt   = `.part`
+)
+
+// Storage is an implementation of pkg.Storage which stores each content as a file
+// in the configured directory. Content is written to a temporary file first so
+// that a file only exists once its content is completely written. Partial content
+// is kept in a separate file with the same name until it is committed.
+type Storage struct {
+	dir string
+}
+
+func NewStorage(dir string, log pkg.Log) *Storage {
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		log.Fatal(createDirFailed(dir, err))
+	}
+
+	log.Info("initialized local file storage", "directory: "+dir)
+	return &Storage{dir: dir}
+}
+
+func (s *Storage) Write(key string, content io.Reader) (n int64, err error) {
+	path := s.path(key)
+	tmp, err := os.CreateTemp(s.dir, filepath.Base(path)+`-*`+tmpExt)
+	if err != nil {
+		return 0, writeFailed(key, err)
+	}
+
+	// temporary file is removed unless it is renamed to the final path
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if n, err = io.Copy(tmp, content); err != nil {
+		return n, writeFailed(key, err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return n, writeFailed(key, err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return n, writeFailed(key, err)
+	}
+
+	return n, nil
+}
+
+func (s *Storage) WriteAt(key string, offset int64, content io.Reader) (n int64, err error) {
+	f, err := os.OpenFile(s.path(key)+tmpExt, os.O_CREATE|os.O_WRONLY, filePerm)
+	if err != nil {
+		return 0, writeFailed(key, err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, writeFailed(key, err)
+	}
+
+	if offset > fi.Size() {
+		return 0, offsetExceeded(key, offset, fi.Size())
+	}
+
+	// partially written chunk is discarded so that the content always ends at the
+	// last offset written successfully
+	defer func() {
+		if err != nil {
+			_ = f.Truncate(offset)
+		}
+	}()
+
+	if err = f.Truncate(offset); err != nil {
+		return 0, writeFailed(key, err)
+	}
+
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return 0, writeFailed(key, err)
+	}
+
+	if n, err = io.Copy(f, content); err != nil {
+		return n, writeFailed(key, err)
+	}
+
+	if err = f.Sync(); err != nil {
+		return n, writeFailed(key, err)
+	}
+	return n, nil
+}
+
+func (s *Storage) Commit(key string) error {
+	path := s.path(key)
+	if err := os.Rename(path+tmpExt, path); err != nil {
+		return commitFailed(key, err)
+	}
+	return nil
+}
+
+func (s *Storage) Open(key string) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		return nil, openFailed(key, err)
+	}
+	return f, nil
+}
+
+func (s *Storage) Delete(key string) error {
+	path := s.path(key)
+	for _, p := range []string{path, path + tmpExt} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return deleteFailed(key, err)
+		}
+	}
+	return nil
+}
+
+// path returns the file path of the key where path separators and characters which
+// are not allowed in file names (e.g. colons of URNs on some platforms) are replaced
+func (s *Storage) path(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, key)
+
+	// names referring to directories would escape the storage directory
+	if name == `` || name == `.` || name == `..` {
+		name = `_` + name
+	}
+	return filepath.Join(s.dir, name)
+}
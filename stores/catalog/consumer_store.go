@@ -3,25 +3,61 @@ package catalog
 import (
 	"github.com/YasiruR/connector/domain"
 	"github.com/YasiruR/connector/domain/api/dsp/http/catalog"
-	"github.com/YasiruR/connector/domain/errors"
 	"github.com/YasiruR/connector/domain/models/odrl"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
 )
 
-const collConsumerCatalog = `consumer-catalog`
+const (
+	collConsumerCatalog   = `consumer-catalog`
+	collCatalogOffer      = `consumer-catalog-offer`
+	collProviderEndpoints = `consumer-catalog-provider-endpoint`
+)
 
 type ConsumerCatalog struct {
-	urn  pkg.URNService
-	coll pkg.Collection
+	db        pkg.Database
+	urn       pkg.URNService
+	coll      pkg.Collection
+	offers    pkg.Collection // index of offers in the stored catalog of each provider by offer ID
+	endpoints pkg.Collection // participant IDs of the providers by their endpoints
 }
 
 func NewConsumerCatalog(plugins domain.Plugins) *ConsumerCatalog {
-	return &ConsumerCatalog{urn: plugins.URNService, coll: plugins.Database.NewCollection()}
+	return &ConsumerCatalog{
+		db:        plugins.Database,
+		urn:       plugins.URNService,
+		coll:      plugins.Database.NewCollection(collConsumerCatalog, catalog.Response{}),
+		offers:    plugins.Database.NewCollection(collCatalogOffer, map[string]odrl.Offer{}),
+		endpoints: plugins.Database.NewCollection(collProviderEndpoints, ``),
+	}
 }
 
-func (c *ConsumerCatalog) AddCatalog(res catalog.Response) {
-	_ = c.coll.Set(res.DspaceParticipantID, res)
+// AddCatalog stores the catalog received from the endpoint and replaces the offers indexed
+// for any catalog previously received from the same provider. Offers are indexed per
+// provider since offer IDs are only unique within the catalog of a provider.
+func (c *ConsumerCatalog) AddCatalog(endpoint string, res catalog.Response) error {
+	return c.db.Transaction(func(tx pkg.Transaction) error {
+		if err := tx.Collection(collConsumerCatalog).Set(res.DspaceParticipantID, res); err != nil {
+			return stores.QueryFailed(collConsumerCatalog, `Set`, err)
+		}
+
+		offers := make(map[string]odrl.Offer)
+		for _, ds := range res.Datasets() {
+			for _, ofr := range ds.OdrlHasPolicy {
+				ofr.Target = odrl.Target(ds.ID)
+				offers[ofr.Id] = ofr
+			}
+		}
+
+		if err := tx.Collection(collCatalogOffer).Set(res.DspaceParticipantID, offers); err != nil {
+			return stores.QueryFailed(collCatalogOffer, `Set`, err)
+		}
+
+		if err := tx.Collection(collProviderEndpoints).Set(endpoint, res.DspaceParticipantID); err != nil {
+			return stores.QueryFailed(collProviderEndpoints, `Set`, err)
+		}
+		return nil
+	})
 }
 
 func (c *ConsumerCatalog) Catalog(providerId string) (catalog.Response, error) {
@@ -37,27 +73,35 @@ func (c *ConsumerCatalog) Catalog(providerId string) (catalog.Response, error) {
 	return val.(catalog.Response), nil
 }
 
-func (c *ConsumerCatalog) Offer(offerId string) (ofr odrl.Offer, err error) {
-	// should optimize this function
-	// - expensive AllCatalogs function
-	// - nested loops
-	cats, err := c.AllCatalogs()
+func (c *ConsumerCatalog) Offer(providerId, offerId string) (ofr odrl.Offer, err error) {
+	val, err := c.offers.Get(providerId)
 	if err != nil {
-		return odrl.Offer{}, errors.StoreFailed(collConsumerCatalog, `AllCatalogs`, err)
+		return odrl.Offer{}, stores.QueryFailed(collCatalogOffer, `Get`, err)
 	}
 
-	for _, cat := range cats {
-		for _, ds := range cat.DcatDataset {
-			for _, ofr = range ds.OdrlHasPolicy {
-				if offerId == ofr.Id {
-					ofr.Target = odrl.Target(ds.ID)
-					return ofr, nil
-				}
-			}
-		}
+	if val == nil {
+		return odrl.Offer{}, stores.InvalidKey(providerId)
+	}
+
+	ofr, ok := val.(map[string]odrl.Offer)[offerId]
+	if !ok {
+		return odrl.Offer{}, stores.InvalidKey(offerId)
+	}
+
+	return ofr, nil
+}
+
+func (c *ConsumerCatalog) Provider(endpoint string) (participantId string, err error) {
+	val, err := c.endpoints.Get(endpoint)
+	if err != nil {
+		return ``, stores.QueryFailed(collProviderEndpoints, `Get`, err)
+	}
+
+	if val == nil {
+		return ``, stores.InvalidKey(endpoint)
 	}
 
-	return odrl.Offer{}, stores.InvalidKey(offerId)
+	return val.(string), nil
 }
 
 func (c *ConsumerCatalog) AllCatalogs() ([]catalog.Response, error) {
@@ -66,7 +110,7 @@ func (c *ConsumerCatalog) AllCatalogs() ([]catalog.Response, error) {
 		return nil, stores.QueryFailed(collConsumerCatalog, `GetAll`, err)
 	}
 
-	res := make([]catalog.Response, len(vals))
+	res := make([]catalog.Response, 0, len(vals))
 	for _, val := range vals {
 		res = append(res, val.(catalog.Response))
 	}
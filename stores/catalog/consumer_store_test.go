@@ -0,0 +1,62 @@
+package catalog
+
+import (
+	defaultErr "errors"
+	"github.com/YasiruR/connector/domain/api/dsp/http/catalog"
+	"github.com/YasiruR/connector/domain/models/dcat"
+	"github.com/YasiruR/connector/domain/models/odrl"
+	"github.com/YasiruR/connector/domain/stores"
+	"testing"
+)
+
+func TestConsumerCatalog_Offer(t *testing.T) {
+	response := func(provider string, datasets ...string) catalog.Response {
+		res := catalog.Response{DspaceParticipantID: provider}
+		for _, id := range datasets {
+			res.DcatDataset = append(res.DcatDataset, dcat.Dataset{ID: id,
+				OdrlHasPolicy: []odrl.Offer{{Id: `offer`, Assigner: odrl.Assigner(provider)}}})
+		}
+		return res
+	}
+
+	for typ, plugins := range databases(t) {
+		c := NewConsumerCatalog(plugins)
+		if err := c.AddCatalog(`http://provider1`, response(`provider1`, `dataset1`)); err != nil {
+			t.Fatalf("%s: AddCatalog failed - %s", typ, err)
+		}
+
+		// same offer ID published by another provider must not replace the former
+		if err := c.AddCatalog(`http://provider2`, response(`provider2`, `dataset2`)); err != nil {
+			t.Fatalf("%s: AddCatalog failed - %s", typ, err)
+		}
+
+		for endpoint, ds := range map[string]string{`http://provider1`: `dataset1`, `http://provider2`: `dataset2`} {
+			provider, err := c.Provider(endpoint)
+			if err != nil {
+				t.Fatalf("%s: Provider failed - %s", typ, err)
+			}
+
+			ofr, err := c.Offer(provider, `offer`)
+			if err != nil {
+				t.Fatalf("%s: Offer failed - %s", typ, err)
+			}
+
+			if string(ofr.Assigner) != provider || string(ofr.Target) != ds {
+				t.Errorf("%s: incorrect offer of %s (assigner: %s, target: %s)", typ, provider, ofr.Assigner, ofr.Target)
+			}
+		}
+
+		// offers of a previously received catalog are replaced
+		if err := c.AddCatalog(`http://provider1`, response(`provider1`)); err != nil {
+			t.Fatalf("%s: AddCatalog failed - %s", typ, err)
+		}
+
+		if _, err := c.Offer(`provider1`, `offer`); !defaultErr.Is(err, stores.TypeInvalidKey) {
+			t.Errorf("%s: Offer of a replaced catalog returned %v", typ, err)
+		}
+
+		if _, err := c.Offer(`provider2`, `offer`); err != nil {
+			t.Errorf("%s: Offer of another provider failed - %s", typ, err)
+		}
+	}
+}
@@ -5,40 +5,83 @@ import (
 	"github.com/YasiruR/connector/domain"
 	"github.com/YasiruR/connector/domain/boot"
 	"github.com/YasiruR/connector/domain/core"
+	"github.com/YasiruR/connector/domain/core/dataplane"
 	"github.com/YasiruR/connector/domain/errors"
 	"github.com/YasiruR/connector/domain/models/dcat"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
+	"sort"
 )
 
-const collProviderCatalog = `provider-catalog`
+const (
+	collProviderCatalog    = `provider-catalog`
+	collCatalogEntry       = `catalog-entry`
+	collDatasetCatalog     = `dataset-catalog`
+	collDistributionSource = `distribution-source`
+)
 
-// ProviderCatalog stores Datasets and Data Services which can be shared through a connector
+// ProviderCatalog stores Datasets and Data Services which can be shared through a connector.
+// Metadata of each catalog is stored along with its parent, and the catalog of each dataset
+// is stored separately, so that catalogs are nested when the catalog is constructed.
 type ProviderCatalog struct {
-	meta dcat.CatalogMetadata
-	urn  pkg.URNService
-	coll pkg.Collection
+	rootId   string
+	db       pkg.Database
+	urn      pkg.URNService
+	coll     pkg.Collection
+	catalogs pkg.Collection
+	members  pkg.Collection
+	sources  pkg.Collection
+}
+
+// catalogEntry is a catalog nested within the parent, where the root catalog of the
+// connector does not have a parent
+type catalogEntry struct {
+	Metadata dcat.CatalogMetadata
+	ParentId string
+}
+
+type membership struct {
+	DatasetId string
+	CatalogId string
 }
 
 func NewProviderCatalog(cfg boot.Config, plugins domain.Plugins) *ProviderCatalog {
 	c := &ProviderCatalog{
-		urn:  plugins.URNService,
-		coll: plugins.Database.NewCollection(),
+		urn:      plugins.URNService,
+		db:       plugins.Database,
+		coll:     plugins.Database.NewCollection(collProviderCatalog, dcat.Dataset{}),
+		catalogs: plugins.Database.NewCollection(collCatalogEntry, catalogEntry{}),
+		members:  plugins.Database.NewCollection(collDatasetCatalog, membership{}),
+		sources:  plugins.Database.NewCollection(collDistributionSource, dataplane.Source{}),
 	}
 
 	if err := c.init(cfg); err != nil {
 		plugins.Log.Fatal(fmt.Sprintf("init catalog store failed: %v", err))
 	}
 
-	plugins.Log.Info(fmt.Sprintf("initialized %s store", collProviderCatalog), `catalog ID: `+c.meta.ID)
+	plugins.Log.Info(fmt.Sprintf("initialized %s store", collProviderCatalog), `catalog ID: `+c.rootId)
 	return c
 }
 
-// may not need to init if only a consumer
+// init stores the root catalog as defined in the configuration, where the ID of a
+// previously stored root catalog is retained so that nested catalogs remain valid
+// (may not need to init if only a consumer)
 func (p *ProviderCatalog) init(cfg boot.Config) error {
-	catId, err := p.urn.NewURN()
+	entries, err := entries(p.catalogs)
 	if err != nil {
-		return errors.PkgError(pkg.TypeURN, `NewURN`, err, `catalog id`)
+		return err
+	}
+
+	for id, e := range entries {
+		if e.ParentId == `` {
+			p.rootId = id
+		}
+	}
+
+	if p.rootId == `` {
+		if p.rootId, err = p.urn.NewURN(); err != nil {
+			return errors.PkgError(pkg.TypeURN, `NewURN`, err, `catalog id`)
+		}
 	}
 
 	var kws []dcat.Keyword
@@ -66,8 +109,8 @@ func (p *ProviderCatalog) init(cfg boot.Config) error {
 		})
 	}
 
-	p.meta = dcat.CatalogMetadata{
-		ID:             catId,
+	meta := dcat.CatalogMetadata{
+		ID:             p.rootId,
 		Type:           dcat.TypeCatalog,
 		DctTitle:       cfg.Catalog.Title,
 		DctDescription: descs,
@@ -75,27 +118,185 @@ func (p *ProviderCatalog) init(cfg boot.Config) error {
 		DcatService:    svcs,
 	}
 
+	if err = p.catalogs.Set(p.rootId, catalogEntry{Metadata: meta}); err != nil {
+		return stores.QueryFailed(collCatalogEntry, `Set`, err)
+	}
 	return nil
 }
 
-func (p *ProviderCatalog) Catalog() (dcat.Catalog, error) {
+func (p *ProviderCatalog) RootID() string {
+	return p.rootId
+}
+
+func (p *ProviderCatalog) Catalog(id string) (dcat.Catalog, error) {
+	if id == `` {
+		id = p.rootId
+	}
+
+	entries, err := entries(p.catalogs)
+	if err != nil {
+		return dcat.Catalog{}, err
+	}
+
+	if _, ok := entries[id]; !ok {
+		return dcat.Catalog{}, stores.InvalidKey(id)
+	}
+
+	children := make(map[string][]string)
+	for childId, e := range entries {
+		if e.ParentId != `` {
+			children[e.ParentId] = append(children[e.ParentId], childId)
+		}
+	}
+
 	vals, err := p.coll.GetAll()
 	if err != nil {
 		return dcat.Catalog{}, stores.QueryFailed(collProviderCatalog, `GetAll`, err)
 	}
 
-	var cat dcat.Catalog
-	cat.CatalogMetadata = p.meta
+	catalogIds, err := memberships(p.members)
+	if err != nil {
+		return dcat.Catalog{}, err
+	}
 
+	// datasets without a valid catalog (e.g. created before catalogs were nested) belong
+	// to the root catalog
+	datasets := make(map[string][]dcat.Dataset)
 	for _, val := range vals {
-		cat.DcatDataset = append(cat.DcatDataset, val.(dcat.Dataset))
+		ds := val.(dcat.Dataset)
+		catId, ok := catalogIds[ds.ID]
+		if _, exists := entries[catId]; !ok || !exists {
+			catId = p.rootId
+		}
+		datasets[catId] = append(datasets[catId], ds)
 	}
 
-	return cat, nil
+	return build(id, entries, children, datasets), nil
 }
 
-func (p *ProviderCatalog) AddDataset(id string, val dcat.Dataset) {
-	_ = p.coll.Set(id, val)
+func build(id string, entries map[string]catalogEntry, children map[string][]string,
+	datasets map[string][]dcat.Dataset) dcat.Catalog {
+	cat := dcat.Catalog{CatalogMetadata: entries[id].Metadata, DcatDataset: datasets[id]}
+	nested := children[id]
+	sort.Strings(nested)
+	for _, childId := range nested {
+		cat.DcatCatalog = append(cat.DcatCatalog, build(childId, entries, children, datasets))
+	}
+	return cat
+}
+
+// AddCatalog stores the catalog within the parent catalog and rejects the catalog if
+// its ID is already in use
+func (p *ProviderCatalog) AddCatalog(parentId string, meta dcat.CatalogMetadata) error {
+	if parentId == `` {
+		parentId = p.rootId
+	}
+
+	return p.db.Transaction(func(tx pkg.Transaction) error {
+		catalogs := tx.Collection(collCatalogEntry)
+		if _, err := entry(catalogs, parentId); err != nil {
+			return err
+		}
+
+		swapped, err := catalogs.CompareAndSwap(meta.ID, nil, catalogEntry{Metadata: meta, ParentId: parentId})
+		if err != nil {
+			return stores.QueryFailed(collCatalogEntry, `CompareAndSwap`, err)
+		}
+
+		if !swapped {
+			return stores.KeyExists(meta.ID)
+		}
+		return nil
+	})
+}
+
+// UpdateCatalog replaces the metadata of an existing catalog, where the existence is
+// checked within the same transaction so that a concurrently deleted catalog is not
+// stored again
+func (p *ProviderCatalog) UpdateCatalog(meta dcat.CatalogMetadata) error {
+	return p.db.Transaction(func(tx pkg.Transaction) error {
+		catalogs := tx.Collection(collCatalogEntry)
+		e, err := entry(catalogs, meta.ID)
+		if err != nil {
+			return err
+		}
+
+		e.Metadata = meta
+		if err = catalogs.Set(meta.ID, e); err != nil {
+			return stores.QueryFailed(collCatalogEntry, `Set`, err)
+		}
+		return nil
+	})
+}
+
+func (p *ProviderCatalog) DeleteCatalog(id string) error {
+	if id == p.rootId {
+		return stores.InUse(id, `root catalog of the connector`)
+	}
+
+	return p.db.Transaction(func(tx pkg.Transaction) error {
+		catalogs := tx.Collection(collCatalogEntry)
+		if _, err := entry(catalogs, id); err != nil {
+			return err
+		}
+
+		entries, err := entries(catalogs)
+		if err != nil {
+			return err
+		}
+
+		for childId, e := range entries {
+			if e.ParentId == id {
+				return stores.InUse(id, `parent of catalog `+childId)
+			}
+		}
+
+		catalogIds, err := memberships(tx.Collection(collDatasetCatalog))
+		if err != nil {
+			return err
+		}
+
+		for dsId, catId := range catalogIds {
+			if catId == id {
+				return stores.InUse(id, `catalog of dataset `+dsId)
+			}
+		}
+
+		if err = catalogs.Delete(id); err != nil {
+			return stores.QueryFailed(collCatalogEntry, `Delete`, err)
+		}
+		return nil
+	})
+}
+
+func (p *ProviderCatalog) AddDataset(catalogId, id string, val dcat.Dataset,
+	sources map[string]dataplane.Source) error {
+	if catalogId == `` {
+		catalogId = p.rootId
+	}
+
+	return p.db.Transaction(func(tx pkg.Transaction) error {
+		if _, err := entry(tx.Collection(collCatalogEntry), catalogId); err != nil {
+			return err
+		}
+
+		members := tx.Collection(collDatasetCatalog)
+		if err := members.Set(id, membership{DatasetId: id, CatalogId: catalogId}); err != nil {
+			return stores.QueryFailed(collDatasetCatalog, `Set`, err)
+		}
+
+		if err := tx.Collection(collProviderCatalog).Set(id, val); err != nil {
+			return stores.QueryFailed(collProviderCatalog, `Set`, err)
+		}
+
+		coll := tx.Collection(collDistributionSource)
+		for distId, src := range sources {
+			if err := coll.Set(distId, src); err != nil {
+				return stores.QueryFailed(collDistributionSource, `Set`, err)
+			}
+		}
+		return nil
+	})
 }
 
 func (p *ProviderCatalog) Dataset(id string) (dcat.Dataset, error) {
@@ -110,3 +311,157 @@ func (p *ProviderCatalog) Dataset(id string) (dcat.Dataset, error) {
 
 	return val.(dcat.Dataset), nil
 }
+
+func (p *ProviderCatalog) Datasets() ([]dcat.Dataset, error) {
+	vals, err := p.coll.GetAll()
+	if err != nil {
+		return nil, stores.QueryFailed(collProviderCatalog, `GetAll`, err)
+	}
+
+	datasets := make([]dcat.Dataset, 0, len(vals))
+	for _, val := range vals {
+		datasets = append(datasets, val.(dcat.Dataset))
+	}
+	return datasets, nil
+}
+
+// UpdateDataset replaces an existing dataset, where the existence is checked within
+// the same transaction so that a concurrently deleted dataset is not stored again
+func (p *ProviderCatalog) UpdateDataset(id string, val dcat.Dataset) error {
+	return p.db.Transaction(func(tx pkg.Transaction) error {
+		datasets := tx.Collection(collProviderCatalog)
+		cur, err := datasets.Get(id)
+		if err != nil {
+			return stores.QueryFailed(collProviderCatalog, `Get`, err)
+		}
+
+		if cur == nil {
+			return stores.InvalidKey(id)
+		}
+
+		if err = datasets.Set(id, val); err != nil {
+			return stores.QueryFailed(collProviderCatalog, `Set`, err)
+		}
+		return nil
+	})
+}
+
+func (p *ProviderCatalog) DatasetsByOffer(offerId string) ([]string, error) {
+	var dsIds []string
+	_, err := p.coll.Query(func(_ string, val any) bool {
+		ds := val.(dcat.Dataset)
+		for _, ofr := range ds.OdrlHasPolicy {
+			if ofr.Id == offerId {
+				dsIds = append(dsIds, ds.ID)
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, stores.QueryFailed(collProviderCatalog, `Query`, err)
+	}
+
+	sort.Strings(dsIds)
+	return dsIds, nil
+}
+
+func (p *ProviderCatalog) DeleteDataset(id string, guard func(tx pkg.Transaction, ds dcat.Dataset) error) error {
+	return p.db.Transaction(func(tx pkg.Transaction) error {
+		datasets := tx.Collection(collProviderCatalog)
+		val, err := datasets.Get(id)
+		if err != nil {
+			return stores.QueryFailed(collProviderCatalog, `Get`, err)
+		}
+
+		if val == nil {
+			return stores.InvalidKey(id)
+		}
+
+		ds := val.(dcat.Dataset)
+		if guard != nil {
+			if err = guard(tx, ds); err != nil {
+				return err
+			}
+		}
+
+		sources := tx.Collection(collDistributionSource)
+		for _, dist := range ds.DcatDistribution {
+			if err = sources.Delete(dist.ID); err != nil {
+				return stores.QueryFailed(collDistributionSource, `Delete`, err)
+			}
+		}
+
+		if err = tx.Collection(collDatasetCatalog).Delete(id); err != nil {
+			return stores.QueryFailed(collDatasetCatalog, `Delete`, err)
+		}
+
+		if err = datasets.Delete(id); err != nil {
+			return stores.QueryFailed(collProviderCatalog, `Delete`, err)
+		}
+		return nil
+	})
+}
+
+func (p *ProviderCatalog) SetSource(distributionId string, val dataplane.Source) error {
+	if err := p.sources.Set(distributionId, val); err != nil {
+		return stores.QueryFailed(collDistributionSource, `Set`, err)
+	}
+	return nil
+}
+
+func (p *ProviderCatalog) Source(distributionId string) (dataplane.Source, error) {
+	val, err := p.sources.Get(distributionId)
+	if err != nil {
+		return dataplane.Source{}, stores.QueryFailed(collDistributionSource, `Get`, err)
+	}
+
+	if val == nil {
+		return dataplane.Source{}, stores.InvalidKey(distributionId)
+	}
+
+	return val.(dataplane.Source), nil
+}
+
+func entry(catalogs pkg.Collection, id string) (catalogEntry, error) {
+	val, err := catalogs.Get(id)
+	if err != nil {
+		return catalogEntry{}, stores.QueryFailed(collCatalogEntry, `Get`, err)
+	}
+
+	if val == nil {
+		return catalogEntry{}, stores.InvalidKey(id)
+	}
+
+	return val.(catalogEntry), nil
+}
+
+// entries returns all catalogs by their IDs
+func entries(catalogs pkg.Collection) (map[string]catalogEntry, error) {
+	vals, err := catalogs.GetAll()
+	if err != nil {
+		return nil, stores.QueryFailed(collCatalogEntry, `GetAll`, err)
+	}
+
+	entries := make(map[string]catalogEntry)
+	for _, val := range vals {
+		e := val.(catalogEntry)
+		entries[e.Metadata.ID] = e
+	}
+	return entries, nil
+}
+
+// memberships returns the catalog IDs of datasets by their IDs
+func memberships(members pkg.Collection) (map[string]string, error) {
+	vals, err := members.GetAll()
+	if err != nil {
+		return nil, stores.QueryFailed(collDatasetCatalog, `GetAll`, err)
+	}
+
+	catalogIds := make(map[string]string)
+	for _, val := range vals {
+		m := val.(membership)
+		catalogIds[m.DatasetId] = m.CatalogId
+	}
+	return catalogIds, nil
+}
@@ -0,0 +1,141 @@
+package catalog
+
+import (
+	defaultErr "errors"
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/boot"
+	"github.com/YasiruR/connector/domain/core/dataplane"
+	"github.com/YasiruR/connector/domain/models/dcat"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/domain/stores"
+	"github.com/YasiruR/connector/pkg/database/memory"
+	"github.com/YasiruR/connector/pkg/database/sqlite"
+	"github.com/YasiruR/connector/pkg/log"
+	"github.com/YasiruR/connector/pkg/urn"
+	"path/filepath"
+	"testing"
+)
+
+// databases returns the plugins of each database type so that the store is tested
+// against all implementations
+func databases(t *testing.T) map[string]domain.Plugins {
+	l := log.NewLogger()
+	return map[string]domain.Plugins{
+		`memory`: {Database: memory.NewStore(l), URNService: urn.NewGenerator(), Log: l},
+		sqlite.Type: {Database: sqlite.NewStore(filepath.Join(t.TempDir(), `test.db`), l),
+			URNService: urn.NewGenerator(), Log: l},
+	}
+}
+
+func TestProviderCatalog_AddCatalog(t *testing.T) {
+	for typ, plugins := range databases(t) {
+		p := NewProviderCatalog(boot.Config{}, plugins)
+		meta := dcat.CatalogMetadata{ID: `catalog`, DctTitle: `original`}
+		if err := p.AddCatalog(``, meta); err != nil {
+			t.Fatalf("%s: AddCatalog failed - %s", typ, err)
+		}
+
+		err := p.AddCatalog(`unknown`, dcat.CatalogMetadata{ID: `nested`})
+		if !defaultErr.Is(err, stores.TypeInvalidKey) {
+			t.Errorf("%s: AddCatalog with an unknown parent returned %v", typ, err)
+		}
+
+		err = p.AddCatalog(``, dcat.CatalogMetadata{ID: `catalog`, DctTitle: `replaced`})
+		if !defaultErr.Is(err, stores.TypeKeyExists) {
+			t.Errorf("%s: AddCatalog with an existing ID returned %v", typ, err)
+		}
+
+		cat, err := p.Catalog(`catalog`)
+		if err != nil {
+			t.Fatalf("%s: Catalog failed - %s", typ, err)
+		}
+
+		if cat.DctTitle != meta.DctTitle {
+			t.Errorf("%s: existing catalog was overwritten (title: %s)", typ, cat.DctTitle)
+		}
+	}
+}
+
+func TestProviderCatalog_DeleteDataset(t *testing.T) {
+	for typ, plugins := range databases(t) {
+		p := NewProviderCatalog(boot.Config{}, plugins)
+		ds := dcat.Dataset{ID: `dataset`, DcatDistribution: []dcat.Distribution{{ID: `distribution`}}}
+		sources := map[string]dataplane.Source{`distribution`: {ID: `source`, Path: `data.csv`}}
+		if err := p.AddDataset(`unknown`, ds.ID, ds, sources); !defaultErr.Is(err, stores.TypeInvalidKey) {
+			t.Errorf("%s: AddDataset with an unknown catalog returned %v", typ, err)
+		}
+
+		if _, err := p.Source(`distribution`); !defaultErr.Is(err, stores.TypeInvalidKey) {
+			t.Errorf("%s: data source of a rejected dataset was stored (error: %v)", typ, err)
+		}
+
+		if err := p.AddDataset(``, ds.ID, ds, sources); err != nil {
+			t.Fatalf("%s: AddDataset failed - %s", typ, err)
+		}
+
+		if _, err := p.Source(`distribution`); err != nil {
+			t.Errorf("%s: Source failed - %s", typ, err)
+		}
+
+		errGuard := defaultErr.New(`guard failed`)
+		err := p.DeleteDataset(ds.ID, func(pkg.Transaction, dcat.Dataset) error { return errGuard })
+		if !defaultErr.Is(err, errGuard) {
+			t.Errorf("%s: DeleteDataset with a failing guard returned %v", typ, err)
+		}
+
+		if _, err = p.Dataset(ds.ID); err != nil {
+			t.Errorf("%s: dataset was deleted although the guard failed (error: %s)", typ, err)
+		}
+
+		if err = p.DeleteDataset(ds.ID, nil); err != nil {
+			t.Fatalf("%s: DeleteDataset failed - %s", typ, err)
+		}
+
+		if _, err = p.Source(`distribution`); !defaultErr.Is(err, stores.TypeInvalidKey) {
+			t.Errorf("%s: data source of a deleted dataset was retained (error: %v)", typ, err)
+		}
+
+		if err = p.DeleteDataset(ds.ID, nil); !defaultErr.Is(err, stores.TypeInvalidKey) {
+			t.Errorf("%s: DeleteDataset of a deleted dataset returned %v", typ, err)
+		}
+	}
+}
+
+func TestProviderCatalog_UpdateDeleted(t *testing.T) {
+	for typ, plugins := range databases(t) {
+		p := NewProviderCatalog(boot.Config{}, plugins)
+		if err := p.AddDataset(``, `dataset`, dcat.Dataset{ID: `dataset`}, nil); err != nil {
+			t.Fatalf("%s: AddDataset failed - %s", typ, err)
+		}
+
+		if err := p.AddCatalog(``, dcat.CatalogMetadata{ID: `catalog`}); err != nil {
+			t.Fatalf("%s: AddCatalog failed - %s", typ, err)
+		}
+
+		if err := p.DeleteDataset(`dataset`, nil); err != nil {
+			t.Fatalf("%s: DeleteDataset failed - %s", typ, err)
+		}
+
+		if err := p.DeleteCatalog(`catalog`); err != nil {
+			t.Fatalf("%s: DeleteCatalog failed - %s", typ, err)
+		}
+
+		err := p.UpdateDataset(`dataset`, dcat.Dataset{ID: `dataset`, DctTitle: `updated`})
+		if !defaultErr.Is(err, stores.TypeInvalidKey) {
+			t.Errorf("%s: UpdateDataset of a deleted dataset returned %v", typ, err)
+		}
+
+		err = p.UpdateCatalog(dcat.CatalogMetadata{ID: `catalog`, DctTitle: `updated`})
+		if !defaultErr.Is(err, stores.TypeInvalidKey) {
+			t.Errorf("%s: UpdateCatalog of a deleted catalog returned %v", typ, err)
+		}
+
+		if _, err = p.Dataset(`dataset`); !defaultErr.Is(err, stores.TypeInvalidKey) {
+			t.Errorf("%s: deleted dataset was stored by the update (error: %v)", typ, err)
+		}
+
+		if _, err = p.Catalog(`catalog`); !defaultErr.Is(err, stores.TypeInvalidKey) {
+			t.Errorf("%s: deleted catalog was stored by the update (error: %v)", typ, err)
+		}
+	}
+}
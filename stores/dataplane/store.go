@@ -0,0 +1,66 @@
+package dataplane
+
+import (
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/core/dataplane"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/domain/stores"
+)
+
+const (
+	collToken    = `data-plane-token`
+	collProgress = `data-plane-progress`
+)
+
+type Store struct {
+	tokens   pkg.Collection
+	progress pkg.Collection
+}
+
+func NewStore(plugins domain.Plugins) *Store {
+	plugins.Log.Info("initialized data plane store")
+	return &Store{
+		tokens:   plugins.Database.NewCollection(collToken, dataplane.Token{}),
+		progress: plugins.Database.NewCollection(collProgress, dataplane.Progress{}),
+	}
+}
+
+func (s *Store) SetToken(tpId string, val dataplane.Token) error {
+	if err := s.tokens.Set(tpId, val); err != nil {
+		return stores.QueryFailed(collToken, `Set`, err)
+	}
+	return nil
+}
+
+func (s *Store) Token(tpId string) (dataplane.Token, error) {
+	val, err := s.tokens.Get(tpId)
+	if err != nil {
+		return dataplane.Token{}, stores.QueryFailed(collToken, `Get`, err)
+	}
+
+	if val == nil {
+		return dataplane.Token{}, stores.InvalidKey(tpId)
+	}
+
+	return val.(dataplane.Token), nil
+}
+
+func (s *Store) SetProgress(tpId string, val dataplane.Progress) error {
+	if err := s.progress.Set(tpId, val); err != nil {
+		return stores.QueryFailed(collProgress, `Set`, err)
+	}
+	return nil
+}
+
+func (s *Store) Progress(tpId string) (dataplane.Progress, error) {
+	val, err := s.progress.Get(tpId)
+	if err != nil {
+		return dataplane.Progress{}, stores.QueryFailed(collProgress, `Get`, err)
+	}
+
+	if val == nil {
+		return dataplane.Progress{}, stores.InvalidKey(tpId)
+	}
+
+	return val.(dataplane.Progress), nil
+}
@@ -5,30 +5,57 @@ import (
 	"github.com/YasiruR/connector/domain/models/odrl"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
+	"github.com/YasiruR/connector/pkg/database"
 )
 
 const (
 	collAgreement            = `agreement`
 	collNegotiationAgreement = `negotiation-agreement`
+	collAgreementNegotiation = `agreement-negotiation`
 )
 
 type Agreement struct {
+	db       pkg.Database
 	agrColl  pkg.Collection
 	cnAgrMap pkg.Collection
+	agrCnMap pkg.Collection
 }
 
 func NewAgreementStore(plugins domain.Plugins) *Agreement {
 	plugins.Log.Info("initialized agreement store")
-	return &Agreement{agrColl: plugins.Database.NewCollection(), cnAgrMap: plugins.Database.NewCollection()}
+	return newAgreement(plugins.Database)
 }
 
-func (a *Agreement) AddAgreement(cnId string, val odrl.Agreement) {
-	_ = a.agrColl.Set(val.Id, val)
-	a.setNegotiationId(cnId, val.Id)
+func newAgreement(db pkg.Database) *Agreement {
+	return &Agreement{
+		db:       db,
+		agrColl:  db.NewCollection(collAgreement, odrl.Agreement{}),
+		cnAgrMap: db.NewCollection(collNegotiationAgreement, ``),
+		agrCnMap: db.NewCollection(collAgreementNegotiation, ``),
+	}
+}
+
+func (a *Agreement) WithTx(tx pkg.Transaction) stores.AgreementStore {
+	return newAgreement(database.Bound(tx))
 }
 
-func (a *Agreement) setNegotiationId(cnId, agrId string) {
-	_ = a.cnAgrMap.Set(cnId, agrId)
+// AddAgreement stores the agreement along with its links to the negotiation in both
+// directions atomically
+func (a *Agreement) AddAgreement(cnId string, val odrl.Agreement) error {
+	return a.db.Transaction(func(tx pkg.Transaction) error {
+		if err := tx.Collection(collAgreement).Set(val.Id, val); err != nil {
+			return stores.QueryFailed(collAgreement, `Set`, err)
+		}
+
+		if err := tx.Collection(collNegotiationAgreement).Set(cnId, val.Id); err != nil {
+			return stores.QueryFailed(collNegotiationAgreement, `Set`, err)
+		}
+
+		if err := tx.Collection(collAgreementNegotiation).Set(val.Id, cnId); err != nil {
+			return stores.QueryFailed(collAgreementNegotiation, `Set`, err)
+		}
+		return nil
+	})
 }
 
 func (a *Agreement) Agreement(id string) (odrl.Agreement, error) {
@@ -44,15 +71,28 @@ func (a *Agreement) Agreement(id string) (odrl.Agreement, error) {
 	return val.(odrl.Agreement), nil
 }
 
-//func (a *Agreement) AgreementByNegotiationID(cnId string) (odrl.Agreement, error) {
-//	agrId, err := a.cnAgrMap.Get(cnId)
-//	if err != nil {
-//		return odrl.Agreement{}, errors.QueryFailed(collNegotiationAgreement, `Get`, err)
-//	}
-//
-//	if agrId == nil {
-//		return odrl.Agreement{}, errors.InvalidKey(cnId)
-//	}
-//
-//	return a.Agreement(agrId.(string))
-//}
+func (a *Agreement) AgreementByNegotiationID(cnId string) (odrl.Agreement, error) {
+	agrId, err := a.cnAgrMap.Get(cnId)
+	if err != nil {
+		return odrl.Agreement{}, stores.QueryFailed(collNegotiationAgreement, `Get`, err)
+	}
+
+	if agrId == nil {
+		return odrl.Agreement{}, stores.InvalidKey(cnId)
+	}
+
+	return a.Agreement(agrId.(string))
+}
+
+func (a *Agreement) NegotiationID(agrId string) (string, error) {
+	cnId, err := a.agrCnMap.Get(agrId)
+	if err != nil {
+		return ``, stores.QueryFailed(collAgreementNegotiation, `Get`, err)
+	}
+
+	if cnId == nil {
+		return ``, stores.InvalidKey(agrId)
+	}
+
+	return cnId.(string), nil
+}
@@ -7,14 +7,17 @@ import (
 	"github.com/YasiruR/connector/domain/stores"
 )
 
+const collOffer = `offer`
+
 // OfferStore is a store that exists within a Provider to persist any created policy
 type OfferStore struct {
+	db    pkg.Database
 	store pkg.Collection
 }
 
 func NewOfferStore(plugins domain.Plugins) *OfferStore {
 	plugins.Log.Info("initialized offer store")
-	return &OfferStore{store: plugins.Database.NewCollection()}
+	return &OfferStore{db: plugins.Database, store: plugins.Database.NewCollection(collOffer, odrl.Offer{})}
 }
 
 func (o *OfferStore) AddOffer(id string, val odrl.Offer) {
@@ -24,7 +27,7 @@ func (o *OfferStore) AddOffer(id string, val odrl.Offer) {
 func (o *OfferStore) Offer(id string) (odrl.Offer, error) {
 	val, err := o.store.Get(id)
 	if err != nil {
-		return odrl.Offer{}, stores.QueryFailed(`policy`, `get`, err)
+		return odrl.Offer{}, stores.QueryFailed(collOffer, `get`, err)
 	}
 
 	if val == nil {
@@ -33,3 +36,65 @@ func (o *OfferStore) Offer(id string) (odrl.Offer, error) {
 
 	return val.(odrl.Offer), nil
 }
+
+func (o *OfferStore) Offers() ([]odrl.Offer, error) {
+	vals, err := o.store.GetAll()
+	if err != nil {
+		return nil, stores.QueryFailed(collOffer, `GetAll`, err)
+	}
+
+	ofrs := make([]odrl.Offer, 0, len(vals))
+	for _, val := range vals {
+		ofrs = append(ofrs, val.(odrl.Offer))
+	}
+	return ofrs, nil
+}
+
+func (o *OfferStore) UpdateOffer(id string, val odrl.Offer, guard func(tx pkg.Transaction) error) error {
+	return o.db.Transaction(func(tx pkg.Transaction) error {
+		coll, err := o.guarded(tx, id, guard)
+		if err != nil {
+			return err
+		}
+
+		if err = coll.Set(id, val); err != nil {
+			return stores.QueryFailed(collOffer, `Set`, err)
+		}
+		return nil
+	})
+}
+
+func (o *OfferStore) DeleteOffer(id string, guard func(tx pkg.Transaction) error) error {
+	return o.db.Transaction(func(tx pkg.Transaction) error {
+		coll, err := o.guarded(tx, id, guard)
+		if err != nil {
+			return err
+		}
+
+		if err = coll.Delete(id); err != nil {
+			return stores.QueryFailed(collOffer, `Delete`, err)
+		}
+		return nil
+	})
+}
+
+// guarded returns the collection of offers within the transaction if the offer exists
+// and the guard (if any) succeeds
+func (o *OfferStore) guarded(tx pkg.Transaction, id string, guard func(tx pkg.Transaction) error) (pkg.Collection, error) {
+	coll := tx.Collection(collOffer)
+	val, err := coll.Get(id)
+	if err != nil {
+		return nil, stores.QueryFailed(collOffer, `Get`, err)
+	}
+
+	if val == nil {
+		return nil, stores.InvalidKey(id)
+	}
+
+	if guard != nil {
+		if err = guard(tx); err != nil {
+			return nil, err
+		}
+	}
+	return coll, nil
+}
@@ -0,0 +1,39 @@
+package protocol
+
+import (
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/core/consumer"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/domain/stores"
+)
+
+const collFetch = `fetch`
+
+type Fetch struct {
+	coll pkg.Collection
+}
+
+func NewFetchStore(plugins domain.Plugins) *Fetch {
+	plugins.Log.Info("initialized fetch store")
+	return &Fetch{coll: plugins.Database.NewCollection(collFetch, consumer.Fetch{})}
+}
+
+func (f *Fetch) SetFetch(id string, val consumer.Fetch) error {
+	if err := f.coll.Set(id, val); err != nil {
+		return stores.QueryFailed(collFetch, `Set`, err)
+	}
+	return nil
+}
+
+func (f *Fetch) Fetch(id string) (consumer.Fetch, error) {
+	val, err := f.coll.Get(id)
+	if err != nil {
+		return consumer.Fetch{}, stores.QueryFailed(collFetch, `Get`, err)
+	}
+
+	if val == nil {
+		return consumer.Fetch{}, stores.InvalidKey(id)
+	}
+
+	return val.(consumer.Fetch), nil
+}
@@ -1,11 +1,14 @@
 package protocol
 
 import (
+	"fmt"
 	"github.com/YasiruR/connector/domain"
 	"github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
 	"github.com/YasiruR/connector/domain/models/odrl"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
+	"github.com/YasiruR/connector/pkg/database"
+	"time"
 )
 
 const (
@@ -13,28 +16,88 @@ const (
 	collAssignee     = `assignee`
 	collAssigner     = `assigner`
 	collCallbackAddr = `callbackAddr`
+	collCnOffer      = `negotiation-offer`
+	collOfferIndex   = `negotiation-offer-index`
+	collCnRound      = `negotiation-round`
+	collCounterparty = `negotiation-counterparty`
 )
 
 // ContractNegotiation stores any ongoing activities related to Contract Negotiation Protocol
 type ContractNegotiation struct {
+	db           pkg.Database
 	negotiations pkg.Collection
 	assignees    pkg.Collection
 	assigners    pkg.Collection
 	callbackAddr pkg.Collection
+	offers       pkg.Collection
+	offerIndex   pkg.Collection // negotiation IDs by the offer, keyed as per offerKey
+	rounds       pkg.Collection
+	counterparty pkg.Collection
 }
 
 func NewContractNegotiationStore(plugins domain.Plugins) *ContractNegotiation {
 	plugins.Log.Info("initialized contract negotiation store")
+	return newContractNegotiation(plugins.Database)
+}
+
+func newContractNegotiation(db pkg.Database) *ContractNegotiation {
 	return &ContractNegotiation{
-		negotiations: plugins.Database.NewCollection(),
-		assignees:    plugins.Database.NewCollection(),
-		assigners:    plugins.Database.NewCollection(),
-		callbackAddr: plugins.Database.NewCollection(),
+		db:           db,
+		negotiations: db.NewCollection(collNegotiation, negotiation.Negotiation{}),
+		assignees:    db.NewCollection(collAssignee, odrl.Assignee(``)),
+		assigners:    db.NewCollection(collAssigner, odrl.Assigner(``)),
+		callbackAddr: db.NewCollection(collCallbackAddr, ``),
+		offers:       db.NewCollection(collCnOffer, ``),
+		offerIndex:   db.NewCollection(collOfferIndex, ``),
+		rounds:       db.NewCollection(collCnRound, []negotiation.Round{}),
+		counterparty: db.NewCollection(collCounterparty, ``),
 	}
 }
 
-func (cn *ContractNegotiation) AddNegotiation(cnId string, val negotiation.Negotiation) {
-	_ = cn.negotiations.Set(cnId, val)
+func (cn *ContractNegotiation) WithTx(tx pkg.Transaction) stores.ContractNegotiationStore {
+	return newContractNegotiation(database.Bound(tx))
+}
+
+// AddNegotiation stores a new negotiation or replaces an existing one if the
+// state transition is allowed
+func (cn *ContractNegotiation) AddNegotiation(cnId string, val negotiation.Negotiation) error {
+	return cn.db.Transaction(func(tx pkg.Transaction) error {
+		return cn.setNegotiation(tx, cnId, val)
+	})
+}
+
+func (cn *ContractNegotiation) SetNegotiation(cnId string, val negotiation.Negotiation, callbackAddr string,
+	assigner odrl.Assigner, assignee odrl.Assignee) error {
+	return cn.db.Transaction(func(tx pkg.Transaction) error {
+		if err := cn.setNegotiation(tx, cnId, val); err != nil {
+			return err
+		}
+		return cn.setParticipants(tx, cnId, callbackAddr, assigner, assignee)
+	})
+}
+
+// setNegotiation validates the transition from the stored state (if any) to the
+// state of the given negotiation before storing it
+func (cn *ContractNegotiation) setNegotiation(tx pkg.Transaction, cnId string, val negotiation.Negotiation) error {
+	coll := tx.Collection(collNegotiation)
+	cur, err := coll.Get(cnId)
+	if err != nil {
+		return stores.QueryFailed(collNegotiation, `Get`, err)
+	}
+
+	var curState negotiation.State
+	if cur != nil {
+		curState = cur.(negotiation.Negotiation).State
+	}
+
+	if !negotiation.ValidTransition(curState, val.State) {
+		return stores.InvalidTransition(cnId, string(curState), string(val.State))
+	}
+
+	if err = coll.Set(cnId, val); err != nil {
+		return stores.QueryFailed(collNegotiation, `Set`, err)
+	}
+	return nil
 }
 
 func (cn *ContractNegotiation) Negotiation(cnId string) (negotiation.Negotiation, error) {
@@ -57,38 +120,20 @@ func (cn *ContractNegotiation) UpdateState(cnId string, s negotiation.State) err
 		return stores.QueryFailed(collNegotiation, `Get`, err)
 	}
 
-	//switch s {
-	//case negotiation.StateRequested:
-	//	if neg.State != negotiation.StateOffered && neg.State != `` {
-	//		return negotiation.Negotiation{}, errors.IncompatibleValues(`state`, string(neg.State), string(negotiation.StateOffered)+" or null")
-	//	}
-	//case negotiation.StateOffered:
-	//	if neg.State != negotiation.StateRequested && neg.State != `` {
-	//		return negotiation.Negotiation{}, errors.IncompatibleValues(`state`, string(neg.State), string(negotiation.StateRequested)+" or null")
-	//	}
-	//case negotiation.StateAccepted:
-	//	if neg.State != negotiation.StateOffered {
-	//		return negotiation.Negotiation{}, errors.IncompatibleValues(`state`, string(neg.State), string(negotiation.StateOffered))
-	//	}
-	//case negotiation.StateAgreed:
-	//	if neg.State != negotiation.StateAccepted && neg.State != negotiation.StateRequested {
-	//		return negotiation.Negotiation{}, errors.IncompatibleValues(`state`, string(neg.State), string(negotiation.StateAccepted)+
-	//			" or "+string(negotiation.StateRequested))
-	//	}
-	//case negotiation.StateVerified:
-	//	if neg.State != negotiation.StateAgreed {
-	//		return negotiation.Negotiation{}, errors.IncompatibleValues(`state`, string(neg.State), string(negotiation.StateAgreed))
-	//	}
-	//case negotiation.StateFinalized:
-	//	if neg.State != negotiation.StateVerified {
-	//		return negotiation.Negotiation{}, errors.IncompatibleValues(`state`, string(neg.State), string(negotiation.StateVerified))
-	//	}
-	//default:
-	//	return negotiation.Negotiation{}, errors.IncompatibleValues(`state`, string(neg.State), "valid state value")
-	//}
-
-	neg.State = s
-	cn.AddNegotiation(cnId, neg)
+	if !negotiation.ValidTransition(neg.State, s) {
+		return stores.InvalidTransition(cnId, string(neg.State), string(s))
+	}
+
+	updated := neg
+	updated.State = s
+	swapped, err := cn.negotiations.CompareAndSwap(cnId, neg, updated)
+	if err != nil {
+		return stores.QueryFailed(collNegotiation, `CompareAndSwap`, err)
+	}
+
+	if !swapped {
+		return stores.Conflict(cnId)
+	}
 	return nil
 }
 
@@ -101,10 +146,28 @@ func (cn *ContractNegotiation) State(cnId string) (negotiation.State, error) {
 	return neg.State, nil
 }
 
-func (cn *ContractNegotiation) SetParticipants(cnId, callbackAddr string, assigner odrl.Assigner, assignee odrl.Assignee) {
-	_ = cn.assigners.Set(cnId, assigner)
-	_ = cn.assignees.Set(cnId, assignee)
-	_ = cn.callbackAddr.Set(cnId, callbackAddr)
+func (cn *ContractNegotiation) SetParticipants(cnId, callbackAddr string, assigner odrl.Assigner,
+	assignee odrl.Assignee) error {
+	return cn.db.Transaction(func(tx pkg.Transaction) error {
+		return cn.setParticipants(tx, cnId, callbackAddr, assigner, assignee)
+	})
+}
+
+func (cn *ContractNegotiation) setParticipants(tx pkg.Transaction, cnId, callbackAddr string,
+	assigner odrl.Assigner, assignee odrl.Assignee) error {
+	if err := tx.Collection(collAssigner).Set(cnId, assigner); err != nil {
+		return stores.QueryFailed(collAssigner, `Set`, err)
+	}
+
+	if err := tx.Collection(collAssignee).Set(cnId, assignee); err != nil {
+		return stores.QueryFailed(collAssignee, `Set`, err)
+	}
+
+	if err := tx.Collection(collCallbackAddr).Set(cnId, callbackAddr); err != nil {
+		return stores.QueryFailed(collCallbackAddr, `Set`, err)
+	}
+
+	return nil
 }
 
 func (cn *ContractNegotiation) Assignee(cnId string) (odrl.Assignee, error) {
@@ -145,3 +208,143 @@ func (cn *ContractNegotiation) CallbackAddr(cnId string) (string, error) {
 
 	return addr.(string), nil
 }
+
+func (cn *ContractNegotiation) SetCounterparty(cnId, participantId string) error {
+	if err := cn.counterparty.Set(cnId, participantId); err != nil {
+		return stores.QueryFailed(collCounterparty, `Set`, err)
+	}
+	return nil
+}
+
+func (cn *ContractNegotiation) Counterparty(cnId string) (participantId string, err error) {
+	val, err := cn.counterparty.Get(cnId)
+	if err != nil {
+		return ``, stores.QueryFailed(collCounterparty, `get`, err)
+	}
+
+	if val == nil {
+		return ``, stores.InvalidKey(cnId)
+	}
+
+	return val.(string), nil
+}
+
+// SetOffer links the negotiation to the offer and replaces the index entry of any
+// offer previously linked to the negotiation
+func (cn *ContractNegotiation) SetOffer(cnId, offerId string) error {
+	return cn.db.Transaction(func(tx pkg.Transaction) error {
+		offers := tx.Collection(collCnOffer)
+		old, err := offers.Get(cnId)
+		if err != nil {
+			return stores.QueryFailed(collCnOffer, `Get`, err)
+		}
+
+		index := tx.Collection(collOfferIndex)
+		if old != nil {
+			if err = index.Delete(offerKey(old.(string), cnId)); err != nil {
+				return stores.QueryFailed(collOfferIndex, `Delete`, err)
+			}
+		}
+
+		if err = offers.Set(cnId, offerId); err != nil {
+			return stores.QueryFailed(collCnOffer, `Set`, err)
+		}
+
+		if err = index.Set(offerKey(offerId, cnId), cnId); err != nil {
+			return stores.QueryFailed(collOfferIndex, `Set`, err)
+		}
+		return nil
+	})
+}
+
+func (cn *ContractNegotiation) NegotiationsByOffer(offerId string) ([]string, error) {
+	return negotiationsByOffer(cn.offerIndex, offerId)
+}
+
+func (cn *ContractNegotiation) StatesByOffer(tx pkg.Transaction, offerId string) (map[string]negotiation.State, error) {
+	cnIds, err := negotiationsByOffer(tx.Collection(collOfferIndex), offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	negotiations := tx.Collection(collNegotiation)
+	states := make(map[string]negotiation.State)
+	for _, cnId := range cnIds {
+		val, err := negotiations.Get(cnId)
+		if err != nil {
+			return nil, stores.QueryFailed(collNegotiation, `Get`, err)
+		}
+
+		if val == nil {
+			return nil, stores.InvalidKey(cnId)
+		}
+		states[cnId] = val.(negotiation.Negotiation).State
+	}
+	return states, nil
+}
+
+// negotiationsByOffer scans the entries of the offer in the index instead of
+// querying the offers of all negotiations
+func negotiationsByOffer(index pkg.Collection, offerId string) ([]string, error) {
+	vals, _, err := index.Scan(offerKey(offerId, ``), ``, 0)
+	if err != nil {
+		return nil, stores.QueryFailed(collOfferIndex, `Scan`, err)
+	}
+
+	cnIds := make([]string, 0, len(vals))
+	for _, val := range vals {
+		cnIds = append(cnIds, val.(string))
+	}
+	return cnIds, nil
+}
+
+// offerKey prefixes the negotiation ID with the length of the offer ID so that an
+// offer ID can not be a prefix of another, and the keys with the prefix of an empty
+// negotiation ID are those of the offer
+func offerKey(offerId, cnId string) string {
+	return fmt.Sprintf("%d:%s:%s", len(offerId), offerId, cnId)
+}
+
+// AddRound appends the round to the rounds of the negotiation, where the timestamp
+// is set if not provided
+func (cn *ContractNegotiation) AddRound(cnId string, r negotiation.Round) error {
+	if r.Timestamp.IsZero() {
+		r.Timestamp = time.Now().UTC()
+	}
+
+	return cn.db.Transaction(func(tx pkg.Transaction) error {
+		coll := tx.Collection(collCnRound)
+		val, err := coll.Get(cnId)
+		if err != nil {
+			return stores.QueryFailed(collCnRound, `Get`, err)
+		}
+
+		var rounds []negotiation.Round
+		if val != nil {
+			rounds = val.([]negotiation.Round)
+		}
+
+		// a new slice is used so that the stored rounds are not modified in place
+		updated := make([]negotiation.Round, len(rounds), len(rounds)+1)
+		copy(updated, rounds)
+		updated = append(updated, r)
+
+		if err = coll.Set(cnId, updated); err != nil {
+			return stores.QueryFailed(collCnRound, `Set`, err)
+		}
+		return nil
+	})
+}
+
+func (cn *ContractNegotiation) Rounds(cnId string) ([]negotiation.Round, error) {
+	val, err := cn.rounds.Get(cnId)
+	if err != nil {
+		return nil, stores.QueryFailed(collCnRound, `Get`, err)
+	}
+
+	if val == nil {
+		return nil, stores.InvalidKey(cnId)
+	}
+
+	return val.([]negotiation.Round), nil
+}
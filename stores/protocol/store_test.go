@@ -0,0 +1,205 @@
+package protocol
+
+import (
+	defaultErr "errors"
+	"github.com/YasiruR/connector/domain"
+	"github.com/YasiruR/connector/domain/api/dsp/http/negotiation"
+	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
+	"github.com/YasiruR/connector/domain/core"
+	"github.com/YasiruR/connector/domain/pkg"
+	"github.com/YasiruR/connector/domain/stores"
+	"github.com/YasiruR/connector/pkg/database/memory"
+	"github.com/YasiruR/connector/pkg/database/sqlite"
+	"github.com/YasiruR/connector/pkg/log"
+	"path/filepath"
+	"testing"
+)
+
+// databases returns the plugins of each database type so that the stores are tested
+// against all implementations
+func databases(t *testing.T) map[string]domain.Plugins {
+	l := log.NewLogger()
+	return map[string]domain.Plugins{
+		`memory`:    {Database: memory.NewStore(l), Log: l},
+		sqlite.Type: {Database: sqlite.NewStore(filepath.Join(t.TempDir(), `test.db`), l), Log: l},
+	}
+}
+
+func TestContractNegotiation_InvalidTransition(t *testing.T) {
+	for typ, plugins := range databases(t) {
+		s := NewContractNegotiationStore(plugins)
+		cn := negotiation.Negotiation{ProvPId: `provider-pid`, ConsPId: `consumer-pid`,
+			State: negotiation.StateAgreed}
+		if err := s.AddNegotiation(`cn`, cn); !defaultErr.Is(err, stores.TypeInvalidTransition) {
+			t.Errorf("%s: AddNegotiation in a state not following an empty state returned %v", typ, err)
+		}
+
+		cn.State = negotiation.StateRequested
+		if err := s.AddNegotiation(`cn`, cn); err != nil {
+			t.Fatalf("%s: AddNegotiation failed - %s", typ, err)
+		}
+
+		cn.State = negotiation.StateFinalized
+		if err := s.SetNegotiation(`cn`, cn, `http://localhost:8080`, `provider`,
+			`consumer`); !defaultErr.Is(err, stores.TypeInvalidTransition) {
+			t.Errorf("%s: SetNegotiation from %s to %s returned %v", typ, negotiation.StateRequested,
+				negotiation.StateFinalized, err)
+		}
+
+		if err := s.UpdateState(`cn`, negotiation.StateVerified); !defaultErr.Is(err, stores.TypeInvalidTransition) {
+			t.Errorf("%s: UpdateState from %s to %s returned %v", typ, negotiation.StateRequested,
+				negotiation.StateVerified, err)
+		}
+
+		if err := s.UpdateState(`cn`, negotiation.StateAgreed); err != nil {
+			t.Errorf("%s: UpdateState from %s to %s failed - %s", typ, negotiation.StateRequested,
+				negotiation.StateAgreed, err)
+		}
+
+		if state, err := s.State(`cn`); err != nil || state != negotiation.StateAgreed {
+			t.Errorf("%s: stored state is %s (error: %v), want %s", typ, state, err, negotiation.StateAgreed)
+		}
+	}
+}
+
+func TestTransfer_InvalidTransition(t *testing.T) {
+	for typ, plugins := range databases(t) {
+		s := NewTransferStore(plugins)
+		cause := transfer.Cause{Initiator: core.RoleConsumer}
+		tp := transfer.Process{ProvPId: `provider-pid`, ConsPId: `consumer-pid`, State: transfer.StateStarted}
+		if err := s.AddProcess(`tp`, tp, cause); !defaultErr.Is(err, stores.TypeInvalidTransition) {
+			t.Errorf("%s: AddProcess in a state not following an empty state returned %v", typ, err)
+		}
+
+		tp.State = transfer.StateRequested
+		if err := s.AddProcess(`tp`, tp, cause); err != nil {
+			t.Fatalf("%s: AddProcess failed - %s", typ, err)
+		}
+
+		tp.State = transfer.StateCompleted
+		if err := s.SetProcess(`tp`, tp, transfer.Request{}, cause); !defaultErr.Is(err,
+			stores.TypeInvalidTransition) {
+			t.Errorf("%s: SetProcess from %s to %s returned %v", typ, transfer.StateRequested,
+				transfer.StateCompleted, err)
+		}
+
+		if err := s.UpdateState(`tp`, transfer.StateSuspended, cause); !defaultErr.Is(err,
+			stores.TypeInvalidTransition) {
+			t.Errorf("%s: UpdateState from %s to %s returned %v", typ, transfer.StateRequested,
+				transfer.StateSuspended, err)
+		}
+
+		if err := s.UpdateState(`tp`, transfer.StateStarted, cause); err != nil {
+			t.Errorf("%s: UpdateState from %s to %s failed - %s", typ, transfer.StateRequested,
+				transfer.StateStarted, err)
+		}
+
+		// rejected transitions are not recorded in the history
+		history, err := s.History(`tp`)
+		if err != nil || len(history) != 2 {
+			t.Errorf("%s: history contains %d transitions (error: %v), want 2", typ, len(history), err)
+		}
+	}
+}
+
+func TestContractNegotiation_StatesByOffer(t *testing.T) {
+	for typ, plugins := range databases(t) {
+		s := NewContractNegotiationStore(plugins)
+		for cnId, offerId := range map[string]string{`cn-1`: `offer`, `cn-2`: `offer`, `cn-3`: `offer-2`} {
+			cn := negotiation.Negotiation{ProvPId: cnId, State: negotiation.StateRequested}
+			if err := s.AddNegotiation(cnId, cn); err != nil {
+				t.Fatalf("%s: AddNegotiation failed - %s", typ, err)
+			}
+
+			if err := s.SetOffer(cnId, offerId); err != nil {
+				t.Fatalf("%s: SetOffer failed - %s", typ, err)
+			}
+		}
+
+		if err := s.UpdateState(`cn-2`, negotiation.StateTerminated); err != nil {
+			t.Fatalf("%s: UpdateState failed - %s", typ, err)
+		}
+
+		var states map[string]negotiation.State
+		err := plugins.Database.Transaction(func(tx pkg.Transaction) (err error) {
+			states, err = s.StatesByOffer(tx, `offer`)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("%s: StatesByOffer failed - %s", typ, err)
+		}
+
+		if len(states) != 2 || states[`cn-1`] != negotiation.StateRequested ||
+			states[`cn-2`] != negotiation.StateTerminated {
+			t.Errorf("%s: StatesByOffer returned %v", typ, states)
+		}
+
+		// negotiation linked to another offer is removed from the former offer
+		if err = s.SetOffer(`cn-1`, `offer-2`); err != nil {
+			t.Fatalf("%s: SetOffer failed - %s", typ, err)
+		}
+
+		cnIds, err := s.NegotiationsByOffer(`offer`)
+		if err != nil || len(cnIds) != 1 || cnIds[0] != `cn-2` {
+			t.Errorf("%s: NegotiationsByOffer returned %v (error: %v), want [cn-2]", typ, cnIds, err)
+		}
+	}
+}
+
+func TestContractNegotiation_WithTx(t *testing.T) {
+	for typ, plugins := range databases(t) {
+		s := NewContractNegotiationStore(plugins)
+		cn := negotiation.Negotiation{ProvPId: `cn`, State: negotiation.StateRequested}
+		write := func(fail bool) error {
+			return plugins.Database.Transaction(func(tx pkg.Transaction) error {
+				txStore := s.WithTx(tx)
+				if err := txStore.SetNegotiation(`cn`, cn, `http://localhost:8080`, `provider`,
+					`consumer`); err != nil {
+					return err
+				}
+
+				if err := txStore.SetCounterparty(`cn`, `consumer`); err != nil {
+					return err
+				}
+
+				if err := txStore.AddRound(`cn`, negotiation.Round{Proposer: negotiation.ProposerConsumer}); err != nil {
+					return err
+				}
+
+				// writes are visible within the transaction before it is committed
+				if _, err := txStore.Negotiation(`cn`); err != nil {
+					return err
+				}
+
+				if fail {
+					return stores.Conflict(`cn`)
+				}
+				return nil
+			})
+		}
+
+		if err := write(true); !defaultErr.Is(err, stores.TypeConflict) {
+			t.Fatalf("%s: failed transaction returned %v", typ, err)
+		}
+
+		if _, err := s.Negotiation(`cn`); !defaultErr.Is(err, stores.TypeInvalidKey) {
+			t.Errorf("%s: negotiation of a failed transaction was stored (error: %v)", typ, err)
+		}
+
+		if _, err := s.Counterparty(`cn`); !defaultErr.Is(err, stores.TypeInvalidKey) {
+			t.Errorf("%s: counterparty of a failed transaction was stored (error: %v)", typ, err)
+		}
+
+		if err := write(false); err != nil {
+			t.Fatalf("%s: transaction failed - %s", typ, err)
+		}
+
+		if rounds, err := s.Rounds(`cn`); err != nil || len(rounds) != 1 {
+			t.Errorf("%s: stored %d rounds (error: %v), want 1", typ, len(rounds), err)
+		}
+
+		if counterparty, err := s.Counterparty(`cn`); err != nil || counterparty != `consumer` {
+			t.Errorf("%s: stored counterparty is %s (error: %v), want consumer", typ, counterparty, err)
+		}
+	}
+}
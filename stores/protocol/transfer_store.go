@@ -5,24 +5,95 @@ import (
 	"github.com/YasiruR/connector/domain/api/dsp/http/transfer"
 	"github.com/YasiruR/connector/domain/pkg"
 	"github.com/YasiruR/connector/domain/stores"
+	"github.com/YasiruR/connector/pkg/database"
+	"time"
 )
 
 const (
-	collTransfer = `transfer`
+	collTransfer             = `transfer`
+	collTransferCallbackAddr = `transfer-callbackAddr`
+	collTransferHistory      = `transfer-history`
+	collTransferRequest      = `transfer-request`
+	collTransferOffset       = `transfer-offset`
+	collTransferCounterparty = `transfer-counterparty`
 )
 
 type Transfer struct {
+	db           pkg.Database
 	coll         pkg.Collection
 	callbackAddr pkg.Collection
+	history      pkg.Collection
+	requests     pkg.Collection
+	offsets      pkg.Collection
+	counterparty pkg.Collection
 }
 
 func NewTransferStore(plugins domain.Plugins) *Transfer {
 	plugins.Log.Info("initialized transfer process store")
-	return &Transfer{coll: plugins.Database.NewCollection(), callbackAddr: plugins.Database.NewCollection()}
+	return newTransfer(plugins.Database)
 }
 
-func (t *Transfer) AddProcess(tpId string, val transfer.Process) {
-	_ = t.coll.Set(tpId, val)
+func newTransfer(db pkg.Database) *Transfer {
+	return &Transfer{
+		db:           db,
+		coll:         db.NewCollection(collTransfer, transfer.Process{}),
+		callbackAddr: db.NewCollection(collTransferCallbackAddr, ``),
+		history:      db.NewCollection(collTransferHistory, []transfer.Transition{}),
+		requests:     db.NewCollection(collTransferRequest, transfer.Request{}),
+		offsets:      db.NewCollection(collTransferOffset, int64(0)),
+		counterparty: db.NewCollection(collTransferCounterparty, ``),
+	}
+}
+
+func (t *Transfer) WithTx(tx pkg.Transaction) stores.TransferStore {
+	return newTransfer(database.Bound(tx))
+}
+
+func (t *Transfer) AddProcess(tpId string, val transfer.Process, c transfer.Cause) error {
+	return t.db.Transaction(func(tx pkg.Transaction) error {
+		return t.setProcess(tx, tpId, val, c)
+	})
+}
+
+func (t *Transfer) SetProcess(tpId string, val transfer.Process, req transfer.Request, c transfer.Cause) error {
+	return t.db.Transaction(func(tx pkg.Transaction) error {
+		if err := t.setProcess(tx, tpId, val, c); err != nil {
+			return err
+		}
+
+		if err := tx.Collection(collTransferRequest).Set(tpId, req); err != nil {
+			return stores.QueryFailed(collTransferRequest, `Set`, err)
+		}
+
+		if err := tx.Collection(collTransferCallbackAddr).Set(tpId, req.CallbackAddr); err != nil {
+			return stores.QueryFailed(collTransferCallbackAddr, `Set`, err)
+		}
+		return nil
+	})
+}
+
+func (t *Transfer) Request(tpId string) (transfer.Request, error) {
+	val, err := t.requests.Get(tpId)
+	if err != nil {
+		return transfer.Request{}, stores.QueryFailed(collTransferRequest, `Get`, err)
+	}
+
+	if val == nil {
+		return transfer.Request{}, stores.InvalidKey(tpId)
+	}
+
+	return val.(transfer.Request), nil
+}
+
+func (t *Transfer) CountByAgreement(agreementId string) (int, error) {
+	vals, err := t.requests.Query(func(_ string, val any) bool {
+		return val.(transfer.Request).AgreementId == agreementId
+	})
+	if err != nil {
+		return 0, stores.QueryFailed(collTransferRequest, `Query`, err)
+	}
+
+	return len(vals), nil
 }
 
 func (t *Transfer) Process(id string) (transfer.Process, error) {
@@ -45,7 +116,76 @@ func (t *Transfer) SetCallbackAddr(tpId, addr string) {
 func (t *Transfer) CallbackAddr(tpId string) (string, error) {
 	val, err := t.callbackAddr.Get(tpId)
 	if err != nil {
-		return ``, stores.QueryFailed(collCallbackAddr, `Get`, err)
+		return ``, stores.QueryFailed(collTransferCallbackAddr, `Get`, err)
+	}
+
+	if val == nil {
+		return ``, stores.InvalidKey(tpId)
+	}
+
+	return val.(string), nil
+}
+
+// UpdateState validates the transition against the transfer state machine and
+// records it in the history of the process within the same transaction
+func (t *Transfer) UpdateState(tpId string, s transfer.State, c transfer.Cause) error {
+	return t.db.Transaction(func(tx pkg.Transaction) error {
+		coll := tx.Collection(collTransfer)
+		val, err := coll.Get(tpId)
+		if err != nil {
+			return stores.QueryFailed(collTransfer, `Get`, err)
+		}
+
+		if val == nil {
+			return stores.InvalidKey(tpId)
+		}
+
+		process := val.(transfer.Process)
+		if !transfer.ValidTransition(process.State, s) {
+			return stores.InvalidTransition(tpId, string(process.State), string(s))
+		}
+
+		updated := process
+		updated.State = s
+		swapped, err := coll.CompareAndSwap(tpId, process, updated)
+		if err != nil {
+			return stores.QueryFailed(collTransfer, `CompareAndSwap`, err)
+		}
+
+		if !swapped {
+			return stores.Conflict(tpId)
+		}
+
+		return t.appendHistory(tx, tpId, process.State, s, c)
+	})
+}
+
+// History returns the state transitions of the transfer process in the order
+// they occurred
+func (t *Transfer) History(tpId string) ([]transfer.Transition, error) {
+	val, err := t.history.Get(tpId)
+	if err != nil {
+		return nil, stores.QueryFailed(collTransferHistory, `Get`, err)
+	}
+
+	if val == nil {
+		return nil, stores.InvalidKey(tpId)
+	}
+
+	return val.([]transfer.Transition), nil
+}
+
+func (t *Transfer) SetCounterparty(tpId, participantId string) error {
+	if err := t.counterparty.Set(tpId, participantId); err != nil {
+		return stores.QueryFailed(collTransferCounterparty, `Set`, err)
+	}
+	return nil
+}
+
+func (t *Transfer) Counterparty(tpId string) (participantId string, err error) {
+	val, err := t.counterparty.Get(tpId)
+	if err != nil {
+		return ``, stores.QueryFailed(collTransferCounterparty, `Get`, err)
 	}
 
 	if val == nil {
@@ -55,13 +195,77 @@ func (t *Transfer) CallbackAddr(tpId string) (string, error) {
 	return val.(string), nil
 }
 
-func (t *Transfer) UpdateState(tpId string, s transfer.State) error {
-	process, err := t.Process(tpId)
+// SetOffset stores the number of bytes of the transfer process transferred so far
+func (t *Transfer) SetOffset(tpId string, offset int64) error {
+	if err := t.offsets.Set(tpId, offset); err != nil {
+		return stores.QueryFailed(collTransferOffset, `Set`, err)
+	}
+	return nil
+}
+
+// Offset returns the number of bytes transferred so far, or zero if none is stored
+func (t *Transfer) Offset(tpId string) (int64, error) {
+	val, err := t.offsets.Get(tpId)
 	if err != nil {
-		return stores.QueryFailed(collTransfer, `Process`, err)
+		return 0, stores.QueryFailed(collTransferOffset, `Get`, err)
 	}
 
-	process.State = s
-	t.AddProcess(tpId, process)
+	if val == nil {
+		return 0, nil
+	}
+
+	return val.(int64), nil
+}
+
+// setProcess validates the transition from the stored state (if any) to the state
+// of the given process before storing it
+func (t *Transfer) setProcess(tx pkg.Transaction, tpId string, val transfer.Process, c transfer.Cause) error {
+	coll := tx.Collection(collTransfer)
+	cur, err := coll.Get(tpId)
+	if err != nil {
+		return stores.QueryFailed(collTransfer, `Get`, err)
+	}
+
+	var curState transfer.State
+	if cur != nil {
+		curState = cur.(transfer.Process).State
+	}
+
+	if !transfer.ValidTransition(curState, val.State) {
+		return stores.InvalidTransition(tpId, string(curState), string(val.State))
+	}
+
+	if err = coll.Set(tpId, val); err != nil {
+		return stores.QueryFailed(collTransfer, `Set`, err)
+	}
+
+	return t.appendHistory(tx, tpId, curState, val.State, c)
+}
+
+func (t *Transfer) appendHistory(tx pkg.Transaction, tpId string, from, to transfer.State, c transfer.Cause) error {
+	coll := tx.Collection(collTransferHistory)
+	val, err := coll.Get(tpId)
+	if err != nil {
+		return stores.QueryFailed(collTransferHistory, `Get`, err)
+	}
+
+	var history []transfer.Transition
+	if val != nil {
+		history = val.([]transfer.Transition)
+	}
+
+	// a new slice is used so that the stored history is not modified in place
+	updated := make([]transfer.Transition, len(history), len(history)+1)
+	copy(updated, history)
+	updated = append(updated, transfer.Transition{
+		Timestamp: time.Now().UTC(),
+		From:      from,
+		To:        to,
+		Cause:     c,
+	})
+
+	if err = coll.Set(tpId, updated); err != nil {
+		return stores.QueryFailed(collTransferHistory, `Set`, err)
+	}
 	return nil
 }
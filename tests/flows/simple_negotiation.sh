@@ -1,9 +1,9 @@
 #!/bin/bash
 
-res=$(curl --silent -X POST -d '{"permissions": [{"action": "use", "constraints": [{"leftOperand": "region", "operator": "eq", "rightOperand": "eu"}]}]}' http://localhost:9081/gateway/create-policy)
+res=$(curl --silent -X POST -d '{"permissions": [{"action": "use", "constraints": [{"leftOperand": "count", "operator": "lteq", "rightOperand": "5", "dataType": "xsd:integer"}]}]}' http://localhost:9081/gateway/create-policy)
 policy_id=$(echo "$res" | awk -F[\"\"] '{print $4}')
 
-data='{"title": "sample dataset", "description": ["sample description"], "endpoints": ["http://localhost:9080/datasource"], "offerIds": ["'$policy_id'"], "keywords": ["dataspace", "connector"]}'
+data='{"title": "sample dataset", "description": ["sample description"], "distributions": [{"format": "HTTP_PUSH", "dataSource": {"id": "datasets", "path": "sample.csv"}}, {"format": "HTTP_PULL", "dataSource": {"id": "datasets", "path": "sample.csv"}}], "offerIds": ["'$policy_id'"], "keywords": ["dataspace", "connector"]}'
 res=$(curl --silent -X POST -d "$data" http://localhost:9081/gateway/create-dataset)
 dataset_id=$(echo "$res" | awk -F[\"\"] '{print $4}')
 
@@ -11,7 +11,7 @@ echo "$data" | jq
 
 curl --silent -X POST -d '{"providerEndpoint": "http://localhost:9080"}' http://localhost:8081/gateway/request-catalog
 
-data='{"offerId": "'$policy_id'", "providerEndpoint": "http://localhost:9080", "constraints": {"region": "eu"}}'
+data='{"offerId": "'$policy_id'", "providerEndpoint": "http://localhost:9080", "constraints": {"count": "5"}}'
 res=$(curl --silent -X POST -d "$data" http://localhost:8081/gateway/request-contract)
 negConsPid=$(echo "$res" | awk -F[\"\"] '{print $4}')
 
@@ -4,7 +4,7 @@
 
 agrId=$1
 
-data='{"transferType": "HTTP_PUSH", "agreementId": "'$agrId'", "sinkEndpoint": "http://localhost:8080/datasink", "providerEndpoint": "http://localhost:9080"}'
+data='{"transferFormat": "HTTP_PUSH", "agreementId": "'$agrId'", "providerEndpoint": "http://localhost:9080"}'
 res=$(curl --silent -X POST -d "$data" http://localhost:8081/gateway/transfer/request)
 tpConsPid=$(echo "$res" | awk -F[\"\"] '{print $4}')
 
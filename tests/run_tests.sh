@@ -1,5 +1,15 @@
 #!/bin/bash
 
+# The provider is run with the configuration of the root directory (go run . from the root) and the
+# consumer with the configuration in tests/consumer (go run ../.. from tests/consumer), which
+# authenticate each other with the development keys in keys.
+for port in 9081 8081; do
+  if ! curl --silent --output /dev/null http://localhost:$port; then
+    printf "connector is not running (gateway port: %s)\n" $port
+    exit 1
+  fi
+done
+
 readFile() {
   while IFS= read -r line; do
     agrId=$line